@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/config"
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+// usage describes the local command line
+const usage = `Usage: gitleaks-diff-comment <command> [flags]
+
+Without a command the GitHub Action runs, configured by its INPUT_* variables.
+
+Commands:
+  diff    comment on the ignore file changes between --base and --head
+  audit   report on every entry of the ignore files at --head
+  clear   delete the bot's comments from a pull request
+  serve   run as a webhook server for repositories without GitHub Actions
+
+diff prints the comments it would post unless --pr is given, and audit prints
+its report unless --audit-issue is given; neither needs a token to do so.
+
+Examples:
+  gitleaks-diff-comment diff --base main
+  gitleaks-diff-comment diff --base origin/main --head HEAD --format json
+  gitleaks-diff-comment diff --base main --pr 42
+  gitleaks-diff-comment audit --verify
+  gitleaks-diff-comment clear --pr 42 --requester octocat
+  GITHUB_WEBHOOK_SECRET=... gitleaks-diff-comment serve --app-id 12345 --app-private-key app.pem
+
+Run 'gitleaks-diff-comment <command> -h' for the flags of a command.
+`
+
+// runCLI runs a command line subcommand, e.g. "diff --base main"
+func runCLI(args []string) error {
+	command := args[0]
+	switch command {
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+		return nil
+	case config.CommandServe:
+		return runServe(args[1:])
+	}
+
+	// Logs go to stderr without timestamps, keeping stdout for the output
+	log.SetFlags(0)
+
+	cfg, err := config.ParseArgs(command, args[1:], os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if cfg.Debug {
+		log.Printf("Configuration: Command=%s, PR=%d, Repo=%s, Commit=%s, DryRun=%v",
+			command, cfg.PRNumber, cfg.Repository, cfg.CommitSHA, cfg.DryRun)
+	}
+
+	return dispatch(context.Background(), cfg)
+}
+
+// dryRunOutput is the JSON output of a dry run
+type dryRunOutput struct {
+	Comments []*comment.GeneratedComment `json:"comments"`
+	Summary  string                      `json:"summary,omitempty"`
+	Policy   *diff.PolicyResult          `json:"policy,omitempty"`
+}
+
+// printDryRun writes the comments that would be posted to w, followed by the
+// summary comment if enabled, as markdown or JSON
+func printDryRun(w io.Writer, cfg *config.Config, generator *comment.Generator, changes []diff.DiffChange, policy *diff.PolicyResult, comments []*comment.GeneratedComment) error {
+	var summary string
+	if cfg.SummaryComment && len(changes) > 0 {
+		body, err := generator.Summary(changes, policy)
+		if err != nil {
+			log.Printf("Warning: failed to generate summary comment: %v", err)
+		}
+		summary = strings.TrimSpace(strings.TrimPrefix(body, comment.SummaryMarker+"\n"))
+	}
+
+	if cfg.Format == config.FormatJSON {
+		if comments == nil {
+			comments = []*comment.GeneratedComment{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(dryRunOutput{Comments: comments, Summary: summary, Policy: policy})
+	}
+
+	if len(comments) == 0 {
+		log.Println("No comments would be posted")
+	}
+	for i, comm := range comments {
+		if i > 0 {
+			fmt.Fprint(w, "\n---\n\n")
+		}
+		location := fmt.Sprintf("%s:%d", comm.Path, comm.Line)
+		if comm.Side == "LEFT" {
+			location += " (removed)"
+		}
+		if _, err := fmt.Fprintf(w, "## %s\n\n%s\n", location, strings.TrimSpace(comm.Body)); err != nil {
+			return err
+		}
+	}
+	if summary != "" {
+		if len(comments) > 0 {
+			fmt.Fprint(w, "\n---\n\n")
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -8,22 +8,31 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
 
-	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
 	"github.com/epy0n0ff/gitleaks-diff-comment/internal/commands"
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
 	"github.com/epy0n0ff/gitleaks-diff-comment/internal/config"
 	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
 	"github.com/epy0n0ff/gitleaks-diff-comment/internal/github"
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/redact"
 )
 
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		log.Fatalf("Error: %v", err)
 		os.Exit(1)
 	}
 }
 
-func run() error {
+func run(args []string) error {
+	// Arguments select the local command line instead of the action
+	if len(args) > 0 {
+		return runCLI(args)
+	}
+
 	// Validate we're running in GitHub Actions environment
 	if os.Getenv("GITHUB_ACTIONS") != "true" {
 		log.Println("Warning: Not running in GitHub Actions environment")
@@ -51,25 +60,38 @@ func run() error {
 		log.Printf("Configuration: PR=%d, Repo=%s, Commit=%s", cfg.PRNumber, cfg.Repository, cfg.CommitSHA)
 	}
 
+	return dispatch(context.Background(), cfg)
+}
+
+// dispatch runs the mode cfg selects
+func dispatch(ctx context.Context, cfg *config.Config) error {
 	// Route to command handler if in command mode
 	if cfg.IsCommandMode() {
-		return runCommand(cfg)
+		return runCommand(ctx, cfg)
+	}
+
+	// Report on every entry instead of a pull request
+	if cfg.IsAuditMode() {
+		return runAuditMode(ctx, cfg)
 	}
 
 	// Otherwise, run normal diff comment mode
-	return runDiffCommentMode(cfg)
+	return runDiffCommentMode(ctx, cfg)
 }
 
 // runCommand handles command execution (e.g., /clear)
-func runCommand(cfg *config.Config) error {
-	ctx := context.Background()
-
+func runCommand(ctx context.Context, cfg *config.Config) error {
 	// Create GitHub API client
-	client, err := github.NewClient(cfg.GitHubToken, cfg.Owner(), cfg.Repo(), cfg.PRNumber, cfg.GHHost)
+	client, err := newClient(ctx, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create GitHub client: %w", err)
 	}
 
+	// Command permissions come from the default branch
+	if err := applyRepoConfig(ctx, cfg, github.NewContentsReader(client), ""); err != nil {
+		return err
+	}
+
 	switch cfg.Command {
 	case "clear":
 		return runClearCommand(ctx, cfg, client)
@@ -80,7 +102,12 @@ func runCommand(cfg *config.Config) error {
 
 // runClearCommand executes the clear command to delete all bot comments
 func runClearCommand(ctx context.Context, cfg *config.Config, client github.Client) error {
+	if slices.Contains(cfg.DisabledCommands, "clear") {
+		return fmt.Errorf("the clear command is disabled by %s", config.RepoConfigPath)
+	}
+
 	cmd := commands.NewClearCommand(cfg.PRNumber, cfg.Requester, cfg.CommentID, client)
+	cmd.MinPermission = cfg.CommandPermission
 	err := cmd.Execute(ctx)
 
 	// Handle unauthorized error with detailed message
@@ -95,8 +122,112 @@ func runClearCommand(ctx context.Context, cfg *config.Config, client github.Clie
 	return err
 }
 
+// runAuditMode reports the entries of every ignore file at the commit that
+// have expired, point at files or lines that no longer exist, or suppress no
+// current finding, and optionally keeps a tracking issue up to date
+func runAuditMode(ctx context.Context, cfg *config.Config) error {
+	if cfg.Workspace != "" {
+		if err := os.Chdir(cfg.Workspace); err != nil {
+			return fmt.Errorf("failed to change to workspace directory: %w", err)
+		}
+	}
+
+	client, err := newClient(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	reader, err := auditReader(ctx, cfg, client)
+	if err != nil {
+		return err
+	}
+
+	if err := applyRepoConfig(ctx, cfg, reader, cfg.CommitSHA); err != nil {
+		return err
+	}
+
+	changes, err := diff.ReadIgnoreEntries(ctx, reader, cfg.CommitSHA, cfg.IgnoreFiles)
+	if err != nil {
+		return fmt.Errorf("failed to read ignore files: %w", err)
+	}
+	log.Printf("Auditing %d entries at %s", len(changes), cfg.CommitSHA)
+
+	if cfg.ValidateEntries {
+		validator := diff.NewEntryValidator(reader, cfg.CommitSHA)
+		validator.MatchThreshold = cfg.WildcardThreshold
+		if err := validator.ValidateChanges(ctx, changes); err != nil {
+			log.Printf("Warning: some entries could not be validated: %v", err)
+		}
+	}
+
+	if cfg.Verify {
+		scanner := diff.NewGitleaksScanner(cfg.GitleaksPath, "")
+		if err := diff.VerifyChanges(ctx, scanner, changes); err != nil {
+			log.Printf("Warning: could not verify entries against gitleaks findings: %v", err)
+		}
+	}
+
+	report := diff.Audit(cfg.CommitSHA, changes)
+
+	// The commit audited is also where template overrides are read from
+	generator := comment.NewGenerator(cfg.Repository, cfg.CommitSHA, cfg.GHHost)
+	templates, err := comment.LoadTemplates(ctx, reader, cfg.CommitSHA, cfg.TemplateDir)
+	if err != nil {
+		return fmt.Errorf("failed to load comment templates from %s: %w", cfg.TemplateDir, err)
+	}
+	generator.Templates = templates
+
+	body, err := generator.Audit(report)
+	if err != nil {
+		return fmt.Errorf("failed to generate audit report: %w", err)
+	}
+	fmt.Println(body)
+
+	log.Printf("%d of %d entries need attention: %d expired, %d missing file, %d missing line, %d unused",
+		len(report.Entries), report.Total, report.Count(diff.AuditExpired), report.Count(diff.AuditMissingFile),
+		report.Count(diff.AuditMissingLine), report.Count(diff.AuditUnused))
+
+	if cfg.AuditIssue {
+		result, err := github.PublishAuditIssue(ctx, client, cfg.AuditIssueTitle, body, len(report.Entries) > 0, cfg.Debug)
+		if err != nil {
+			return err
+		}
+		if result != nil {
+			log.Printf("Audit issue #%d %s: %s", result.Number, result.Status, result.URL)
+		}
+	}
+
+	return nil
+}
+
+// auditReader returns the reader the audited files are read through: the
+// local checkout, or the Contents and Git Trees APIs if the commit is not
+// available locally (or diff-source is "api")
+func auditReader(ctx context.Context, cfg *config.Config, client github.Client) (diff.RepositoryReader, error) {
+	if cfg.DiffSource == diff.SourceAPI && client == nil {
+		return nil, errors.New("diff-source 'api' needs a GitHub token")
+	}
+	if cfg.DiffSource == diff.SourceAPI {
+		return github.NewContentsReader(client), nil
+	}
+
+	reader, err := diff.NewObjectReader(cfg.GitBackend, "")
+	if err == nil {
+		if _, err = reader.ResolveCommit(ctx, cfg.CommitSHA); err == nil {
+			return reader, nil
+		}
+	}
+
+	if cfg.DiffSource == diff.SourceGit || client == nil {
+		return nil, fmt.Errorf("failed to read commit %s from local checkout: %w", cfg.CommitSHA, err)
+	}
+
+	log.Printf("Local checkout cannot read %s, using the Contents API instead: %v", cfg.CommitSHA, err)
+	return github.NewContentsReader(client), nil
+}
+
 // runDiffCommentMode handles the original diff commenting functionality
-func runDiffCommentMode(cfg *config.Config) error {
+func runDiffCommentMode(ctx context.Context, cfg *config.Config) error {
 
 	// Change to workspace directory if specified
 	if cfg.Workspace != "" {
@@ -126,30 +257,93 @@ func runDiffCommentMode(cfg *config.Config) error {
 		}
 	}
 
-	// Parse diff to find .gitleaksignore changes
+	// Create GitHub API client
 	if cfg.Debug {
-		log.Printf("Parsing .gitleaksignore diff (base: %s, head: %s)...", cfg.BaseRef, cfg.HeadRef)
+		if cfg.GHHost != "" {
+			log.Printf("GitHub Enterprise Server: %s", cfg.GHHost)
+			log.Printf("API Base URL: https://%s/api/v3/", cfg.GHHost)
+		} else {
+			log.Println("GitHub: Using GitHub.com (default)")
+			log.Println("API Base URL: https://api.github.com")
+		}
 	}
 
-	changes, err := diff.ParseGitleaksDiff(cfg.BaseRef, cfg.HeadRef)
+	client, err := newClient(ctx, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to parse diff (base: %s, head: %s): %w", cfg.BaseRef, cfg.HeadRef, err)
+		return fmt.Errorf("failed to create GitHub client: %w", err)
 	}
 
-	if len(changes) == 0 {
-		log.Println("No changes found in .gitleaksignore")
-		outputResult(&github.ActionOutput{})
-		return nil
+	if cfg.Debug && client != nil {
+		log.Println("Client initialized successfully")
+	}
+
+	// Parse diff to find .gitleaksignore changes
+	source, err := selectDiffSource(ctx, cfg, client)
+	if err != nil {
+		return err
+	}
+
+	// Settings from the repository configuration file on the base branch
+	if revision := baseRevision(cfg, source); revision != "" {
+		if err := applyRepoConfig(ctx, cfg, entryReader(source, client), revision); err != nil {
+			return err
+		}
+	}
+
+	// The configuration file may select other ignore files
+	switch s := source.(type) {
+	case *diff.GitSource:
+		s.Patterns = cfg.IgnoreFiles
+	case *github.PullRequestFilesSource:
+		source = github.NewPullRequestFilesSource(client, cfg.CommitSHA, cfg.IgnoreFiles)
+	}
+
+	log.Printf("Reading .gitleaksignore changes from %s", source.Name())
+
+	changes, err := source.Changes(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to parse diff from %s: %w", source.Name(), err)
 	}
 
 	if cfg.Debug {
 		log.Printf("Found %d changes in .gitleaksignore", len(changes))
 	}
 
+	// Check that new entries point at files and lines that exist, and expand wildcards
+	if cfg.ValidateEntries {
+		reader := entryReader(source, client)
+		validator := diff.NewEntryValidator(reader, cfg.CommitSHA)
+		validator.MatchThreshold = cfg.WildcardThreshold
+		if cfg.ShowSnippets {
+			validator.Redactor = newRedactor(ctx, cfg, reader, baseRevision(cfg, source))
+		}
+		if err := validator.ValidateChanges(ctx, changes); err != nil {
+			log.Printf("Warning: some entries could not be validated: %v", err)
+		}
+	}
+
+	// Check which current findings new entries suppress
+	if cfg.Verify {
+		verifyChanges(ctx, cfg, source, changes)
+	}
+
+	// Check new and modified entries against the policy
+	var policy *diff.PolicyResult
+	if cfg.Policy.Enabled() {
+		policy = diff.EvaluatePolicy(&cfg.Policy, changes)
+		log.Printf("Policy: %d blocking violations, %d warnings", policy.Blocking, policy.Warnings)
+		reportPolicy(changes, policy)
+	}
+
+	generator, err := newGenerator(ctx, cfg, source, client)
+	if err != nil {
+		return err
+	}
+
 	// Generate comments for each change
 	var comments []*comment.GeneratedComment
 	for _, change := range changes {
-		comm, err := comment.NewGeneratedComment(&change, cfg.Repository, cfg.CommitSHA, cfg.GHHost)
+		comm, err := generator.Comment(&change)
 		if err != nil {
 			log.Printf("Warning: failed to generate comment for change at position %d: %v", change.Position, err)
 			continue
@@ -157,45 +351,63 @@ func runDiffCommentMode(cfg *config.Config) error {
 		comments = append(comments, comm)
 	}
 
-	if len(comments) == 0 {
-		log.Println("No valid comments generated")
-		outputResult(&github.ActionOutput{})
-		return nil
+	// Generate comments for gitleaks config allowlist changes
+	allowlistComments, err := generateAllowlistComments(ctx, cfg, source, generator)
+	if err != nil {
+		return err
+	}
+	comments = append(comments, allowlistComments...)
+
+	// Generate comments for gitleaks:allow annotations added anywhere in the PR
+	if cfg.InlineAllow {
+		inlineComments, err := generateInlineAllowComments(ctx, cfg, source, generator)
+		if err != nil {
+			return err
+		}
+		comments = append(comments, inlineComments...)
 	}
 
 	if cfg.Debug {
 		log.Printf("Generated %d comments", len(comments))
 	}
 
-	// Create GitHub API client
-	if cfg.Debug {
-		if cfg.GHHost != "" {
-			log.Printf("GitHub Enterprise Server: %s", cfg.GHHost)
-			log.Printf("API Base URL: https://%s/api/v3/", cfg.GHHost)
-		} else {
-			log.Println("GitHub: Using GitHub.com (default)")
-			log.Println("API Base URL: https://api.github.com")
+	// Print the comments instead of posting them, e.g. to preview them locally
+	if cfg.DryRun {
+		if err := printDryRun(os.Stdout, cfg, generator, changes, policy, comments); err != nil {
+			return fmt.Errorf("failed to print comments: %w", err)
 		}
+		return policyError(policy)
 	}
 
-	client, err := github.NewClient(cfg.GitHubToken, cfg.Owner(), cfg.Repo(), cfg.PRNumber, cfg.GHHost)
-	if err != nil {
-		return fmt.Errorf("failed to create GitHub client: %w", err)
-	}
+	output := &github.ActionOutput{}
+	if cfg.OutputMode != github.OutputCheck {
+		if len(comments) == 0 {
+			log.Println("No valid comments generated")
+		} else {
+			// Post comments, either individually or as one review
+			post := github.PostComments
+			if cfg.DeliveryMode == github.DeliveryReview {
+				post = github.PostReview
+			}
+			output, err = post(ctx, client, comments, cfg.CommentMode, cfg.Debug)
+			if err != nil {
+				return fmt.Errorf("failed to post comments: %w", err)
+			}
+		}
 
-	if cfg.Debug {
-		log.Println("Client initialized successfully")
+		// Keep the summary comment in step with the ignore file changes
+		if cfg.SummaryComment {
+			postSummary(ctx, cfg, client, generator, changes, policy, output)
+		}
 	}
 
-	// Post comments
-	ctx := context.Background()
-	output, err := github.PostComments(ctx, client, comments, cfg.CommentMode, cfg.Debug)
-	if err != nil {
-		return fmt.Errorf("failed to post comments: %w", err)
+	// Publish a check run with an annotation per comment
+	if cfg.OutputMode == github.OutputCheck || cfg.OutputMode == github.OutputBoth {
+		publishCheckRun(ctx, cfg, client, generator, changes, policy, comments, output)
 	}
 
 	// Output results
-	outputResult(output)
+	outputResult(cfg, output)
 
 	// Print summary
 	log.Printf("✓ Posted: %d comments", output.Posted)
@@ -209,21 +421,389 @@ func runDiffCommentMode(cfg *config.Config) error {
 		return fmt.Errorf("completed with %d errors", output.Errors)
 	}
 
+	// Fail the job on blocking policy violations, after everything is posted
+	return policyError(policy)
+}
+
+// newClient creates the GitHub API client: scoped to the pull request if
+// there is one, or nil for a dry run without a token. With GitHub App
+// credentials it authenticates as the app's installation on the repository.
+func newClient(ctx context.Context, cfg *config.Config) (github.Client, error) {
+	switch {
+	case cfg.UsesApp():
+		app, err := github.NewApp(ctx, cfg.AppID, []byte(cfg.AppPrivateKey), cfg.GHHost)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Authenticating as GitHub App %s", app.BotLogin())
+		return github.NewAppClient(ctx, app, cfg.AppInstallationID, cfg.Owner(), cfg.Repo(), cfg.PRNumber, cfg.GHHost)
+	case cfg.GitHubToken == "" && cfg.DryRun:
+		return nil, nil
+	case cfg.PRNumber > 0:
+		return github.NewClient(cfg.GitHubToken, cfg.Owner(), cfg.Repo(), cfg.PRNumber, cfg.GHHost)
+	default:
+		return github.NewRepositoryClient(cfg.GitHubToken, cfg.Owner(), cfg.Repo(), cfg.GHHost)
+	}
+}
+
+// policyError returns an error if the policy check has blocking violations
+func policyError(policy *diff.PolicyResult) error {
+	if policy != nil && policy.Failed() {
+		return fmt.Errorf("policy check failed with %d blocking violations", policy.Blocking)
+	}
 	return nil
 }
 
-// outputResult outputs the action results in GitHub Actions format
-func outputResult(output *github.ActionOutput) {
-	// Output for GitHub Actions
-	fmt.Printf("::set-output name=posted::%d\n", output.Posted)
-	fmt.Printf("::set-output name=skipped_duplicates::%d\n", output.SkippedDuplicates)
-	fmt.Printf("::set-output name=errors::%d\n", output.Errors)
+// postSummary creates or updates the pull request summary comment and records
+// the result in output. A summary is only created once there are entry
+// changes, but an existing one is always updated, e.g. after they are reverted.
+func postSummary(ctx context.Context, cfg *config.Config, client github.Client, generator *comment.Generator, changes []diff.DiffChange, policy *diff.PolicyResult, output *github.ActionOutput) {
+	body, err := generator.Summary(changes, policy)
+	if err != nil {
+		log.Printf("Warning: failed to generate summary comment: %v", err)
+		return
+	}
 
-	// Also output JSON for debugging
-	jsonOutput, err := json.MarshalIndent(output, "", "  ")
+	result, err := github.PostSummary(ctx, client, body, len(changes) > 0, cfg.Debug)
 	if err != nil {
-		log.Printf("Warning: failed to marshal output as JSON: %v", err)
+		log.Printf("Warning: %v", err)
+		output.Errors++
+		output.Results = append(output.Results, github.CommentResult{Status: "error", Error: err.Error()})
 		return
 	}
-	fmt.Printf("\nResults:\n%s\n", string(jsonOutput))
+	if result == nil {
+		return
+	}
+
+	log.Printf("Summary comment %s: %s", result.Status, result.CommentURL)
+	output.Results = append(output.Results, *result)
+	switch result.Status {
+	case "posted", "updated":
+		output.Posted++
+	case "skipped_duplicate":
+		output.SkippedDuplicates++
+	}
+}
+
+// publishCheckRun publishes the results as a check run on the head commit and
+// records its URL in output. The summary table is shown on the check run page.
+func publishCheckRun(ctx context.Context, cfg *config.Config, client github.Client, generator *comment.Generator, changes []diff.DiffChange, policy *diff.PolicyResult, comments []*comment.GeneratedComment, output *github.ActionOutput) {
+	summary, err := generator.Summary(changes, policy)
+	if err != nil {
+		log.Printf("Warning: failed to generate check run summary: %v", err)
+	}
+	summary = strings.TrimPrefix(summary, comment.SummaryMarker+"\n")
+
+	req := github.NewCheckRun(cfg.CommitSHA, summary, comments, policy)
+	run, err := github.PublishCheckRun(ctx, client, req, cfg.Debug)
+	if run != nil {
+		output.CheckRunURL = run.HTMLURL
+		log.Printf("Check run %s (%s, %d annotations): %s", req.Title, req.Conclusion, len(req.Annotations), run.HTMLURL)
+	}
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		output.Errors++
+		output.Results = append(output.Results, github.CommentResult{Status: "error", Error: err.Error()})
+	}
+}
+
+// reportPolicy prints every policy violation as a workflow annotation:
+// ::error for blocking rules and ::warning for warn-only ones. Outside GitHub
+// Actions the violations are logged instead.
+func reportPolicy(changes []diff.DiffChange, policy *diff.PolicyResult) {
+	inActions := os.Getenv("GITHUB_ACTIONS") == "true"
+	report := func(v diff.PolicyViolation, file string, line int) {
+		command := "warning"
+		if v.Blocking {
+			command = "error"
+		}
+		if !inActions {
+			location := ""
+			if file != "" {
+				location = fmt.Sprintf("%s:%d: ", file, line)
+			}
+			log.Printf("Policy %s: %s%s (%s)", command, location, v.Message, v.Rule)
+			return
+		}
+
+		var properties []string
+		if file != "" {
+			properties = []string{"file", file, "line", strconv.Itoa(line)}
+		}
+		properties = append(properties, "title", "Gitleaks exclusion policy ("+v.Rule+")")
+		fmt.Println(workflowCommand(command, properties, v.Message))
+	}
+
+	for _, change := range changes {
+		file := change.FilePath
+		if file == "" {
+			file = diff.GitleaksIgnorePath
+		}
+		for _, v := range change.Violations {
+			report(v, file, change.LineNumber)
+		}
+	}
+	for _, v := range policy.Violations {
+		report(v, "", 0)
+	}
+}
+
+// workflowCommand formats a GitHub Actions workflow command, escaping its
+// properties (given as name, value pairs) and message
+func workflowCommand(command string, properties []string, message string) string {
+	escapeData := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	escapeProperty := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+	var props []string
+	for i := 0; i+1 < len(properties); i += 2 {
+		props = append(props, properties[i]+"="+escapeProperty.Replace(properties[i+1]))
+	}
+
+	line := "::" + command
+	if len(props) > 0 {
+		line += " " + strings.Join(props, ",")
+	}
+	return line + "::" + escapeData.Replace(message)
+}
+
+// selectDiffSource chooses where ignore file changes are read from
+// "git" requires the base and head commits in a local checkout, "api" uses the
+// pull request files API, and "auto" prefers git but falls back to the API
+// when the checkout is missing or too shallow to resolve the range.
+func selectDiffSource(ctx context.Context, cfg *config.Config, client github.Client) (diff.Source, error) {
+	// The pull request files API needs a pull request and a token
+	hasAPI := client != nil && cfg.PRNumber > 0
+	if cfg.DiffSource == diff.SourceAPI && !hasAPI {
+		return nil, errors.New("diff-source 'api' needs a pull request number and a GitHub token")
+	}
+	if cfg.DiffSource == diff.SourceAPI {
+		return github.NewPullRequestFilesSource(client, cfg.CommitSHA, cfg.IgnoreFiles), nil
+	}
+
+	reader, err := diff.NewObjectReader(cfg.GitBackend, "")
+	if err == nil {
+		var rng diff.Range
+		rng, err = diff.ResolveRange(ctx, reader, cfg.BaseSHA, cfg.BaseRef, cfg.CommitSHA)
+		if err == nil {
+			return diff.NewGitSource(reader, rng, cfg.IgnoreFiles), nil
+		}
+	}
+
+	if cfg.DiffSource == diff.SourceGit || !hasAPI {
+		return nil, fmt.Errorf("failed to resolve diff range from local checkout: %w", err)
+	}
+
+	log.Printf("Local checkout cannot resolve the PR range, using pull request files API instead: %v", err)
+	return github.NewPullRequestFilesSource(client, cfg.CommitSHA, cfg.IgnoreFiles), nil
+}
+
+// entryReader returns the reader used to validate and expand entries: the local
+// checkout when diffing with git, otherwise the Contents and Git Trees APIs
+func entryReader(source diff.Source, client github.Client) diff.BlobReader {
+	if gitSource, ok := source.(*diff.GitSource); ok {
+		return gitSource.Reader
+	}
+	return github.NewContentsReader(client)
+}
+
+// newGenerator loads the repository's comment templates and pull request
+// metadata. Overrides are read from the base commit so a pull request cannot
+// restyle the comments reviewing it; an invalid override fails the run before
+// anything is posted.
+func newGenerator(ctx context.Context, cfg *config.Config, source diff.Source, client github.Client) (*comment.Generator, error) {
+	generator := comment.NewGenerator(cfg.Repository, cfg.CommitSHA, cfg.GHHost)
+
+	revision := baseRevision(cfg, source)
+	if revision == "" {
+		log.Printf("Base commit unknown, using the default comment templates")
+	} else {
+		templates, err := comment.LoadTemplates(ctx, entryReader(source, client), revision, cfg.TemplateDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load comment templates from %s: %w", cfg.TemplateDir, err)
+		}
+		for _, file := range templates.Overrides {
+			log.Printf("Using custom comment template %s", file)
+		}
+		generator.Templates = templates
+	}
+
+	// Dry runs may have no pull request to describe
+	if client == nil || cfg.PRNumber <= 0 {
+		if cfg.PRNumber > 0 {
+			generator.SetPullRequest(comment.PullRequestInfo{Number: cfg.PRNumber})
+		}
+		return generator, nil
+	}
+
+	pr, err := client.GetPullRequest(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to fetch pull request details for templates: %v", err)
+		generator.SetPullRequest(comment.PullRequestInfo{Number: cfg.PRNumber})
+	} else {
+		generator.SetPullRequest(comment.PullRequestInfo{
+			Number: pr.Number,
+			Title:  pr.Title,
+			Author: pr.Author,
+			URL:    pr.HTMLURL,
+		})
+	}
+
+	return generator, nil
+}
+
+// baseRevision returns the commit the pull request is compared against: the
+// base of the diff range, or the base commit or branch from the inputs
+// Returns empty if it is unknown.
+func baseRevision(cfg *config.Config, source diff.Source) string {
+	if gitSource, ok := source.(*diff.GitSource); ok {
+		return gitSource.Range.Base
+	}
+	if cfg.BaseSHA != "" {
+		return cfg.BaseSHA
+	}
+	return cfg.BaseRef
+}
+
+// applyRepoConfig merges the repository configuration file at revision into
+// cfg. Pull requests are reviewed with the file from their base branch, so
+// they cannot weaken the settings or policy they are checked against. An
+// empty revision reads the default branch through the API.
+func applyRepoConfig(ctx context.Context, cfg *config.Config, reader diff.BlobReader, revision string) error {
+	content, err := reader.ReadBlob(ctx, revision, config.RepoConfigPath)
+	if errors.Is(err, diff.ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", config.RepoConfigPath, err)
+	}
+
+	repoCfg, err := config.ParseRepoConfig(content)
+	if err != nil {
+		return err
+	}
+	if err := cfg.ApplyRepoConfig(repoCfg); err != nil {
+		return fmt.Errorf("invalid configuration with %s applied: %w", config.RepoConfigPath, err)
+	}
+
+	if revision == "" {
+		revision = "the default branch"
+	}
+	log.Printf("Using repository configuration %s from %s", config.RepoConfigPath, revision)
+	return nil
+}
+
+// verifyChanges scans the checkout with gitleaks and records the findings each
+// new entry suppresses. Failures are logged; comments are still posted without
+// the verification result.
+func verifyChanges(ctx context.Context, cfg *config.Config, source diff.Source, changes []diff.DiffChange) {
+	// The scan reads the working tree, which should be the PR head
+	if gitSource, ok := source.(*diff.GitSource); ok {
+		if head, err := gitSource.Reader.ResolveCommit(ctx, "HEAD"); err == nil && head != gitSource.Range.Head {
+			log.Printf("Warning: checkout is at %s but the PR head is %s; verification may not reflect the PR", head, gitSource.Range.Head)
+		}
+	}
+
+	scanner := diff.NewGitleaksScanner(cfg.GitleaksPath, "")
+	if err := diff.VerifyChanges(ctx, scanner, changes); err != nil {
+		log.Printf("Warning: could not verify entries against gitleaks findings: %v", err)
+	}
+}
+
+// newRedactor builds the snippet redactor from the rules in the gitleaks configs
+// at revision, the base commit, so a pull request cannot weaken the redaction
+// of its own snippets. Configs that cannot be read only lose their custom
+// rules; the built-in rules and entropy heuristic still apply.
+func newRedactor(ctx context.Context, cfg *config.Config, reader diff.BlobReader, revision string) *redact.Redactor {
+	if revision == "" {
+		log.Printf("Base commit unknown, redacting snippets with the built-in rules only")
+		return redact.New(nil)
+	}
+	rules, err := diff.LoadRules(ctx, reader, revision, cfg.ConfigFiles)
+	if err != nil {
+		log.Printf("Warning: failed to load gitleaks rules for redaction: %v", err)
+	}
+	if cfg.Debug {
+		log.Printf("Loaded %d gitleaks rules for snippet redaction", len(rules))
+	}
+	return redact.New(rules)
+}
+
+// generateAllowlistComments analyzes allowlist changes in gitleaks config files
+// Full file contents are needed to know which table an element belongs to, so
+// this requires the git diff source; with the API source it is skipped.
+func generateAllowlistComments(ctx context.Context, cfg *config.Config, source diff.Source, generator *comment.Generator) ([]*comment.GeneratedComment, error) {
+	gitSource, ok := source.(*diff.GitSource)
+	if !ok {
+		log.Printf("Skipping gitleaks config allowlist analysis: requires a local checkout (diff-source: git)")
+		return nil, nil
+	}
+
+	changes, err := diff.ParseAllowlistDiff(ctx, gitSource.Reader, gitSource.Range, cfg.ConfigFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze gitleaks config allowlists: %w", err)
+	}
+
+	if cfg.Debug {
+		log.Printf("Found %d allowlist changes in gitleaks config files", len(changes))
+	}
+
+	var comments []*comment.GeneratedComment
+	for _, change := range changes {
+		comm, err := generator.AllowlistComment(&change)
+		if err != nil {
+			log.Printf("Warning: failed to generate comment for allowlist change in %s: %v", change.FilePath, err)
+			continue
+		}
+		comments = append(comments, comm)
+	}
+	return comments, nil
+}
+
+// generateInlineAllowComments creates a comment for each added gitleaks:allow annotation
+func generateInlineAllowComments(ctx context.Context, cfg *config.Config, source diff.Source, generator *comment.Generator) ([]*comment.GeneratedComment, error) {
+	allows, err := source.InlineAllows(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan for gitleaks:allow annotations from %s: %w", source.Name(), err)
+	}
+
+	if cfg.Debug {
+		log.Printf("Found %d gitleaks:allow annotations", len(allows))
+	}
+
+	var comments []*comment.GeneratedComment
+	for _, allow := range allows {
+		comm, err := generator.InlineAllowComment(&allow)
+		if err != nil {
+			log.Printf("Warning: failed to generate comment for gitleaks:allow in %s: %v", allow.FilePath, err)
+			continue
+		}
+		comments = append(comments, comm)
+	}
+	return comments, nil
+}
+
+// outputResult writes the step outputs to GITHUB_OUTPUT and the job summary
+// to GITHUB_STEP_SUMMARY. Outside GitHub Actions the results are only logged
+// in debug mode.
+func outputResult(cfg *config.Config, output *github.ActionOutput) {
+	if path := os.Getenv("GITHUB_OUTPUT"); path != "" {
+		if err := github.WriteOutputs(path, output); err != nil {
+			log.Printf("Warning: failed to write step outputs: %v", err)
+		}
+	}
+
+	if path := os.Getenv("GITHUB_STEP_SUMMARY"); path != "" {
+		if err := github.WriteStepSummary(path, output); err != nil {
+			log.Printf("Warning: failed to write job summary: %v", err)
+		}
+	}
+
+	if cfg.Debug {
+		jsonOutput, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			log.Printf("Warning: failed to marshal output as JSON: %v", err)
+			return
+		}
+		log.Printf("Results:\n%s", string(jsonOutput))
+	}
 }
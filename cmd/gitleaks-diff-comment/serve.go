@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/config"
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/server"
+)
+
+// runServe runs the webhook server until SIGINT or SIGTERM, then stops
+// accepting deliveries and lets queued ones finish
+func runServe(args []string) error {
+	cfg, err := config.ParseServeArgs(args, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	srv := server.New([]byte(cfg.WebhookSecret), server.HandlerFunc(func(ctx context.Context, job *server.Job) error {
+		jobCfg, err := cfg.JobConfig(job.Repository, job.InstallationID, job.Event, job.Command)
+		if err != nil {
+			return fmt.Errorf("invalid configuration for %s: %w", job.Key(), err)
+		}
+		return dispatch(ctx, jobCfg)
+	}), server.Options{
+		Workers:    cfg.Workers,
+		QueueSize:  cfg.QueueSize,
+		JobTimeout: cfg.JobTimeout,
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, srv)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	httpServer := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+	log.Printf("Listening for webhooks on %s%s (%d workers, queue of %d)", cfg.Addr, cfg.Path, cfg.Workers, cfg.QueueSize)
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("webhook server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, waiting up to %s for queued deliveries", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Stop receiving first, so no delivery is accepted that cannot finish
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Warning: failed to close connections: %v", err)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown did not finish: %w", err)
+	}
+	log.Println("Shutdown complete")
+	return nil
+}
@@ -62,6 +62,10 @@ type ClearCommand struct {
 	// Client is the GitHub API client
 	Client github.Client
 
+	// MinPermission is the lowest permission level allowed to run the
+	// command, e.g. "admin"; empty allows write, maintain and admin
+	MinPermission string
+
 	// Operation tracks execution state
 	Operation *ClearOperation
 }
@@ -101,9 +105,16 @@ func (c *ClearCommand) Execute(ctx context.Context) error {
 		return fmt.Errorf("failed to check permissions: %w", err)
 	}
 
+	if c.MinPermission != "" {
+		authorized = HasPermission(permissionLevel, c.MinPermission)
+	}
+
 	if !authorized {
 		c.Operation.Status = "failed"
 		errUnauth := NewErrUnauthorized(c.RequestedBy, permissionLevel)
+		if c.MinPermission != "" {
+			errUnauth.RequiredLevels = permissionsAtLeast(c.MinPermission)
+		}
 		c.Operation.Errors = append(c.Operation.Errors, errUnauth.Error())
 		c.finalize()
 		return errUnauth
@@ -124,7 +135,7 @@ func (c *ClearCommand) Execute(ctx context.Context) error {
 	}
 
 	// Filter to bot comments only
-	botComments := github.FilterBotReviewComments(reviewComments)
+	botComments := github.FilterBotReviewComments(reviewComments, c.Client.BotLogin())
 	c.Operation.CommentsFound = len(botComments)
 
 	log.Printf("::notice::Found %d bot review comments to delete", len(botComments))
@@ -1,6 +1,9 @@
 package commands
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // ErrUnauthorized is returned when a user lacks required permissions to execute a command
 type ErrUnauthorized struct {
@@ -12,8 +15,21 @@ type ErrUnauthorized struct {
 func (e *ErrUnauthorized) Error() string {
 	return fmt.Sprintf("permission denied: user '%s' does not have required permissions\n"+
 		"  → Current permission level: %s\n"+
-		"  → Required: write, admin, or maintain access to repository",
-		e.Username, e.PermissionLevel)
+		"  → Required: %s access to repository",
+		e.Username, e.PermissionLevel, joinLevels(e.RequiredLevels))
+}
+
+// joinLevels lists permission levels in prose, e.g. "write, admin, or maintain"
+func joinLevels(levels []string) string {
+	switch len(levels) {
+	case 0:
+		return "no"
+	case 1:
+		return levels[0]
+	case 2:
+		return levels[0] + " or " + levels[1]
+	}
+	return strings.Join(levels[:len(levels)-1], ", ") + ", or " + levels[len(levels)-1]
 }
 
 // NewErrUnauthorized creates a new unauthorized error
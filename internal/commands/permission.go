@@ -0,0 +1,33 @@
+package commands
+
+// CommandPermissions are the minimum permission levels a repository may
+// require for commands, from least to most access. The permission API only
+// reports these levels: maintainers are reported as write, triagers as read.
+var CommandPermissions = []string{"read", "write", "admin"}
+
+// permissionRank orders the permission levels the API reports
+var permissionRank = map[string]int{
+	"none":     0,
+	"read":     1,
+	"triage":   2,
+	"write":    3,
+	"maintain": 4,
+	"admin":    5,
+}
+
+// HasPermission returns true if level grants at least the required level
+func HasPermission(level, required string) bool {
+	rank, ok := permissionRank[level]
+	return ok && rank > 0 && rank >= permissionRank[required]
+}
+
+// permissionsAtLeast returns the reported levels that satisfy required
+func permissionsAtLeast(required string) []string {
+	var levels []string
+	for _, level := range CommandPermissions {
+		if HasPermission(level, required) {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
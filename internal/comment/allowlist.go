@@ -0,0 +1,121 @@
+package comment
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+//go:embed templates/allowlist_addition.md
+var allowlistAdditionTemplate string
+
+//go:embed templates/allowlist_deletion.md
+var allowlistDeletionTemplate string
+
+// allowlistKindLabels are the human-readable names of allowlist arrays
+var allowlistKindLabels = map[diff.AllowlistKind]string{
+	diff.AllowlistPaths:     "Path pattern",
+	diff.AllowlistRegexes:   "Regex",
+	diff.AllowlistStopwords: "Stopword",
+	diff.AllowlistCommits:   "Commit",
+}
+
+// NewAllowlistComment creates a GeneratedComment from a gitleaks config allowlist change
+// using the default templates
+func NewAllowlistComment(change *diff.AllowlistChange, commitSHA string) (*GeneratedComment, error) {
+	return NewGenerator("", commitSHA, "").AllowlistComment(change)
+}
+
+// AllowlistComment creates a GeneratedComment from a gitleaks config allowlist change
+// The comment is anchored to the changed array element in the config file.
+func (g *Generator) AllowlistComment(change *diff.AllowlistChange) (*GeneratedComment, error) {
+	entry := change.Entry
+	commitSHA := g.Context.CommitSHA
+
+	label, ok := allowlistKindLabels[entry.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown allowlist kind: %s", entry.Kind)
+	}
+
+	data := AllowlistCommentData{
+		TemplateContext: g.Context,
+		Kind:            string(entry.Kind),
+		KindLabel:       label,
+		Value:           entry.Value,
+		RuleIDs:         entry.RuleIDs,
+		Global:          entry.IsGlobal(),
+		Scope:           describeScope(entry.RuleIDs),
+		Section:         describeSection(&entry),
+		Description:     entry.Description,
+		ConfigPath:      change.FilePath,
+		Operation:       string(change.Operation),
+	}
+
+	body, err := g.renderAllowlist(change.Operation, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	side := "RIGHT"
+	line := change.LineNumber
+	if change.Operation == diff.OperationDeletion {
+		side = "LEFT"
+		line = change.OldLineNumber
+	}
+	if line <= 0 {
+		line = 1 // Fallback to line 1 if not set
+	}
+
+	// Same marker format as .gitleaksignore comments so override mode and /clear apply
+	marker := fmt.Sprintf("<!-- gitleaks-diff-comment: %s:%d:%s -->", change.FilePath, line, side)
+
+	return &GeneratedComment{
+		Body:     marker + "\n" + body,
+		Path:     change.FilePath,
+		Line:     line,
+		Side:     side,
+		CommitID: commitSHA,
+	}, nil
+}
+
+// renderAllowlist renders the allowlist template for operation
+func (g *Generator) renderAllowlist(operation diff.OperationType, data AllowlistCommentData) (string, error) {
+	switch operation {
+	case diff.OperationAddition:
+		return g.Templates.render(TemplateAllowlistAddition, data)
+	case diff.OperationDeletion:
+		return g.Templates.render(TemplateAllowlistDeletion, data)
+	default:
+		return "", fmt.Errorf("unsupported allowlist operation: %s", operation)
+	}
+}
+
+// describeScope returns which rules an allowlist applies to, e.g. "for rule `aws-access-key`"
+func describeScope(ruleIDs []string) string {
+	if len(ruleIDs) == 0 {
+		return "for all rules"
+	}
+	return "for " + describeRules(ruleIDs)
+}
+
+// describeRules lists rule IDs, e.g. "rule `a`" or "rules `a`, `b`"
+func describeRules(ruleIDs []string) string {
+	quoted := make([]string, len(ruleIDs))
+	for i, id := range ruleIDs {
+		quoted[i] = "`" + id + "`"
+	}
+	if len(quoted) == 1 {
+		return "rule " + quoted[0]
+	}
+	return "rules " + strings.Join(quoted, ", ")
+}
+
+// describeSection names the allowlist array an entry was found in, e.g. "global allowlist regexes"
+func describeSection(entry *diff.AllowlistEntry) string {
+	if entry.IsGlobal() {
+		return fmt.Sprintf("global allowlist `%s`", entry.Kind)
+	}
+	return fmt.Sprintf("rule allowlist `%s`", entry.Kind)
+}
@@ -0,0 +1,91 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+func TestNewAllowlistComment_Addition(t *testing.T) {
+	change := &diff.AllowlistChange{
+		FilePath:   ".gitleaks.toml",
+		Operation:  diff.OperationAddition,
+		LineNumber: 12,
+		Entry: diff.AllowlistEntry{
+			Kind:       diff.AllowlistRegexes,
+			Value:      "AKIA.*TEST",
+			RuleIDs:    []string{"aws-access-key"},
+			LineNumber: 12,
+		},
+	}
+
+	comment, err := NewAllowlistComment(change, "abc123")
+	if err != nil {
+		t.Fatalf("NewAllowlistComment() unexpected error: %v", err)
+	}
+
+	if comment.Path != ".gitleaks.toml" || comment.Line != 12 || comment.Side != "RIGHT" {
+		t.Errorf("comment anchored at %s:%d (%s), want .gitleaks.toml:12 (RIGHT)", comment.Path, comment.Line, comment.Side)
+	}
+	for _, want := range []string{
+		"<!-- gitleaks-diff-comment: .gitleaks.toml:12:RIGHT -->",
+		"Gitleaks Allowlist Extended",
+		"Regex `AKIA.*TEST` now suppresses findings for rule `aws-access-key`.",
+		"rule allowlist `regexes`",
+		"regular expression will be ignored",
+	} {
+		if !strings.Contains(comment.Body, want) {
+			t.Errorf("Comment body should contain %q: %s", want, comment.Body)
+		}
+	}
+}
+
+func TestNewAllowlistComment_Deletion(t *testing.T) {
+	change := &diff.AllowlistChange{
+		FilePath:      "config/.gitleaks.toml",
+		Operation:     diff.OperationDeletion,
+		OldLineNumber: 7,
+		Entry: diff.AllowlistEntry{
+			Kind:        diff.AllowlistPaths,
+			Value:       "vendor/.*",
+			Description: "third party code",
+		},
+	}
+
+	comment, err := NewAllowlistComment(change, "abc123")
+	if err != nil {
+		t.Fatalf("NewAllowlistComment() unexpected error: %v", err)
+	}
+
+	if comment.Line != 7 || comment.Side != "LEFT" {
+		t.Errorf("comment anchored at line %d (%s), want 7 (LEFT)", comment.Line, comment.Side)
+	}
+	for _, want := range []string{
+		"Gitleaks Allowlist Narrowed",
+		"Path pattern `vendor/.*` no longer suppresses findings for all rules.",
+		"> third party code",
+		"global allowlist `paths`",
+	} {
+		if !strings.Contains(comment.Body, want) {
+			t.Errorf("Comment body should contain %q: %s", want, comment.Body)
+		}
+	}
+}
+
+func TestDescribeScope(t *testing.T) {
+	tests := []struct {
+		ruleIDs []string
+		want    string
+	}{
+		{nil, "for all rules"},
+		{[]string{"aws-access-key"}, "for rule `aws-access-key`"},
+		{[]string{"a", "b"}, "for rules `a`, `b`"},
+	}
+
+	for _, tt := range tests {
+		if got := describeScope(tt.ruleIDs); got != tt.want {
+			t.Errorf("describeScope(%v) = %q, want %q", tt.ruleIDs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package comment
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+//go:embed templates/audit.md
+var auditTemplate string
+
+// AuditMarker identifies the audit tracking issue, which is updated in place
+// on every scheduled run instead of being opened again
+const AuditMarker = "<!-- gitleaks-diff-comment: audit -->"
+
+// auditIcons prefixes each problem kind in the report
+var auditIcons = map[string]string{
+	diff.AuditExpired:       "⌛",
+	diff.AuditInvalidExpiry: "❗",
+	diff.AuditMissingFile:   "❗",
+	diff.AuditMissingLine:   "❗",
+	diff.AuditUnused:        "🗑️",
+}
+
+// Audit renders the audit report of the ignore files at the generator's
+// commit, with AuditMarker as its first line
+func (g *Generator) Audit(report *diff.AuditReport) (string, error) {
+	data := AuditData{
+		TemplateContext: g.Context,
+		Revision:        shortSHA(report.Revision),
+		Total:           report.Total,
+		Verified:        report.Verified,
+		Expired:         report.Count(diff.AuditExpired),
+		Stale:           report.Count(diff.AuditMissingFile) + report.Count(diff.AuditMissingLine),
+		Unused:          report.Count(diff.AuditUnused),
+	}
+
+	for i := range report.Entries {
+		change := &report.Entries[i].Change
+		entry, err := g.commentData(change)
+		if err != nil {
+			return "", fmt.Errorf("failed to describe entry at %s:%d: %w", change.FilePath, change.LineNumber, err)
+		}
+
+		var problems []string
+		for _, p := range report.Entries[i].Problems {
+			problems = append(problems, auditIcons[p.Kind]+" "+p.Message)
+		}
+		data.Entries = append(data.Entries, AuditEntry{
+			CommentData: entry,
+			IgnoreFile:  change.FilePath,
+			IgnoreLine:  change.LineNumber,
+			IgnoreLink:  fmt.Sprintf("%s/blob/%s/%s#L%d", g.Context.Repo.URL, report.Revision, change.FilePath, change.LineNumber),
+			Problems:    problems,
+		})
+	}
+
+	body, err := g.Templates.render(TemplateAudit, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return AuditMarker + "\n" + body, nil
+}
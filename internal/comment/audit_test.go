@@ -0,0 +1,80 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+func TestGenerator_Audit(t *testing.T) {
+	generator := NewGenerator("owner/repo", "abc123def456", "")
+
+	report := &diff.AuditReport{
+		Revision: "abc123def456",
+		Total:    12,
+		Verified: true,
+		Entries: []diff.AuditEntry{
+			{
+				Change: diff.DiffChange{
+					FilePath:   ".gitleaksignore",
+					Operation:  diff.OperationAddition,
+					LineNumber: 4,
+					Content:    "test/key.pem:private-key:1",
+					Metadata:   &diff.EntryMetadata{Reason: "fixture", Ticket: "SEC-1", Expires: "2026-01-31"},
+				},
+				Problems: []diff.AuditProblem{
+					{Kind: diff.AuditExpired, Message: "expired on 2026-01-31"},
+					{Kind: diff.AuditUnused, Message: "matches no current finding"},
+				},
+			},
+			{
+				Change: diff.DiffChange{
+					FilePath:   "svc/.gitleaksignore",
+					Operation:  diff.OperationAddition,
+					LineNumber: 2,
+					Content:    "old.json",
+				},
+				Problems: []diff.AuditProblem{{Kind: diff.AuditMissingFile, Message: "file no longer exists"}},
+			},
+		},
+	}
+
+	body, err := generator.Audit(report)
+	if err != nil {
+		t.Fatalf("Audit() unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(body, AuditMarker+"\n") {
+		t.Errorf("report must start with the marker:\n%s", body)
+	}
+	for _, want := range []string{
+		"2 of 12 gitleaks exclusions at commit abc123d need attention: 1 expired, 1 stale, 1 unused.",
+		"| [`test/key.pem:private-key:1`](https://github.com/owner/repo/blob/abc123def456/.gitleaksignore#L4)<br><sub>.gitleaksignore:4</sub> | `private-key` | [`test/key.pem`](https://github.com/owner/repo/blob/abc123def456/test/key.pem#L1) | SEC-1 | 2026-01-31 | ⌛ expired on 2026-01-31<br>🗑️ matches no current finding |",
+		"| [`old.json`](https://github.com/owner/repo/blob/abc123def456/svc/.gitleaksignore#L2)<br><sub>svc/.gitleaksignore:2</sub> | any | [`svc/old.json`](https://github.com/owner/repo/blob/abc123def456/svc/old.json) | — | — | ❗ file no longer exists |",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("report should contain %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "Unused entries were not checked") {
+		t.Errorf("verified report should not mention skipped verification:\n%s", body)
+	}
+}
+
+func TestGenerator_AuditClean(t *testing.T) {
+	generator := NewGenerator("owner/repo", "abc123def456", "")
+
+	body, err := generator.Audit(&diff.AuditReport{Revision: "abc123def456", Total: 1})
+	if err != nil {
+		t.Fatalf("Audit() unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"All 1 gitleaks exclusion at commit abc123d is current.",
+		"enable `verify` to compare entries with a gitleaks scan",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("report should contain %q:\n%s", want, body)
+		}
+	}
+}
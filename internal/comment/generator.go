@@ -2,42 +2,94 @@ package comment
 
 import (
 	_ "embed"
-	"bytes"
 	"fmt"
 	"strings"
-	"text/template"
 
 	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
 )
 
+// maxListedMatches is the number of files matched by a wildcard entry listed in a comment
+const maxListedMatches = 10
+
 //go:embed templates/addition.md
 var additionTemplate string
 
 //go:embed templates/deletion.md
 var deletionTemplate string
 
+//go:embed templates/modification.md
+var modificationTemplate string
+
+// Generator renders comments with a template set and the repository and
+// pull request context shared by every comment
+type Generator struct {
+	// Context is passed to every template
+	Context TemplateContext
+
+	// GHHost is the GitHub Enterprise Server hostname (empty for GitHub.com)
+	GHHost string
+
+	// Templates are the templates comments are rendered with
+	Templates *Templates
+}
+
+// NewGenerator creates a Generator for repo ("owner/repo") using the default templates
+// ghHost should be the GitHub Enterprise Server hostname (e.g., "github.company.com")
+// or empty string for GitHub.com
+func NewGenerator(repo, commitSHA, ghHost string) *Generator {
+	baseURL := "https://github.com"
+	if ghHost != "" {
+		baseURL = "https://" + ghHost
+	}
+	owner, name, _ := strings.Cut(repo, "/")
+
+	return &Generator{
+		Context: TemplateContext{
+			Repo: RepoInfo{
+				FullName: repo,
+				Owner:    owner,
+				Name:     name,
+				URL:      baseURL + "/" + repo,
+			},
+			CommitSHA: commitSHA,
+		},
+		GHHost:    ghHost,
+		Templates: DefaultTemplates(),
+	}
+}
+
+// SetPullRequest sets the pull request described to templates
+// URL defaults to the pull request page in the generator's repository.
+func (g *Generator) SetPullRequest(pr PullRequestInfo) {
+	if pr.URL == "" && pr.Number > 0 {
+		pr.URL = fmt.Sprintf("%s/pull/%d", g.Context.Repo.URL, pr.Number)
+	}
+	g.Context.PR = pr
+}
+
 // NewGeneratedComment creates a new GeneratedComment from a DiffChange
+// using the default templates
 // ghHost should be the GitHub Enterprise Server hostname (e.g., "github.company.com")
 // or empty string for GitHub.com
 func NewGeneratedComment(change *diff.DiffChange, repo, commitSHA, ghHost string) (*GeneratedComment, error) {
-	// Parse the gitleaks entry
-	entry, err := diff.ParseGitleaksEntry(change.Content)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse gitleaks entry: %w", err)
+	return NewGenerator(repo, commitSHA, ghHost).Comment(change)
+}
+
+// Comment creates a GeneratedComment from a .gitleaksignore DiffChange
+func (g *Generator) Comment(change *diff.DiffChange) (*GeneratedComment, error) {
+	// The ignore file this change belongs to (nested files in monorepos)
+	path := change.FilePath
+	if path == "" {
+		path = diff.GitleaksIgnorePath
 	}
 
-	// Prepare template data
-	data := CommentData{
-		FilePattern:   entry.FilePattern,
-		FileLink:      entry.FileLink(repo, commitSHA, ghHost),
-		Operation:     string(change.Operation),
-		HasLineNumber: entry.HasLineNumber(),
-		LineNumber:    entry.LineNumber,
-		IsPattern:     entry.IsPattern,
+	data, err := g.commentData(change)
+	if err != nil {
+		return nil, err
 	}
 
 	// Render template
-	body, err := renderTemplate(change.Operation, data)
+	body, err := g.renderChange(change.Operation, data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to render template: %w", err)
 	}
@@ -48,59 +100,231 @@ func NewGeneratedComment(change *diff.DiffChange, repo, commitSHA, ghHost string
 		side = "LEFT"
 	}
 
-	// Use LineNumber from the change (this is the line number in the file)
+	// Use the line number on the side being commented on:
+	// new file line for additions (RIGHT), old file line for deletions (LEFT)
 	line := change.LineNumber
+	if side == "LEFT" {
+		line = change.OldLineNumber
+	}
 	if line <= 0 {
 		line = 1 // Fallback to line 1 if not set
 	}
 
 	// Add invisible marker for comment identification (for override mode)
 	// Format: <!-- gitleaks-diff-comment: {path}:{line}:{side} -->
-	marker := fmt.Sprintf("<!-- gitleaks-diff-comment: %s:%d:%s -->", ".gitleaksignore", line, side)
+	marker := fmt.Sprintf("<!-- gitleaks-diff-comment: %s:%d:%s -->", path, line, side)
 	bodyWithMarker := marker + "\n" + body
 
 	return &GeneratedComment{
 		Body:         bodyWithMarker,
-		Path:         ".gitleaksignore",
+		Path:         path,
 		Line:         line,
 		Side:         side,
 		Position:     change.Position,
-		CommitID:     commitSHA,
+		CommitID:     g.Context.CommitSHA,
 		SourceChange: change,
 	}, nil
 }
 
-// renderTemplate renders the appropriate template based on operation type
+// commentData collects the template data describing a .gitleaksignore change
+func (g *Generator) commentData(change *diff.DiffChange) (CommentData, error) {
+	repo, commitSHA, ghHost := g.Context.Repo.FullName, g.Context.CommitSHA, g.GHHost
+
+	// Parse the gitleaks entry, resolving it against the ignore file's directory
+	entry, err := change.Entry()
+	if err != nil {
+		return CommentData{}, fmt.Errorf("failed to parse gitleaks entry: %w", err)
+	}
+
+	// Prepare template data
+	data := CommentData{
+		TemplateContext: g.Context,
+		FilePattern:     entry.FilePattern,
+		FileLink:        entry.FileLink(repo, commitSHA, ghHost),
+		Operation:       string(change.Operation),
+		HasLineNumber:   entry.HasLineNumber(),
+		LineNumber:      entry.LineNumber,
+		IsPattern:       entry.IsPattern,
+		OriginalLine:    entry.OriginalLine,
+		RuleID:          entry.RuleID,
+		Commit:          entry.ShortCommit(),
+	}
+
+	// For modifications, describe the entry before the change
+	if change.IsModification() {
+		prevEntry, err := change.PreviousEntry()
+		if err != nil {
+			return CommentData{}, fmt.Errorf("failed to parse previous gitleaks entry: %w", err)
+		}
+		data.PreviousFilePattern = prevEntry.FilePattern
+		data.PreviousOriginalLine = prevEntry.OriginalLine
+		data.PreviousHasLineNumber = prevEntry.HasLineNumber()
+		data.PreviousLineNumber = prevEntry.LineNumber
+		data.ChangeSummary = describeModification(prevEntry, entry)
+	}
+
+	// Surface whether the referenced file and line exist
+	if v := change.Validation; v != nil {
+		data.Validated = true
+		data.FileExists = v.FileExists
+		data.LineExists = v.LineExists
+		data.FileLineCount = v.LineCount
+		data.ValidatedAt = shortSHA(v.Revision)
+
+		if entry.IsPattern {
+			data.MatchCount = len(v.Matches)
+			data.Matches = linkMatches(v.Matches, maxListedMatches, repo, v.Revision, ghHost)
+			data.MoreMatches = data.MatchCount - len(data.Matches)
+			data.BroadPattern = v.IsBroad()
+			data.MatchThreshold = v.MatchThreshold
+		}
+
+		for _, line := range v.Snippet {
+			data.Snippet = append(data.Snippet, SnippetLine{Number: line.Number, Text: line.Text, Target: line.Target})
+		}
+		data.SnippetFence = codeFence(v.Snippet)
+	}
+
+	// Surface whether the entry suppresses any current finding
+	if v := change.Verification; v != nil {
+		data.Verified = true
+		data.FindingCount = len(v.Findings)
+		if ids := v.RuleIDs(); len(ids) > 0 {
+			data.FindingRules = describeRules(ids)
+		}
+
+		for _, f := range v.Findings {
+			target := diff.GitleaksEntry{Commit: f.Commit, FilePattern: f.File, LineNumber: f.StartLine}
+			data.Findings = append(data.Findings, FindingInfo{
+				RuleID:      f.RuleID,
+				Description: f.Description,
+				File:        f.File,
+				Line:        f.StartLine,
+				Link:        target.FileLink(repo, commitSHA, ghHost),
+			})
+			if f.RuleID == entry.RuleID && data.RuleDescription == "" {
+				data.RuleDescription = f.Description
+			}
+		}
+	}
+
+	// Surface the justification recorded above the entry
+	if m := entry.Metadata; m != nil {
+		data.Reason = m.Reason
+		data.Ticket = m.Ticket
+		data.Expires = m.Expires
+	}
+
+	// Surface the policy rules the entry breaks
+	data.Violations = policyViolations(change.Violations)
+	for _, v := range change.Violations {
+		data.Blocked = data.Blocked || v.Blocking
+	}
+
+	return data, nil
+}
+
+// policyViolations converts violations for templates
+func policyViolations(violations []diff.PolicyViolation) []PolicyViolation {
+	var converted []PolicyViolation
+	for _, v := range violations {
+		converted = append(converted, PolicyViolation{Rule: v.Rule, Message: v.Message, Blocking: v.Blocking})
+	}
+	return converted
+}
+
+// renderTemplate renders the default template for operation
 func renderTemplate(operation diff.OperationType, data CommentData) (string, error) {
-	var tmplStr string
-	var tmplName string
+	return NewGenerator("", "", "").renderChange(operation, data)
+}
 
+// renderChange renders the template for a .gitleaksignore operation
+func (g *Generator) renderChange(operation diff.OperationType, data CommentData) (string, error) {
+	var name string
 	switch operation {
 	case diff.OperationAddition:
-		tmplStr = additionTemplate
-		tmplName = "addition"
+		name = TemplateAddition
 	case diff.OperationDeletion:
-		tmplStr = deletionTemplate
-		tmplName = "deletion"
+		name = TemplateDeletion
+	case diff.OperationModification:
+		name = TemplateModification
 	default:
 		return "", fmt.Errorf("unknown operation type: %s", operation)
 	}
+	return g.Templates.render(name, data)
+}
 
-	// Parse template
-	tmpl, err := template.New(tmplName).Parse(tmplStr)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+// describeModification returns human-readable differences between two entries
+func describeModification(before, after *diff.GitleaksEntry) []string {
+	var summary []string
+
+	switch {
+	case before.HasLineNumber() && after.HasLineNumber() && before.LineNumber != after.LineNumber:
+		summary = append(summary, fmt.Sprintf("Line number changed from `%d` to `%d`", before.LineNumber, after.LineNumber))
+	case before.HasLineNumber() && !after.HasLineNumber():
+		summary = append(summary, fmt.Sprintf("Line number `%d` removed: the exclusion now covers the whole file", before.LineNumber))
+	case !before.HasLineNumber() && after.HasLineNumber():
+		summary = append(summary, fmt.Sprintf("Line number `%d` added: the exclusion now covers a single line", after.LineNumber))
+	}
+
+	if before.RuleID != after.RuleID {
+		switch {
+		case before.RuleID == "":
+			summary = append(summary, fmt.Sprintf("Rule `%s` added: only findings for this rule are excluded", after.RuleID))
+		case after.RuleID == "":
+			summary = append(summary, fmt.Sprintf("Rule `%s` removed: findings for any rule are excluded", before.RuleID))
+		default:
+			summary = append(summary, fmt.Sprintf("Rule changed from `%s` to `%s`", before.RuleID, after.RuleID))
+		}
+	}
+
+	if before.Commit != after.Commit && before.Commit != "" && after.Commit != "" {
+		summary = append(summary, fmt.Sprintf("Finding commit changed from `%s` to `%s`", before.ShortCommit(), after.ShortCommit()))
+	}
+
+	if len(summary) == 0 && before.OriginalLine != after.OriginalLine {
+		summary = append(summary, fmt.Sprintf("Fingerprint changed from `%s` to `%s`", before.OriginalLine, after.OriginalLine))
 	}
 
-	// Execute template
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+	return summary
+}
+
+// linkMatches returns up to limit matched files with links at revision
+func linkMatches(paths []string, limit int, repo, revision, ghHost string) []FileMatch {
+	if len(paths) > limit {
+		paths = paths[:limit]
+	}
+	matches := make([]FileMatch, 0, len(paths))
+	for _, p := range paths {
+		target := diff.GitleaksEntry{FilePattern: p}
+		matches = append(matches, FileMatch{Path: p, Link: target.FileLink(repo, revision, ghHost)})
+	}
+	return matches
+}
+
+// codeFence returns a backtick fence that cannot be closed by the snippet text
+func codeFence(lines []diff.SnippetLine) string {
+	longest := 0
+	for _, line := range lines {
+		run := 0
+		for _, c := range line.Text {
+			if c != '`' {
+				run = 0
+				continue
+			}
+			run++
+			longest = max(longest, run)
+		}
 	}
+	return strings.Repeat("`", max(3, longest+1))
+}
 
-	// Clean up extra whitespace
-	result := strings.TrimSpace(buf.String())
-	return result, nil
+// shortSHA abbreviates a commit SHA for display
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
 }
 
 // GetBodyPreview returns a short preview of the comment body for logging
@@ -1,6 +1,7 @@
 package comment
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -91,6 +92,424 @@ func TestNewGeneratedComment_Deletion(t *testing.T) {
 	}
 }
 
+func TestNewGeneratedComment_DeletionUsesOldLine(t *testing.T) {
+	change := &diff.DiffChange{
+		FilePath:      ".gitleaksignore",
+		Operation:     diff.OperationDeletion,
+		OldLineNumber: 17,
+		Content:       "old-secrets.yml",
+		Position:      10,
+	}
+
+	comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+	if err != nil {
+		t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+	}
+
+	if comment.Side != "LEFT" {
+		t.Errorf("Side = %s, want LEFT", comment.Side)
+	}
+	if comment.Line != 17 {
+		t.Errorf("Line = %d, want old-side line 17", comment.Line)
+	}
+	if !strings.Contains(comment.Body, "<!-- gitleaks-diff-comment: .gitleaksignore:17:LEFT -->") {
+		t.Errorf("Comment marker should reference old-side line: %s", comment.Body)
+	}
+}
+
+func TestNewGeneratedComment_Modification(t *testing.T) {
+	change := &diff.DiffChange{
+		FilePath:        ".gitleaksignore",
+		Operation:       diff.OperationModification,
+		LineNumber:      8,
+		OldLineNumber:   8,
+		Content:         "config/a.yml:14",
+		PreviousContent: "config/a.yml:12",
+		Position:        4,
+	}
+
+	comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+	if err != nil {
+		t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+	}
+
+	if comment.Side != "RIGHT" || comment.Line != 8 {
+		t.Errorf("comment placed at %d (%s), want 8 (RIGHT)", comment.Line, comment.Side)
+	}
+
+	for _, want := range []string{
+		"Gitleaks Exclusion Changed",
+		"`config/a.yml:12`",
+		"`config/a.yml:14`",
+		"Line number changed from `12` to `14`",
+		"Line 12 will be scanned again",
+	} {
+		if !strings.Contains(comment.Body, want) {
+			t.Errorf("Comment body should contain %q: %s", want, comment.Body)
+		}
+	}
+}
+
+func TestDescribeModification(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{"line changed", "a.yml:1", "a.yml:2", "Line number changed from `1` to `2`"},
+		{"line removed", "a.yml:1", "a.yml", "Line number `1` removed"},
+		{"line added", "a.yml", "a.yml:3", "Line number `3` added"},
+		{"rule changed", "a.yml:rule-a:1", "a.yml:rule-b:1", "Rule changed from `rule-a` to `rule-b`"},
+		{"rule added", "a.yml:1", "a.yml:rule-b:1", "Rule `rule-b` added"},
+		{
+			"commit changed",
+			"cd5226711335c68be1e720b318b7bc3135a30eb2:a.yml:rule-a:1",
+			"0123456789abcdef0123456789abcdef01234567:a.yml:rule-a:1",
+			"Finding commit changed from `cd52267` to `0123456`",
+		},
+		{"other change", "a.yml:1", "a.yml:01", "Fingerprint changed from `a.yml:1` to `a.yml:01`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, _ := diff.ParseGitleaksEntry(tt.before)
+			after, _ := diff.ParseGitleaksEntry(tt.after)
+			summary := strings.Join(describeModification(before, after), "\n")
+			if !strings.Contains(summary, tt.want) {
+				t.Errorf("describeModification() = %q, want it to contain %q", summary, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGeneratedComment_GitFingerprint(t *testing.T) {
+	change := &diff.DiffChange{
+		FilePath:   ".gitleaksignore",
+		Operation:  diff.OperationAddition,
+		LineNumber: 3,
+		Content:    "cd5226711335c68be1e720b318b7bc3135a30eb2:config/app.yml:generic-api-key:12",
+		Position:   2,
+	}
+
+	comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+	if err != nil {
+		t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"`config/app.yml` (line 12) for rule `generic-api-key`",
+		"https://github.com/owner/repo/blob/cd5226711335c68be1e720b318b7bc3135a30eb2/config/app.yml#L12",
+		"finding reported in commit cd52267",
+	} {
+		if !strings.Contains(comment.Body, want) {
+			t.Errorf("Comment body should contain %q: %s", want, comment.Body)
+		}
+	}
+}
+
+func TestNewGeneratedComment_NestedIgnoreFile(t *testing.T) {
+	change := &diff.DiffChange{
+		FilePath:   "services/payments/.gitleaksignore",
+		Operation:  diff.OperationAddition,
+		LineNumber: 4,
+		Content:    "config/app.yml:12",
+		Position:   1,
+	}
+
+	comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+	if err != nil {
+		t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+	}
+
+	if comment.Path != "services/payments/.gitleaksignore" {
+		t.Errorf("Path = %s, want services/payments/.gitleaksignore", comment.Path)
+	}
+	for _, want := range []string{
+		"<!-- gitleaks-diff-comment: services/payments/.gitleaksignore:4:RIGHT -->",
+		"`services/payments/config/app.yml`",
+		"https://github.com/owner/repo/blob/abc123/services/payments/config/app.yml#L12",
+	} {
+		if !strings.Contains(comment.Body, want) {
+			t.Errorf("Comment body should contain %q: %s", want, comment.Body)
+		}
+	}
+}
+
+func TestNewGeneratedComment_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		validation *diff.EntryValidation
+		want       string
+		notWant    string
+	}{
+		{
+			name:       "not validated",
+			validation: nil,
+			notWant:    "Warning",
+		},
+		{
+			name:       "file and line exist",
+			validation: &diff.EntryValidation{Revision: "abcdef1234", FileExists: true, LineExists: true, LineCount: 20},
+			notWant:    "Warning",
+		},
+		{
+			name:       "missing file",
+			validation: &diff.EntryValidation{Revision: "abcdef1234"},
+			want:       "`config/app.yml` does not exist at commit abcdef1",
+		},
+		{
+			name:       "line past end of file",
+			validation: &diff.EntryValidation{Revision: "abcdef1234", FileExists: true, LineCount: 5},
+			want:       "`config/app.yml` has only 5 lines at commit abcdef1, so line 12 does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := &diff.DiffChange{
+				FilePath:   ".gitleaksignore",
+				Operation:  diff.OperationAddition,
+				LineNumber: 1,
+				Content:    "config/app.yml:12",
+				Validation: tt.validation,
+			}
+
+			comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+			if err != nil {
+				t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+			}
+			if tt.want != "" && !strings.Contains(comment.Body, tt.want) {
+				t.Errorf("Comment body should contain %q: %s", tt.want, comment.Body)
+			}
+			if tt.notWant != "" && strings.Contains(comment.Body, tt.notWant) {
+				t.Errorf("Comment body should not contain %q: %s", tt.notWant, comment.Body)
+			}
+		})
+	}
+}
+
+func TestNewGeneratedComment_WildcardMatches(t *testing.T) {
+	var matches []string
+	for i := 1; i <= 12; i++ {
+		matches = append(matches, fmt.Sprintf("config/%02d.env", i))
+	}
+
+	change := &diff.DiffChange{
+		FilePath:   ".gitleaksignore",
+		Operation:  diff.OperationAddition,
+		LineNumber: 3,
+		Content:    "config/*.env",
+		Validation: &diff.EntryValidation{
+			Revision:       "abcdef1234",
+			FileExists:     true,
+			LineExists:     true,
+			Matches:        matches,
+			MatchThreshold: 10,
+		},
+	}
+
+	comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+	if err != nil {
+		t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"**Matches 12 files** at commit abcdef1",
+		"- [`config/01.env`](https://github.com/owner/repo/blob/abcdef1234/config/01.env)",
+		"- [`config/10.env`]",
+		"- …and 2 more",
+		"more than the limit of 10",
+	} {
+		if !strings.Contains(comment.Body, want) {
+			t.Errorf("Comment body should contain %q: %s", want, comment.Body)
+		}
+	}
+	if strings.Contains(comment.Body, "config/11.env") {
+		t.Errorf("Comment body should list at most %d matches: %s", maxListedMatches, comment.Body)
+	}
+}
+
+func TestNewGeneratedComment_WildcardNoMatches(t *testing.T) {
+	change := &diff.DiffChange{
+		FilePath:   ".gitleaksignore",
+		Operation:  diff.OperationAddition,
+		LineNumber: 3,
+		Content:    "config/*.env",
+		Validation: &diff.EntryValidation{Revision: "abcdef1234", MatchThreshold: 10},
+	}
+
+	comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+	if err != nil {
+		t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+	}
+	if !strings.Contains(comment.Body, "`config/*.env` matches no files at commit abcdef1") {
+		t.Errorf("Comment body should warn that the pattern matches nothing: %s", comment.Body)
+	}
+}
+
+func TestNewGeneratedComment_Snippet(t *testing.T) {
+	snippet := []diff.SnippetLine{
+		{Number: 41, Text: "user: deploy"},
+		{Number: 42, Text: "password: [REDACTED]", Target: true},
+		{Number: 43, Text: "note: use ```code``` here"},
+	}
+
+	tests := []struct {
+		operation diff.OperationType
+		heading   string
+	}{
+		{diff.OperationAddition, "**Excluded line** at commit abcdef1:"},
+		{diff.OperationDeletion, "**Line scanned again** at commit abcdef1:"},
+		{diff.OperationModification, "**Excluded line** at commit abcdef1:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.operation), func(t *testing.T) {
+			change := &diff.DiffChange{
+				FilePath:        ".gitleaksignore",
+				Operation:       tt.operation,
+				LineNumber:      3,
+				OldLineNumber:   3,
+				Content:         "config/secrets.yml:42",
+				PreviousContent: "config/secrets.yml:41",
+				Validation: &diff.EntryValidation{
+					Revision:   "abcdef1234",
+					FileExists: true,
+					LineExists: true,
+					LineCount:  43,
+					Snippet:    snippet,
+				},
+			}
+
+			comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+			if err != nil {
+				t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+			}
+
+			want := tt.heading + "\n\n````\n" +
+				"    41 | user: deploy\n" +
+				">   42 | password: [REDACTED]\n" +
+				"    43 | note: use ```code``` here\n" +
+				"````\n"
+			if !strings.Contains(comment.Body, want) {
+				t.Errorf("Comment body should contain the snippet in a fence longer than its backticks:\nwant:\n%s\ngot:\n%s", want, comment.Body)
+			}
+		})
+	}
+}
+
+func TestNewGeneratedComment_Verification(t *testing.T) {
+	tests := []struct {
+		name     string
+		findings []diff.Finding
+		want     string
+	}{
+		{
+			name:     "one finding",
+			findings: []diff.Finding{{RuleID: "aws-access-token", File: "config/aws.yml", StartLine: 3}},
+			want:     "suppresses 1 finding (rule `aws-access-token`) at the PR head",
+		},
+		{
+			name: "several rules",
+			findings: []diff.Finding{
+				{RuleID: "generic-api-key", File: "config/aws.yml", StartLine: 7},
+				{RuleID: "aws-access-token", File: "config/aws.yml", StartLine: 3},
+			},
+			want: "suppresses 2 findings (rules `aws-access-token`, `generic-api-key`) at the PR head",
+		},
+		{
+			name: "no finding",
+			want: "matches no current finding — consider removing this entry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := &diff.DiffChange{
+				FilePath:     ".gitleaksignore",
+				Operation:    diff.OperationAddition,
+				LineNumber:   3,
+				Content:      "config/aws.yml",
+				Verification: &diff.EntryVerification{Findings: tt.findings},
+			}
+
+			comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+			if err != nil {
+				t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+			}
+			if !strings.Contains(comment.Body, tt.want) {
+				t.Errorf("Comment body should contain %q: %s", tt.want, comment.Body)
+			}
+		})
+	}
+}
+
+func TestNewGeneratedComment_PolicyViolations(t *testing.T) {
+	violations := []diff.PolicyViolation{
+		{Rule: diff.PolicyNoWildcards, Message: "`*.env` is a wildcard pattern.", Blocking: true},
+		{Rule: diff.PolicyForbiddenPaths, Message: "`*.env` matches `src/a.env`.", Blocking: false},
+	}
+
+	for _, operation := range []diff.OperationType{diff.OperationAddition, diff.OperationModification} {
+		t.Run(string(operation), func(t *testing.T) {
+			change := &diff.DiffChange{
+				Operation:       operation,
+				LineNumber:      2,
+				Content:         "*.env",
+				PreviousContent: "a.env",
+				Violations:      violations,
+			}
+
+			comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+			if err != nil {
+				t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+			}
+			for _, want := range []string{
+				"⛔ **Policy violation** (`no-wildcards`): `*.env` is a wildcard pattern.",
+				"⚠️ **Policy warning** (`forbidden-paths`): `*.env` matches `src/a.env`.",
+			} {
+				if !strings.Contains(comment.Body, want) {
+					t.Errorf("Comment body should contain %q: %s", want, comment.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestNewGeneratedComment_Metadata(t *testing.T) {
+	for _, operation := range []diff.OperationType{diff.OperationAddition, diff.OperationModification} {
+		t.Run(string(operation), func(t *testing.T) {
+			change := &diff.DiffChange{
+				Operation:       operation,
+				LineNumber:      2,
+				Content:         "test/key.pem:1",
+				PreviousContent: "test/key.pem:3",
+				Metadata:        &diff.EntryMetadata{Reason: "test fixture", Expires: "2027-01-31"},
+			}
+
+			comment, err := NewGeneratedComment(change, "owner/repo", "abc123", "")
+			if err != nil {
+				t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+			}
+			if !strings.Contains(comment.Body, "**Justification**:\n- Reason: test fixture\n- Expires: 2027-01-31\n\n") {
+				t.Errorf("Comment body should list the justification: %s", comment.Body)
+			}
+			if strings.Contains(comment.Body, "Ticket") {
+				t.Errorf("Comment body should omit the missing ticket: %s", comment.Body)
+			}
+		})
+	}
+
+	comment, err := NewGeneratedComment(&diff.DiffChange{Operation: diff.OperationAddition, LineNumber: 1, Content: "a.txt"}, "owner/repo", "abc123", "")
+	if err != nil {
+		t.Fatalf("NewGeneratedComment() unexpected error: %v", err)
+	}
+	if strings.Contains(comment.Body, "Justification") {
+		t.Errorf("Comment body should have no justification without metadata: %s", comment.Body)
+	}
+}
+
 func TestRenderTemplate_Addition(t *testing.T) {
 	data := CommentData{
 		FilePattern:   "config/secrets.yml",
@@ -0,0 +1,51 @@
+package comment
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+//go:embed templates/inline_allow.md
+var inlineAllowTemplate string
+
+// NewInlineAllowComment creates a GeneratedComment for a gitleaks:allow annotation
+// using the default templates
+func NewInlineAllowComment(allow *diff.InlineAllow, repo, commitSHA, ghHost string) (*GeneratedComment, error) {
+	return NewGenerator(repo, commitSHA, ghHost).InlineAllowComment(allow)
+}
+
+// InlineAllowComment creates a GeneratedComment for a gitleaks:allow annotation
+// The comment is posted on the annotated line itself.
+func (g *Generator) InlineAllowComment(allow *diff.InlineAllow) (*GeneratedComment, error) {
+	repo, commitSHA := g.Context.Repo.FullName, g.Context.CommitSHA
+	line := allow.LineNumber
+	if line <= 0 {
+		return nil, fmt.Errorf("gitleaks:allow in %s has no line number", allow.FilePath)
+	}
+
+	target := diff.GitleaksEntry{FilePattern: allow.FilePath, LineNumber: line}
+	data := InlineAllowCommentData{
+		TemplateContext: g.Context,
+		FilePath:        allow.FilePath,
+		LineNumber:      line,
+		FileLink:        target.FileLink(repo, commitSHA, g.GHHost),
+	}
+
+	body, err := g.Templates.render(TemplateInlineAllow, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	// Same marker format as .gitleaksignore comments so override mode and /clear apply
+	marker := fmt.Sprintf("<!-- gitleaks-diff-comment: %s:%d:%s -->", allow.FilePath, line, "RIGHT")
+
+	return &GeneratedComment{
+		Body:     marker + "\n" + body,
+		Path:     allow.FilePath,
+		Line:     line,
+		Side:     "RIGHT",
+		CommitID: commitSHA,
+	}, nil
+}
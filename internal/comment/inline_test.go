@@ -0,0 +1,43 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+func TestNewInlineAllowComment(t *testing.T) {
+	allow := &diff.InlineAllow{
+		FilePath:   "app/config.py",
+		LineNumber: 12,
+		Content:    `API_KEY = "sk-live-1234"  # gitleaks:allow`,
+	}
+
+	comment, err := NewInlineAllowComment(allow, "owner/repo", "abc123", "")
+	if err != nil {
+		t.Fatalf("NewInlineAllowComment() unexpected error: %v", err)
+	}
+
+	if comment.Path != "app/config.py" || comment.Line != 12 || comment.Side != "RIGHT" {
+		t.Errorf("comment anchored at %s:%d (%s), want app/config.py:12 (RIGHT)", comment.Path, comment.Line, comment.Side)
+	}
+	for _, want := range []string{
+		"<!-- gitleaks-diff-comment: app/config.py:12:RIGHT -->",
+		"Inline Gitleaks Bypass Added",
+		"https://github.com/owner/repo/blob/abc123/app/config.py#L12",
+	} {
+		if !strings.Contains(comment.Body, want) {
+			t.Errorf("Comment body should contain %q: %s", want, comment.Body)
+		}
+	}
+	if strings.Contains(comment.Body, "sk-live-1234") {
+		t.Errorf("Comment body must not repeat the annotated line: %s", comment.Body)
+	}
+}
+
+func TestNewInlineAllowComment_NoLine(t *testing.T) {
+	if _, err := NewInlineAllowComment(&diff.InlineAllow{FilePath: "a.go"}, "owner/repo", "abc123", ""); err == nil {
+		t.Error("NewInlineAllowComment() expected error without a line number")
+	}
+}
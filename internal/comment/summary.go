@@ -0,0 +1,121 @@
+package comment
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+//go:embed templates/summary.md
+var summaryTemplate string
+
+// SummaryMarker identifies the pull request summary comment, which is
+// updated in place on every run instead of being posted again
+const SummaryMarker = "<!-- gitleaks-diff-comment: summary -->"
+
+// Summary renders the pull request summary comment tabulating every
+// .gitleaksignore change, with SummaryMarker as its first line
+// policy is the result of evaluating the changes, or nil if there is no policy.
+func (g *Generator) Summary(changes []diff.DiffChange, policy *diff.PolicyResult) (string, error) {
+	data := SummaryData{
+		TemplateContext: g.Context,
+		HeadCommit:      shortSHA(g.Context.CommitSHA),
+	}
+	if policy != nil {
+		data.Violations = policyViolations(policy.Violations)
+		data.PolicyBlocking = policy.Blocking
+		data.PolicyWarnings = policy.Warnings
+	}
+
+	for i := range changes {
+		change := &changes[i]
+		entry, err := g.commentData(change)
+		if err != nil {
+			return "", fmt.Errorf("failed to describe change at line %d: %w", summaryLine(change), err)
+		}
+
+		ignoreFile := change.FilePath
+		if ignoreFile == "" {
+			ignoreFile = diff.GitleaksIgnorePath
+		}
+		data.Entries = append(data.Entries, SummaryEntry{
+			CommentData: entry,
+			IgnoreFile:  ignoreFile,
+			IgnoreLine:  summaryLine(change),
+			Status:      entryStatus(entry),
+		})
+
+		switch change.Operation {
+		case diff.OperationAddition:
+			data.Added++
+		case diff.OperationDeletion:
+			data.Removed++
+		case diff.OperationModification:
+			data.Modified++
+		}
+	}
+
+	body, err := g.Templates.render(TemplateSummary, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return SummaryMarker + "\n" + body, nil
+}
+
+// summaryLine returns the ignore file line a change is on: the old file line
+// for removals, the new file line otherwise
+func summaryLine(change *diff.DiffChange) int {
+	if change.IsDeletion() {
+		return change.OldLineNumber
+	}
+	return change.LineNumber
+}
+
+// entryStatus summarizes validation, policy and verification of an entry in a few words
+func entryStatus(data CommentData) string {
+	var status []string
+
+	if data.Validated {
+		switch {
+		case data.IsPattern && !data.FileExists:
+			status = append(status, "❗ matches no files")
+		case !data.FileExists:
+			status = append(status, "❗ file not found")
+		case data.BroadPattern:
+			status = append(status, fmt.Sprintf("🚨 over %d files", data.MatchThreshold))
+		case !data.LineExists:
+			status = append(status, fmt.Sprintf("❗ line %d past end of file", data.LineNumber))
+		default:
+			status = append(status, "✅ found")
+		}
+	}
+
+	for _, v := range data.Violations {
+		if v.Blocking {
+			status = append(status, fmt.Sprintf("⛔ `%s`", v.Rule))
+		} else {
+			status = append(status, fmt.Sprintf("⚠️ `%s`", v.Rule))
+		}
+	}
+
+	if data.Verified && data.Operation != string(diff.OperationDeletion) {
+		if data.FindingCount > 0 {
+			status = append(status, fmt.Sprintf("🎯 %d %s", data.FindingCount, pluralize(data.FindingCount, "finding")))
+		} else {
+			status = append(status, "🗑️ unused")
+		}
+	}
+
+	if len(status) == 0 {
+		return "not checked"
+	}
+	return strings.Join(status, ", ")
+}
+
+// cell escapes text for use in a markdown table cell
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,91 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+func TestGenerator_Summary(t *testing.T) {
+	generator := NewGenerator("owner/repo", "abc123def456", "")
+
+	changes := []diff.DiffChange{
+		{
+			Operation:  diff.OperationAddition,
+			LineNumber: 3,
+			Content:    "config/app.yml:generic-api-key:12",
+			Validation: &diff.EntryValidation{Revision: "abc123def456", FileExists: true, LineExists: true, LineCount: 40},
+			Verification: &diff.EntryVerification{
+				Findings: []diff.Finding{{RuleID: "generic-api-key", File: "config/app.yml", StartLine: 12}},
+			},
+		},
+		{
+			Operation:  diff.OperationAddition,
+			LineNumber: 4,
+			Content:    "secrets/*.env",
+			Validation: &diff.EntryValidation{Revision: "abc123def456", FileExists: true, LineExists: true, Matches: []string{"secrets/a.env", "secrets/b.env"}, MatchThreshold: 25},
+			Violations: []diff.PolicyViolation{{Rule: diff.PolicyNoWildcards, Message: "no wildcards", Blocking: true}},
+		},
+		{
+			FilePath:      "svc/.gitleaksignore",
+			Operation:     diff.OperationDeletion,
+			OldLineNumber: 7,
+			Content:       "old.json",
+		},
+		{
+			Operation:       diff.OperationModification,
+			LineNumber:      5,
+			Content:         "db.yml:20",
+			PreviousContent: "db.yml:18",
+			Validation:      &diff.EntryValidation{Revision: "abc123def456", FileExists: true, LineCount: 10},
+		},
+	}
+
+	policy := &diff.PolicyResult{
+		Violations: []diff.PolicyViolation{{Rule: diff.PolicyMaxNewEntries, Message: "Too many entries.", Blocking: true}},
+		Blocking:   2,
+	}
+	body, err := generator.Summary(changes, policy)
+	if err != nil {
+		t.Fatalf("Summary() unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(body, SummaryMarker+"\n") {
+		t.Errorf("summary must start with the marker:\n%s", body)
+	}
+
+	for _, want := range []string{
+		"changes 4 gitleaks exclusions: 2 added, 1 removed, 1 modified",
+		"| 🔒 Added | `config/app.yml:generic-api-key:12`<br><sub>.gitleaksignore:3</sub> | `generic-api-key` | [`config/app.yml`](https://github.com/owner/repo/blob/abc123def456/config/app.yml#L12) | ✅ found, 🎯 1 finding | — |",
+		"| `secrets/*.env`<br><sub>.gitleaksignore:4</sub> | any |",
+		"| ✅ found, ⛔ `no-wildcards` | 2 |",
+		"⛔ **Policy violation** (`max-new-entries`): Too many entries.",
+		"⛔ **2 blocking policy violations**",
+		"| ✅ Removed | `old.json`<br><sub>svc/.gitleaksignore:7</sub>",
+		"| not checked | — |",
+		"| ✏️ Modified | `db.yml:18` → `db.yml:20`",
+		"❗ line 20 past end of file",
+		"Updated for commit abc123d.",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("summary missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestGenerator_SummaryEmpty(t *testing.T) {
+	body, err := NewGenerator("owner/repo", "abc123", "").Summary(nil, nil)
+	if err != nil {
+		t.Fatalf("Summary() unexpected error: %v", err)
+	}
+	if !strings.Contains(body, "no longer changes any gitleaks exclusions") || strings.Contains(body, "| Change |") {
+		t.Errorf("an empty summary should say so without a table:\n%s", body)
+	}
+}
+
+func TestCell(t *testing.T) {
+	if got := cell("a|b\n  c"); got != `a\|b c` {
+		t.Errorf("cell() = %q, want %q", got, `a\|b c`)
+	}
+}
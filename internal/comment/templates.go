@@ -0,0 +1,359 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"unicode/utf8"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+// DefaultTemplateDir is where repositories keep their comment template overrides
+const DefaultTemplateDir = ".github/gitleaks-diff-comment"
+
+// Template names; the override for each is <template dir>/<name>.md
+const (
+	TemplateAddition          = "addition"
+	TemplateDeletion          = "deletion"
+	TemplateModification      = "modification"
+	TemplateAllowlistAddition = "allowlist_addition"
+	TemplateAllowlistDeletion = "allowlist_deletion"
+	TemplateInlineAllow       = "inline_allow"
+	TemplateSummary           = "summary"
+	TemplateAudit             = "audit"
+)
+
+// templateDefaults maps each template name to its embedded default and the
+// type of data it is rendered with
+var templateDefaults = map[string]struct {
+	text string
+	data any
+}{
+	TemplateAddition:          {additionTemplate, CommentData{}},
+	TemplateDeletion:          {deletionTemplate, CommentData{}},
+	TemplateModification:      {modificationTemplate, CommentData{}},
+	TemplateAllowlistAddition: {allowlistAdditionTemplate, AllowlistCommentData{}},
+	TemplateAllowlistDeletion: {allowlistDeletionTemplate, AllowlistCommentData{}},
+	TemplateInlineAllow:       {inlineAllowTemplate, InlineAllowCommentData{}},
+	TemplateSummary:           {summaryTemplate, SummaryData{}},
+	TemplateAudit:             {auditTemplate, AuditData{}},
+}
+
+// TemplateNames returns the names of all comment templates, in a fixed order
+func TemplateNames() []string {
+	return []string{
+		TemplateAddition,
+		TemplateDeletion,
+		TemplateModification,
+		TemplateAllowlistAddition,
+		TemplateAllowlistDeletion,
+		TemplateInlineAllow,
+		TemplateSummary,
+		TemplateAudit,
+	}
+}
+
+// TemplateFuncs returns the functions available to comment templates
+//
+//	link "text" .FileLink        [text](url)
+//	truncate 40 .OriginalLine    at most 40 characters, with a trailing …
+//	pluralize .MatchCount "file" "file" or "files"; an explicit plural may follow
+//	cell .OriginalLine           escaped for a markdown table cell
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"link":      link,
+		"truncate":  truncate,
+		"pluralize": pluralize,
+		"cell":      cell,
+	}
+}
+
+// link formats a markdown link
+func link(text, url string) string {
+	if url == "" {
+		return text
+	}
+	return "[" + text + "](" + url + ")"
+}
+
+// truncate shortens s to at most n characters, ending with … when cut
+func truncate(n int, s string) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// pluralize returns singular if count is 1, otherwise plural (default singular + "s")
+func pluralize(count int, singular string, plural ...string) string {
+	if count == 1 {
+		return singular
+	}
+	if len(plural) > 0 {
+		return plural[0]
+	}
+	return singular + "s"
+}
+
+// Templates is a set of parsed comment templates
+type Templates struct {
+	set map[string]*template.Template
+
+	// Overrides lists the repository files that replaced a default template
+	Overrides []string
+}
+
+// defaultTemplates is the embedded set, parsed once
+var defaultTemplates = mustDefaultTemplates()
+
+func mustDefaultTemplates() *Templates {
+	t := &Templates{set: make(map[string]*template.Template)}
+	for _, name := range TemplateNames() {
+		tmpl, err := ParseTemplate(name, templateDefaults[name].text)
+		if err != nil {
+			panic(fmt.Sprintf("embedded template %s: %v", name, err))
+		}
+		t.set[name] = tmpl
+	}
+	return t
+}
+
+// DefaultTemplates returns the embedded templates
+func DefaultTemplates() *Templates {
+	return defaultTemplates
+}
+
+// LoadTemplates reads template overrides from dir at rev, falling back to the
+// embedded default for each template that has no override
+// Every override is parsed and checked before any comment is rendered; all
+// problems are returned together.
+func LoadTemplates(ctx context.Context, reader diff.BlobReader, rev, dir string) (*Templates, error) {
+	if dir == "" {
+		dir = DefaultTemplateDir
+	}
+
+	t := &Templates{set: make(map[string]*template.Template)}
+	var errs []error
+	for _, name := range TemplateNames() {
+		t.set[name] = defaultTemplates.set[name]
+
+		file := path.Join(dir, name+".md")
+		content, err := reader.ReadBlob(ctx, rev, file)
+		if errors.Is(err, diff.ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read template %s: %w", file, err))
+			continue
+		}
+
+		tmpl, err := ParseTemplate(name, string(content))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid template %s: %w", file, err))
+			continue
+		}
+		t.set[name] = tmpl
+		t.Overrides = append(t.Overrides, file)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ParseTemplate parses the template called name and checks that every field
+// it references exists in the data that template is rendered with
+// Unknown names are rejected, since only the fixed set of templates is rendered.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	defaults, ok := templateDefaults[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown template %q", name)
+	}
+
+	tmpl, err := template.New(name).Funcs(TemplateFuncs()).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	// text/template only reports a missing field when the branch using it
+	// runs, so walk the whole tree instead of relying on a trial render
+	root := reflect.TypeOf(defaults.data)
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		// Templates from {{ define }} may be invoked with any data
+		dot := root
+		if t.Name() != name {
+			dot = nil
+		}
+		checker := &fieldChecker{tree: t.Tree, root: dot}
+		if err := checker.list(t.Tree.Root, dot); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+// render executes the template called name with data
+func (t *Templates) render(name string, data any) (string, error) {
+	tmpl, ok := t.set[name]
+	if !ok {
+		return "", fmt.Errorf("unknown template %q", name)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// fieldChecker verifies field references in a parsed template against the
+// data type it is rendered with. A nil type means the type of a value is not
+// known statically, and references through it are not checked.
+type fieldChecker struct {
+	tree *parse.Tree
+
+	// root is the type of "$"
+	root reflect.Type
+}
+
+// list checks the nodes of a list with dot as the type of "."
+func (c *fieldChecker) list(n *parse.ListNode, dot reflect.Type) error {
+	if n == nil {
+		return nil
+	}
+	for _, node := range n.Nodes {
+		if err := c.node(node, dot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *fieldChecker) node(node parse.Node, dot reflect.Type) error {
+	switch n := node.(type) {
+	case *parse.ActionNode:
+		return c.pipe(n.Pipe, dot)
+	case *parse.IfNode:
+		return c.branch(&n.BranchNode, dot, dot)
+	case *parse.WithNode:
+		return c.branch(&n.BranchNode, dot, c.pipeType(n.Pipe, dot))
+	case *parse.RangeNode:
+		return c.branch(&n.BranchNode, dot, elemType(c.pipeType(n.Pipe, dot)))
+	case *parse.TemplateNode:
+		return c.pipe(n.Pipe, dot)
+	}
+	return nil
+}
+
+// branch checks an if/with/range node; inner is the type of "." in its body
+func (c *fieldChecker) branch(n *parse.BranchNode, dot, inner reflect.Type) error {
+	if err := c.pipe(n.Pipe, dot); err != nil {
+		return err
+	}
+	if err := c.list(n.List, inner); err != nil {
+		return err
+	}
+	return c.list(n.ElseList, dot)
+}
+
+// pipe checks every field reference in a pipeline
+func (c *fieldChecker) pipe(pipe *parse.PipeNode, dot reflect.Type) error {
+	if pipe == nil {
+		return nil
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			if _, err := c.arg(arg, dot); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// arg checks a command argument and returns its type, if known
+func (c *fieldChecker) arg(arg parse.Node, dot reflect.Type) (reflect.Type, error) {
+	switch a := arg.(type) {
+	case *parse.DotNode:
+		return dot, nil
+	case *parse.FieldNode:
+		return c.field(arg, dot, a.Ident)
+	case *parse.VariableNode:
+		if a.Ident[0] == "$" {
+			return c.field(arg, c.root, a.Ident[1:])
+		}
+	case *parse.PipeNode:
+		return nil, c.pipe(a, dot)
+	}
+	return nil, nil
+}
+
+// pipeType returns the type a pipeline evaluates to, if it is a plain reference
+func (c *fieldChecker) pipeType(pipe *parse.PipeNode, dot reflect.Type) reflect.Type {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+	t, _ := c.arg(pipe.Cmds[0].Args[0], dot)
+	return t
+}
+
+// field follows a chain of field names from t
+func (c *fieldChecker) field(node parse.Node, t reflect.Type, idents []string) (reflect.Type, error) {
+	for _, ident := range idents {
+		if t == nil {
+			return nil, nil
+		}
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, nil
+		}
+
+		field, ok := t.FieldByName(ident)
+		if !ok || !field.IsExported() {
+			if _, ok := reflect.PointerTo(t).MethodByName(ident); ok {
+				return nil, nil // Method results are not followed
+			}
+			location, _ := c.tree.ErrorContext(node)
+			return nil, fmt.Errorf("%s: %s has no field %q (available: %s)",
+				location, t.Name(), ident, strings.Join(fieldNames(t), ", "))
+		}
+		t = field.Type
+	}
+	return t, nil
+}
+
+// elemType returns the element type ranged over for slices, arrays and maps
+func elemType(t reflect.Type) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return t.Elem()
+	}
+	return nil
+}
+
+// fieldNames lists the exported fields of a struct type, including promoted ones
+func fieldNames(t reflect.Type) []string {
+	var names []string
+	for _, f := range reflect.VisibleFields(t) {
+		if f.IsExported() && !f.Anonymous {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,220 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+// fakeBlobReader serves file contents from a map keyed by "rev:path"
+type fakeBlobReader struct {
+	files map[string]string
+	err   error
+}
+
+func (r *fakeBlobReader) ReadBlob(ctx context.Context, rev, path string) ([]byte, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	content, ok := r.files[rev+":"+path]
+	if !ok {
+		return nil, diff.ErrObjectNotFound
+	}
+	return []byte(content), nil
+}
+
+func TestDefaultTemplates(t *testing.T) {
+	for _, name := range TemplateNames() {
+		if _, ok := DefaultTemplates().set[name]; !ok {
+			t.Errorf("default template %s is missing", name)
+		}
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		text      string
+		wantError string
+	}{
+		{
+			name:     "context, funcs and nested fields",
+			template: TemplateAddition,
+			text: `{{ .PR.Author }} in {{ link .Repo.FullName .Repo.URL }}: {{ truncate 20 .OriginalLine }}
+{{ range .Matches }}{{ link .Path .Link }}{{ end }}
+{{ with .Findings }}{{ range . }}{{ .RuleID }}{{ end }}{{ end }}
+{{ .MatchCount }} {{ pluralize .MatchCount "file" }}`,
+		},
+		{
+			name:     "root variable inside range",
+			template: TemplateAddition,
+			text:     `{{ range .Snippet }}{{ $.FilePattern }}:{{ .Number }}{{ end }}`,
+		},
+		{
+			name:      "syntax error",
+			template:  TemplateAddition,
+			text:      `{{ if .IsPattern }}unclosed`,
+			wantError: "unexpected EOF",
+		},
+		{
+			name:      "unknown function",
+			template:  TemplateDeletion,
+			text:      `{{ shout .FilePattern }}`,
+			wantError: `function "shout" not defined`,
+		},
+		{
+			name:      "unknown field in a branch that is not taken",
+			template:  TemplateAddition,
+			text:      "line one\n{{ if false }}{{ .FilePath }}{{ end }}",
+			wantError: `addition:2:17: CommentData has no field "FilePath"`,
+		},
+		{
+			name:      "unknown field inside range",
+			template:  TemplateModification,
+			text:      `{{ range .Matches }}{{ .Name }}{{ end }}`,
+			wantError: `FileMatch has no field "Name"`,
+		},
+		{
+			name:      "unknown nested field",
+			template:  TemplateInlineAllow,
+			text:      `{{ $.PR.Login }}`,
+			wantError: `PullRequestInfo has no field "Login"`,
+		},
+		{
+			name:      "field of another template's data",
+			template:  TemplateAllowlistAddition,
+			text:      `{{ .FilePattern }}`,
+			wantError: "available: Repo, PR, CommitSHA, Kind",
+		},
+		{
+			name:      "unknown template",
+			template:  "footer",
+			text:      `hello`,
+			wantError: `unknown template "footer"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTemplate(tt.template, tt.text)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("ParseTemplate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("ParseTemplate() error = %v, want error containing %q", err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	reader := &fakeBlobReader{files: map[string]string{
+		"base:.github/gitleaks-diff-comment/addition.md": "Custom: {{ .FilePattern }} by @{{ .PR.Author }}",
+		"head:.github/gitleaks-diff-comment/addition.md": "Changed in the PR",
+	}}
+
+	templates, err := LoadTemplates(context.Background(), reader, "base", "")
+	if err != nil {
+		t.Fatalf("LoadTemplates() unexpected error: %v", err)
+	}
+	if len(templates.Overrides) != 1 || templates.Overrides[0] != ".github/gitleaks-diff-comment/addition.md" {
+		t.Errorf("Overrides = %v, want the addition override", templates.Overrides)
+	}
+
+	generator := NewGenerator("owner/repo", "abc123", "")
+	generator.Templates = templates
+	generator.SetPullRequest(PullRequestInfo{Number: 7, Author: "octocat"})
+
+	addition, err := generator.Comment(&diff.DiffChange{Operation: diff.OperationAddition, LineNumber: 1, Content: "secrets.env"})
+	if err != nil {
+		t.Fatalf("Comment() unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(addition.Body, "\nCustom: secrets.env by @octocat") {
+		t.Errorf("addition should use the override: %s", addition.Body)
+	}
+	if !strings.HasPrefix(addition.Body, "<!-- gitleaks-diff-comment: .gitleaksignore:1:RIGHT -->") {
+		t.Errorf("the marker must be kept with custom templates: %s", addition.Body)
+	}
+
+	deletion, err := generator.Comment(&diff.DiffChange{Operation: diff.OperationDeletion, OldLineNumber: 1, Content: "secrets.env"})
+	if err != nil {
+		t.Fatalf("Comment() unexpected error: %v", err)
+	}
+	if !strings.Contains(deletion.Body, "Gitleaks Exclusion Removed") {
+		t.Errorf("deletion should fall back to the default template: %s", deletion.Body)
+	}
+}
+
+func TestLoadTemplates_Errors(t *testing.T) {
+	reader := &fakeBlobReader{files: map[string]string{
+		"base:templates/addition.md":     "{{ .Nope }}",
+		"base:templates/inline_allow.md": "{{ end }}",
+	}}
+
+	_, err := LoadTemplates(context.Background(), reader, "base", "templates")
+	if err == nil {
+		t.Fatal("LoadTemplates() expected error")
+	}
+	for _, want := range []string{"invalid template templates/addition.md", "invalid template templates/inline_allow.md"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadTemplates() error = %v, want it to contain %q", err, want)
+		}
+	}
+
+	_, err = LoadTemplates(context.Background(), &fakeBlobReader{err: errors.New("boom")}, "base", "")
+	if err == nil || !strings.Contains(err.Error(), "failed to read template") {
+		t.Errorf("LoadTemplates() error = %v, want read failure", err)
+	}
+}
+
+func TestGenerator_Context(t *testing.T) {
+	generator := NewGenerator("owner/repo", "abc123", "github.company.com")
+	generator.SetPullRequest(PullRequestInfo{Number: 42, Author: "octocat"})
+
+	ctx := generator.Context
+	if ctx.Repo.Owner != "owner" || ctx.Repo.Name != "repo" || ctx.Repo.URL != "https://github.company.com/owner/repo" {
+		t.Errorf("Repo = %+v, want owner/repo on the enterprise host", ctx.Repo)
+	}
+	if ctx.PR.URL != "https://github.company.com/owner/repo/pull/42" {
+		t.Errorf("PR.URL = %q, want the pull request page", ctx.PR.URL)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	if got := link("a.env", "https://x/a.env"); got != "[a.env](https://x/a.env)" {
+		t.Errorf("link() = %q", got)
+	}
+	if got := link("a.env", ""); got != "a.env" {
+		t.Errorf("link() without URL = %q, want plain text", got)
+	}
+
+	if got := truncate(5, "abcdefgh"); got != "abcd…" {
+		t.Errorf("truncate(5) = %q, want %q", got, "abcd…")
+	}
+	if got := truncate(10, "short"); got != "short" {
+		t.Errorf("truncate(10) = %q, want unchanged", got)
+	}
+
+	tests := []struct {
+		count  int
+		plural []string
+		want   string
+	}{
+		{1, nil, "file"},
+		{0, nil, "files"},
+		{3, nil, "files"},
+		{2, []string{"entries"}, "entries"},
+	}
+	for _, tt := range tests {
+		if got := pluralize(tt.count, "file", tt.plural...); got != tt.want {
+			t.Errorf("pluralize(%d, %v) = %q, want %q", tt.count, tt.plural, got, tt.want)
+		}
+	}
+}
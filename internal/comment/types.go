@@ -7,7 +7,7 @@ type GeneratedComment struct {
 	// Comment body in markdown format
 	Body string `json:"body"`
 
-	// File path for the comment (always ".gitleaksignore")
+	// File path for the comment (the ignore file or gitleaks config)
 	Path string `json:"path"`
 
 	// Line number in the file (for Line-based API)
@@ -22,16 +22,280 @@ type GeneratedComment struct {
 	// Commit ID for the comment
 	CommitID string `json:"commit_id"`
 
-	// Source diff change (not serialized to JSON)
+	// Source diff change (not serialized to JSON, nil for allowlist and gitleaks:allow comments)
 	SourceChange *diff.DiffChange `json:"-"`
 }
 
+// RepoInfo describes the repository in comment templates
+type RepoInfo struct {
+	// FullName is "owner/repo"
+	FullName string
+	Owner    string
+	Name     string
+
+	// URL is the repository's web URL
+	URL string
+}
+
+// PullRequestInfo describes the pull request in comment templates
+// Fields other than Number are empty if the pull request could not be fetched.
+type PullRequestInfo struct {
+	Number int
+	Title  string
+
+	// Author is the login of the user who opened the pull request
+	Author string
+
+	// URL is the pull request's web URL
+	URL string
+}
+
+// TemplateContext is the data shared by every comment template
+type TemplateContext struct {
+	Repo RepoInfo
+	PR   PullRequestInfo
+
+	// CommitSHA is the commit comments are attached to
+	CommitSHA string
+}
+
 // CommentData is the data passed to comment templates
 type CommentData struct {
+	TemplateContext
+
 	FilePattern   string
 	FileLink      string
 	Operation     string
 	HasLineNumber bool
 	LineNumber    int
 	IsPattern     bool
+
+	// OriginalLine is the raw .gitleaksignore entry (the fingerprint)
+	OriginalLine string
+
+	// RuleID is the gitleaks rule from the fingerprint (empty if not specified)
+	RuleID string
+
+	// Commit is the abbreviated SHA the finding was reported in (git scan fingerprints only)
+	Commit string
+
+	// Fields describing the entry before a modification (empty otherwise)
+	PreviousFilePattern   string
+	PreviousOriginalLine  string
+	PreviousHasLineNumber bool
+	PreviousLineNumber    int
+
+	// ChangeSummary lists human-readable differences for modifications
+	ChangeSummary []string
+
+	// Validated is true if the referenced file was looked up
+	Validated bool
+
+	// FileExists and LineExists report what the lookup found
+	// LineExists is also true for entries without a line number
+	FileExists bool
+	LineExists bool
+
+	// FileLineCount is the number of lines in the referenced file
+	FileLineCount int
+
+	// ValidatedAt is the abbreviated commit the file was checked at
+	ValidatedAt string
+
+	// MatchCount is the number of files a wildcard entry matches
+	MatchCount int
+
+	// Matches are the first matched files, each with a link
+	Matches []FileMatch
+
+	// MoreMatches is the number of matched files not listed in Matches
+	MoreMatches int
+
+	// BroadPattern is true if MatchCount exceeds MatchThreshold
+	BroadPattern   bool
+	MatchThreshold int
+
+	// Snippet is the redacted entry line with surrounding context
+	Snippet []SnippetLine
+
+	// SnippetFence is a code fence longer than any backtick run in Snippet
+	SnippetFence string
+
+	// Verified is true if the entry was checked against a gitleaks scan of the head
+	Verified bool
+
+	// FindingCount is the number of current findings the entry suppresses
+	FindingCount int
+
+	// FindingRules lists the rules of those findings, e.g. "rule `aws-access-token`"
+	FindingRules string
+
+	// Findings are the current findings the entry suppresses
+	Findings []FindingInfo
+
+	// RuleDescription describes RuleID, taken from a matching finding if any
+	RuleDescription string
+
+	// Reason, Ticket and Expires come from the comment block above the entry
+	Reason  string
+	Ticket  string
+	Expires string
+
+	// Violations are the policy rules the entry breaks
+	Violations []PolicyViolation
+
+	// Blocked is true if any of Violations fails the run
+	Blocked bool
+}
+
+// PolicyViolation is a broken policy rule
+type PolicyViolation struct {
+	Rule    string
+	Message string
+
+	// Blocking is false for rules that only warn
+	Blocking bool
+}
+
+// FindingInfo is a gitleaks finding suppressed by an entry
+type FindingInfo struct {
+	RuleID      string
+	Description string
+	File        string
+	Line        int
+	Link        string
+}
+
+// SnippetLine is one line of a redacted snippet
+type SnippetLine struct {
+	Number int
+	Text   string
+
+	// Target is true for the line the entry refers to
+	Target bool
+}
+
+// FileMatch is a file matched by a wildcard entry
+type FileMatch struct {
+	Path string
+	Link string
+}
+
+// SummaryData is the data passed to the pull request summary template
+type SummaryData struct {
+	TemplateContext
+
+	// Entries are the changed .gitleaksignore entries, in diff order
+	Entries []SummaryEntry
+
+	// Added, Removed and Modified count Entries by operation
+	Added    int
+	Removed  int
+	Modified int
+
+	// HeadCommit is the abbreviated commit the summary describes
+	HeadCommit string
+
+	// Violations are policy rules broken by the pull request as a whole,
+	// such as the number of new entries
+	Violations []PolicyViolation
+
+	// PolicyBlocking and PolicyWarnings count all violations, including those on entries
+	PolicyBlocking int
+	PolicyWarnings int
+}
+
+// SummaryEntry is one row of the summary table
+// It has every field an entry comment is rendered with.
+type SummaryEntry struct {
+	CommentData
+
+	// IgnoreFile and IgnoreLine locate the entry (the old file line for removals)
+	IgnoreFile string
+	IgnoreLine int
+
+	// Status summarizes validation and verification, e.g. "✅ found, 🎯 1 finding"
+	Status string
+}
+
+// AuditData is the data passed to the audit report template
+type AuditData struct {
+	TemplateContext
+
+	// Revision is the abbreviated commit the ignore files were read at
+	Revision string
+
+	// Total is the number of entries audited
+	Total int
+
+	// Entries are the entries that need attention, in file order
+	Entries []AuditEntry
+
+	// Expired, Stale and Unused count Entries by problem; an entry may have several
+	Expired int
+	Stale   int
+	Unused  int
+
+	// Verified is true if entries were compared with a gitleaks scan, so
+	// Unused is meaningful
+	Verified bool
+}
+
+// AuditEntry is one row of the audit report
+type AuditEntry struct {
+	CommentData
+
+	// IgnoreFile and IgnoreLine locate the entry; IgnoreLink points at that line
+	IgnoreFile string
+	IgnoreLine int
+	IgnoreLink string
+
+	// Problems describe why the entry needs attention, e.g. "⌛ expired on 2026-01-31"
+	Problems []string
+}
+
+// AllowlistCommentData is the data passed to gitleaks config allowlist templates
+type AllowlistCommentData struct {
+	TemplateContext
+
+	// Kind is the allowlist array: "paths", "regexes", "stopwords" or "commits"
+	Kind string
+
+	// KindLabel is the human-readable kind, e.g. "Regex"
+	KindLabel string
+
+	// Value is the allowlist element
+	Value string
+
+	// RuleIDs are the rules the allowlist applies to (empty for all rules)
+	RuleIDs []string
+	Global  bool
+
+	// Scope describes the rules covered, e.g. "for rule `aws-access-key`"
+	Scope string
+
+	// Section describes where the element lives, e.g. "global allowlist `regexes`"
+	Section string
+
+	// Description is the allowlist's description from the config, if any
+	Description string
+
+	// ConfigPath is the gitleaks config file path
+	ConfigPath string
+
+	Operation string
+}
+
+// InlineAllowCommentData is the data passed to the gitleaks:allow template
+type InlineAllowCommentData struct {
+	TemplateContext
+
+	// FilePath is the annotated file
+	FilePath string
+
+	// LineNumber is the annotated line in the new file
+	LineNumber int
+
+	// FileLink is a permalink to the annotated line
+	FileLink string
 }
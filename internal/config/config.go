@@ -7,6 +7,8 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
 )
 
 // Config holds all configuration parsed from action inputs and environment
@@ -14,15 +16,39 @@ type Config struct {
 	// GitHub API token for authentication
 	GitHubToken string
 
-	// Pull request number
+	// GitHub App ID; with AppPrivateKey, the action authenticates as the
+	// app's installation on Repository instead of with GitHubToken
+	AppID int64
+
+	// PEM-encoded private key of the GitHub App
+	AppPrivateKey string
+
+	// Installation of the GitHub App to use (default: looked up for Repository)
+	AppInstallationID int64
+
+	// Pull request number (not used in audit mode)
 	PRNumber int
 
+	// Mode: "diff" (comment on a pull request's changes) or "audit" (report
+	// on every entry at CommitSHA, e.g. from a scheduled workflow)
+	Mode string
+
+	// Open or update a tracking issue with the audit report (default: false)
+	AuditIssue bool
+
+	// Title of the audit tracking issue when it is opened
+	AuditIssueTitle string
+
 	// Repository in format "owner/repo"
 	Repository string
 
 	// Commit SHA that triggered the action
 	CommitSHA string
 
+	// Base commit SHA of the pull request (e.g., pull_request.base.sha)
+	// The diff starts at its merge base with CommitSHA; if empty, origin/BaseRef is used
+	BaseSHA string
+
 	// Base branch reference (e.g., "main")
 	BaseRef string
 
@@ -35,9 +61,65 @@ type Config struct {
 	// Comment mode: "override" or "append"
 	CommentMode string
 
+	// Output mode: "comments" (review comments), "check" (a check run with
+	// annotations, needs no pull-requests permission) or "both"
+	OutputMode string
+
+	// Delivery mode: "comments" (one API call per comment) or "review"
+	// (new comments submitted together as one pull request review)
+	DeliveryMode string
+
+	// Git backend used to read repository objects: "cli" or "go-git"
+	GitBackend string
+
+	// Diff source: "auto", "git" (local checkout) or "api" (pull request files API)
+	DiffSource string
+
+	// Glob patterns selecting which ignore files to watch (default: .gitleaksignore)
+	// "**" matches any number of directories, e.g. "**/.gitleaksignore"
+	IgnoreFiles []string
+
+	// Glob patterns selecting gitleaks config files whose allowlists are analyzed
+	// (default: .gitleaks.toml)
+	ConfigFiles []string
+
+	// Policy new and modified entries must follow; the run fails on blocking violations
+	Policy diff.Policy
+
+	// Keep a summary comment tabulating every entry change on the PR (default: true)
+	SummaryComment bool
+
+	// Comment on gitleaks:allow annotations added anywhere in the PR (default: true)
+	InlineAllow bool
+
+	// Check that files and lines referenced by new entries exist at CommitSHA (default: true)
+	ValidateEntries bool
+
+	// Number of files a wildcard entry may match before the comment escalates its warning
+	WildcardThreshold int
+
+	// Quote the referenced line, with secrets redacted, in entry comments (default: true)
+	ShowSnippets bool
+
+	// Run gitleaks over the checkout to check which findings new entries suppress (default: false)
+	Verify bool
+
+	// gitleaks executable used when Verify is set (default: "gitleaks" from PATH)
+	GitleaksPath string
+
+	// Directory in the repository holding comment template overrides
+	// (default: .github/gitleaks-diff-comment)
+	TemplateDir string
+
 	// Enable debug logging
 	Debug bool
 
+	// Print comments to stdout instead of posting them (local CLI only)
+	DryRun bool
+
+	// Format of dry run output: "markdown" or "json" (local CLI only)
+	Format string
+
 	// GitHub Enterprise Server hostname (empty = GitHub.com)
 	GHHost string
 
@@ -49,19 +131,57 @@ type Config struct {
 
 	// Requester is the GitHub username who requested the command
 	Requester string
+
+	// CommandPermission is the lowest permission level allowed to run
+	// commands, e.g. "admin" (empty: write, maintain or admin)
+	CommandPermission string
+
+	// DisabledCommands lists commands the repository does not allow, e.g. "clear"
+	DisabledCommands []string
+
+	// explicit holds the inputs (or flags) set explicitly, which take
+	// precedence over the repository configuration file
+	explicit map[string]bool
 }
 
 // ParseFromEnv parses configuration from environment variables
+// Inputs the workflow leaves unset are taken from the triggering event.
 func ParseFromEnv() (*Config, error) {
+	event, err := ReadEvent()
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
-		GitHubToken: os.Getenv("INPUT_GITHUB-TOKEN"),
-		Repository:  os.Getenv("GITHUB_REPOSITORY"),
-		CommitSHA:   getCommitSHA(),
-		BaseRef:     os.Getenv("GITHUB_BASE_REF"),
-		HeadRef:     os.Getenv("GITHUB_HEAD_REF"),
-		Workspace:   os.Getenv("GITHUB_WORKSPACE"),
-		CommentMode: os.Getenv("INPUT_COMMENT-MODE"),
-		GHHost:      os.Getenv("INPUT_GH-HOST"),
+		GitHubToken:  os.Getenv("INPUT_GITHUB-TOKEN"),
+		Repository:   os.Getenv("GITHUB_REPOSITORY"),
+		CommitSHA:    getCommitSHA(event),
+		BaseSHA:      os.Getenv("INPUT_BASE-SHA"),
+		BaseRef:      os.Getenv("GITHUB_BASE_REF"),
+		HeadRef:      os.Getenv("GITHUB_HEAD_REF"),
+		Workspace:    os.Getenv("GITHUB_WORKSPACE"),
+		Mode:         os.Getenv("INPUT_MODE"),
+		CommentMode:  os.Getenv("INPUT_COMMENT-MODE"),
+		DeliveryMode: os.Getenv("INPUT_DELIVERY-MODE"),
+		OutputMode:   os.Getenv("INPUT_OUTPUT-MODE"),
+		GHHost:       os.Getenv("INPUT_GH-HOST"),
+		GitBackend:   os.Getenv("INPUT_GIT-BACKEND"),
+		DiffSource:   os.Getenv("INPUT_DIFF-SOURCE"),
+		IgnoreFiles:  parseList(os.Getenv("INPUT_IGNORE-FILES")),
+		ConfigFiles:  parseList(os.Getenv("INPUT_CONFIG-FILES")),
+		explicit:     explicitInputs(),
+	}
+
+	// Default to commenting on the pull request diff if not specified
+	if cfg.Mode == "" {
+		cfg.Mode = "diff"
+	}
+
+	// Parse audit issue settings (no issue unless explicitly enabled)
+	cfg.AuditIssue = strings.ToLower(os.Getenv("INPUT_AUDIT-ISSUE")) == "true"
+	cfg.AuditIssueTitle = os.Getenv("INPUT_AUDIT-ISSUE-TITLE")
+	if cfg.AuditIssueTitle == "" {
+		cfg.AuditIssueTitle = "Gitleaks exclusion audit"
 	}
 
 	// Default comment mode to "override" if not specified
@@ -69,6 +189,52 @@ func ParseFromEnv() (*Config, error) {
 		cfg.CommentMode = "override"
 	}
 
+	// Default output mode to review comments if not specified
+	if cfg.OutputMode == "" {
+		cfg.OutputMode = "comments"
+	}
+
+	// Default delivery mode to individual comments if not specified
+	if cfg.DeliveryMode == "" {
+		cfg.DeliveryMode = "comments"
+	}
+
+	// Default git backend to the git CLI if not specified
+	if cfg.GitBackend == "" {
+		cfg.GitBackend = "cli"
+	}
+
+	// Default diff source to automatic selection if not specified
+	if cfg.DiffSource == "" {
+		cfg.DiffSource = "auto"
+	}
+
+	// Default to the root .gitleaksignore if no ignore files are specified
+	if len(cfg.IgnoreFiles) == 0 {
+		cfg.IgnoreFiles = []string{".gitleaksignore"}
+	}
+
+	// Default to the root .gitleaks.toml if no config files are specified
+	if len(cfg.ConfigFiles) == 0 {
+		cfg.ConfigFiles = []string{".gitleaks.toml"}
+	}
+
+	// Parse wildcard threshold (default 25)
+	cfg.WildcardThreshold = 25
+	if thresholdStr := os.Getenv("INPUT_WILDCARD-THRESHOLD"); thresholdStr != "" {
+		threshold, err := strconv.Atoi(thresholdStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid wildcard threshold: %w", err)
+		}
+		cfg.WildcardThreshold = threshold
+	}
+
+	// Template overrides are read from the base commit (default directory if unset)
+	cfg.TemplateDir = strings.Trim(strings.TrimSpace(os.Getenv("INPUT_TEMPLATE-DIR")), "/")
+	if cfg.TemplateDir == "" {
+		cfg.TemplateDir = ".github/gitleaks-diff-comment"
+	}
+
 	// Parse PR number
 	prNumStr := os.Getenv("INPUT_PR-NUMBER")
 	if prNumStr != "" {
@@ -79,10 +245,68 @@ func ParseFromEnv() (*Config, error) {
 		cfg.PRNumber = prNum
 	}
 
+	// Parse GitHub App credentials (optional, replace the token)
+	cfg.AppPrivateKey = os.Getenv("INPUT_APP-PRIVATE-KEY")
+	if appIDStr := os.Getenv("INPUT_APP-ID"); appIDStr != "" {
+		appID, err := strconv.ParseInt(appIDStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid app ID: %w", err)
+		}
+		cfg.AppID = appID
+	}
+	if installationStr := os.Getenv("INPUT_APP-INSTALLATION-ID"); installationStr != "" {
+		installationID, err := strconv.ParseInt(installationStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid app installation ID: %w", err)
+		}
+		cfg.AppInstallationID = installationID
+	}
+
 	// Parse debug flag
 	debugStr := os.Getenv("INPUT_DEBUG")
 	cfg.Debug = strings.ToLower(debugStr) == "true"
 
+	// Parse policy rules (all disabled unless set)
+	cfg.Policy.ForbidWildcards = strings.ToLower(os.Getenv("INPUT_POLICY-FORBID-WILDCARDS")) == "true"
+	cfg.Policy.RequireLineNumber = strings.ToLower(os.Getenv("INPUT_POLICY-REQUIRE-LINE-NUMBER")) == "true"
+	cfg.Policy.ForbiddenPaths = parseList(os.Getenv("INPUT_POLICY-FORBIDDEN-PATHS"))
+	cfg.Policy.RequireReason = strings.ToLower(os.Getenv("INPUT_POLICY-REQUIRE-REASON")) == "true"
+	cfg.Policy.RequireTicket = strings.ToLower(os.Getenv("INPUT_POLICY-REQUIRE-TICKET")) == "true"
+	cfg.Policy.WarnOnly = parseList(os.Getenv("INPUT_POLICY-WARN-ONLY"))
+	if maxStr := os.Getenv("INPUT_POLICY-MAX-NEW-ENTRIES"); maxStr != "" {
+		maxEntries, err := strconv.Atoi(maxStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy max new entries: %w", err)
+		}
+		cfg.Policy.MaxNewEntries = maxEntries
+	}
+	if daysStr := os.Getenv("INPUT_POLICY-MAX-EXPIRY-DAYS"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy max expiry days: %w", err)
+		}
+		cfg.Policy.MaxExpiryDays = days
+	}
+
+	// Parse summary-comment flag (enabled unless explicitly disabled)
+	cfg.SummaryComment = strings.ToLower(os.Getenv("INPUT_SUMMARY-COMMENT")) != "false"
+
+	// Parse inline-allow flag (enabled unless explicitly disabled)
+	cfg.InlineAllow = strings.ToLower(os.Getenv("INPUT_INLINE-ALLOW")) != "false"
+
+	// Parse validate-entries flag (enabled unless explicitly disabled)
+	cfg.ValidateEntries = strings.ToLower(os.Getenv("INPUT_VALIDATE-ENTRIES")) != "false"
+
+	// Parse show-snippets flag (enabled unless explicitly disabled)
+	cfg.ShowSnippets = strings.ToLower(os.Getenv("INPUT_SHOW-SNIPPETS")) != "false"
+
+	// Parse verify flag (disabled unless explicitly enabled)
+	cfg.Verify = strings.ToLower(os.Getenv("INPUT_VERIFY")) == "true"
+	cfg.GitleaksPath = os.Getenv("INPUT_GITLEAKS-PATH")
+	if cfg.GitleaksPath == "" {
+		cfg.GitleaksPath = "gitleaks"
+	}
+
 	// Parse command-related fields (optional, for command mode)
 	cfg.Command = os.Getenv("INPUT_COMMAND")
 	cfg.Requester = os.Getenv("INPUT_REQUESTER")
@@ -97,6 +321,9 @@ func ParseFromEnv() (*Config, error) {
 		cfg.CommentID = commentID
 	}
 
+	// Fill the pull request, commits and requester from the event payload
+	cfg.applyEvent(event)
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -107,21 +334,42 @@ func ParseFromEnv() (*Config, error) {
 
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
-	if c.GitHubToken == "" {
+	// Dry runs only read the repository, which needs no token locally
+	if c.GitHubToken == "" && !c.UsesApp() && !c.DryRun {
 		return errors.New("GitHub token is required (INPUT_GITHUB-TOKEN)\n" +
 			"  → Action: Set 'github-token' input in your workflow file\n" +
 			"  → Example: github-token: ${{ secrets.GITHUB_TOKEN }}\n" +
-			"  → Required scopes: repo (read), pull_requests (write)")
+			"  → Required scopes: repo (read), pull_requests (write)\n" +
+			"  → Or authenticate as a GitHub App with 'app-id' and 'app-private-key'")
+	}
+	if (c.AppID != 0) != (c.AppPrivateKey != "") {
+		return errors.New("GitHub App authentication needs both app-id and app-private-key\n" +
+			"  → Action: Set both inputs, or neither to use 'github-token'\n" +
+			"  → Example: app-id: ${{ vars.APP_ID }}\n" +
+			"             app-private-key: ${{ secrets.APP_PRIVATE_KEY }}")
 	}
-	if c.PRNumber <= 0 {
+	if c.AppID < 0 || c.AppInstallationID < 0 {
+		return errors.New("app-id and app-installation-id must be positive\n" +
+			"  → Action: Use the IDs shown in the GitHub App's settings")
+	}
+	if c.Mode != "" && c.Mode != "diff" && c.Mode != "audit" {
+		return fmt.Errorf("mode must be 'diff' or 'audit', got: %s\n"+
+			"  → Action: Set 'mode' input to either 'diff' or 'audit'\n"+
+			"  → Example: mode: audit", c.Mode)
+	}
+	// Audits read the whole ignore file and need no pull request
+	if !c.IsAuditMode() && !c.DryRun && c.PRNumber <= 0 {
 		return errors.New("PR number must be positive (INPUT_PR-NUMBER)\n" +
 			"  → Action: Set 'pr-number' input in your workflow file\n" +
-			"  → Example: pr-number: ${{ github.event.pull_request.number }}")
+			"  → Example: pr-number: ${{ github.event.pull_request.number }}\n" +
+			"  → Or run on a pull_request, pull_request_target, issue_comment or workflow_run event\n" +
+			"  → Or report on every entry without a pull request: mode: audit")
 	}
 	if c.Repository == "" {
 		return errors.New("repository is required (GITHUB_REPOSITORY)\n" +
 			"  → Action: This is automatically set by GitHub Actions\n" +
-			"  → Ensure the action is running in a GitHub Actions workflow")
+			"  → Ensure the action is running in a GitHub Actions workflow\n" +
+			"  → Outside GitHub Actions: pass --repo owner/repo")
 	}
 	if !strings.Contains(c.Repository, "/") {
 		return fmt.Errorf("repository must be in format owner/repo, got: %s\n"+
@@ -134,11 +382,65 @@ func (c *Config) Validate() error {
 			"  → Action: This is automatically set by GitHub Actions\n" +
 			"  → Ensure the action is running in a GitHub Actions workflow")
 	}
+	if c.Format != "" && c.Format != "markdown" && c.Format != "json" {
+		return fmt.Errorf("format must be 'markdown' or 'json', got: %s\n"+
+			"  → Action: Pass --format markdown or --format json\n"+
+			"  → Example: gitleaks-diff-comment diff --base main --format json", c.Format)
+	}
 	if c.CommentMode != "override" && c.CommentMode != "append" {
 		return fmt.Errorf("comment-mode must be 'override' or 'append', got: %s\n"+
 			"  → Action: Set 'comment-mode' input to either 'override' or 'append'\n"+
 			"  → Example: comment-mode: override", c.CommentMode)
 	}
+	if c.OutputMode != "" && c.OutputMode != "comments" && c.OutputMode != "check" && c.OutputMode != "both" {
+		return fmt.Errorf("output-mode must be 'comments', 'check' or 'both', got: %s\n"+
+			"  → Action: Set 'output-mode' input to 'comments', 'check' or 'both'\n"+
+			"  → Example: output-mode: check", c.OutputMode)
+	}
+	if c.DeliveryMode != "" && c.DeliveryMode != "comments" && c.DeliveryMode != "review" {
+		return fmt.Errorf("delivery-mode must be 'comments' or 'review', got: %s\n"+
+			"  → Action: Set 'delivery-mode' input to either 'comments' or 'review'\n"+
+			"  → Example: delivery-mode: review", c.DeliveryMode)
+	}
+	if c.GitBackend != "" && c.GitBackend != "cli" && c.GitBackend != "go-git" {
+		return fmt.Errorf("git-backend must be 'cli' or 'go-git', got: %s\n"+
+			"  → Action: Set 'git-backend' input to either 'cli' or 'go-git'\n"+
+			"  → Example: git-backend: go-git", c.GitBackend)
+	}
+	if c.DiffSource != "" && c.DiffSource != "auto" && c.DiffSource != "git" && c.DiffSource != "api" {
+		return fmt.Errorf("diff-source must be 'auto', 'git' or 'api', got: %s\n"+
+			"  → Action: Set 'diff-source' input to 'auto', 'git' or 'api'\n"+
+			"  → Example: diff-source: api", c.DiffSource)
+	}
+	for _, pattern := range c.IgnoreFiles {
+		if err := diff.ValidateGlob(pattern); err != nil {
+			return fmt.Errorf("invalid ignore-files pattern: %w\n"+
+				"  → Action: Use gitignore-style globs, one per line or comma-separated\n"+
+				"  → Example: ignore-files: .gitleaksignore,**/.gitleaksignore", err)
+		}
+	}
+	if c.WildcardThreshold < 0 {
+		return fmt.Errorf("wildcard-threshold must not be negative, got: %d\n"+
+			"  → Action: Set 'wildcard-threshold' to the number of files a pattern may match\n"+
+			"  → Example: wildcard-threshold: 25", c.WildcardThreshold)
+	}
+	for _, pattern := range c.ConfigFiles {
+		if err := diff.ValidateGlob(pattern); err != nil {
+			return fmt.Errorf("invalid config-files pattern: %w\n"+
+				"  → Action: Use gitignore-style globs, one per line or comma-separated\n"+
+				"  → Example: config-files: .gitleaks.toml", err)
+		}
+	}
+	if err := c.Policy.Validate(); err != nil {
+		return fmt.Errorf("invalid policy: %w\n"+
+			"  → Action: Check the 'policy-*' inputs in your workflow file\n"+
+			"  → Example: policy-forbidden-paths: src/,config/prod/**", err)
+	}
+	if c.Verify && c.DiffSource == diff.SourceAPI {
+		return errors.New("verify requires a local checkout, but diff-source is 'api'\n" +
+			"  → Action: Check out the PR head with actions/checkout and set 'diff-source' to 'auto' or 'git'\n" +
+			"  → Or disable verification: verify: false")
+	}
 
 	// Validate GHHost format (GitHub Enterprise Server hostname)
 	if c.GHHost != "" {
@@ -196,19 +498,59 @@ func (c *Config) Repo() string {
 	return parts[1]
 }
 
+// IsAuditMode returns true if the action reports on every entry instead of a pull request
+func (c *Config) IsAuditMode() bool {
+	return c.Mode == "audit"
+}
+
 // IsCommandMode returns true if the action is running in command mode
 func (c *Config) IsCommandMode() bool {
 	return c.Command != ""
 }
 
+// UsesApp returns true if the action authenticates as a GitHub App installation
+func (c *Config) UsesApp() bool {
+	return c.AppID != 0 && c.AppPrivateKey != ""
+}
+
+// parseList splits a comma- or newline-separated input into trimmed, non-empty items
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// explicitInputs returns the names of the inputs the workflow set, e.g.
+// "comment-mode"; inputs left empty fall back to their defaults
+func explicitInputs() map[string]bool {
+	inputs := make(map[string]bool)
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, "INPUT_") && strings.TrimSpace(value) != "" {
+			inputs[strings.ToLower(strings.TrimPrefix(name, "INPUT_"))] = true
+		}
+	}
+	return inputs
+}
+
 // getCommitSHA gets the commit SHA to use for PR comments
-// Priority: INPUT_COMMIT-SHA > git rev-parse HEAD > GITHUB_SHA
-func getCommitSHA() string {
+// Priority: INPUT_COMMIT-SHA > event head SHA > git rev-parse HEAD > GITHUB_SHA
+func getCommitSHA(event *Event) string {
 	// First, check if user provided commit-sha input
 	if commitSHA := os.Getenv("INPUT_COMMIT-SHA"); commitSHA != "" {
 		return commitSHA
 	}
 
+	// The pull request head from the event payload, unlike the merge commit
+	// that pull_request workflows check out by default
+	if event.HeadSHA != "" {
+		return event.HeadSHA
+	}
+
 	// Try to get actual HEAD commit from git
 	// This is the most reliable method in PR context
 	cmd := exec.Command("git", "rev-parse", "HEAD")
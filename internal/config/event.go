@@ -0,0 +1,160 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Event holds what the action needs from the payload of the event that
+// triggered the workflow (GITHUB_EVENT_PATH). Fields the event does not carry
+// are left empty.
+type Event struct {
+	// Name is the event name, e.g. "pull_request" (GITHUB_EVENT_NAME)
+	Name string
+
+	// PRNumber is the pull request the event belongs to
+	PRNumber int
+
+	// HeadSHA is the pull request head commit
+	HeadSHA string
+
+	// BaseSHA is the tip of the base branch when the event fired, not the
+	// merge base the pull request diff starts at
+	BaseSHA string
+
+	// BaseRef is the branch the pull request targets
+	BaseRef string
+
+	// HeadRef is the pull request branch
+	HeadRef string
+
+	// CommentID is the comment that triggered an issue_comment event
+	CommentID int64
+
+	// Commenter is the login of the comment's author
+	Commenter string
+}
+
+// eventPullRequest is a pull request as it appears in event payloads
+type eventPullRequest struct {
+	Number int `json:"number"`
+	Head   struct {
+		SHA string `json:"sha"`
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		SHA string `json:"sha"`
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// eventPayload is the union of the payload fields read from supported events
+type eventPayload struct {
+	PullRequest *eventPullRequest `json:"pull_request"`
+
+	Issue *struct {
+		Number      int       `json:"number"`
+		PullRequest *struct{} `json:"pull_request"`
+	} `json:"issue"`
+
+	Comment *struct {
+		ID   int64 `json:"id"`
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"comment"`
+
+	WorkflowRun *struct {
+		HeadSHA      string             `json:"head_sha"`
+		HeadBranch   string             `json:"head_branch"`
+		PullRequests []eventPullRequest `json:"pull_requests"`
+	} `json:"workflow_run"`
+}
+
+// ReadEvent reads the payload of the triggering event from GITHUB_EVENT_PATH
+// Returns an empty Event if the variable is unset, e.g. outside GitHub Actions.
+func ReadEvent() (*Event, error) {
+	name := os.Getenv("GITHUB_EVENT_NAME")
+	path := os.Getenv("GITHUB_EVENT_PATH")
+	if path == "" {
+		return &Event{Name: name}, nil
+	}
+
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event payload (GITHUB_EVENT_PATH): %w", err)
+	}
+	return ParseEvent(name, payload)
+}
+
+// ParseEvent extracts the pull request, commits, branches and comment from an
+// event payload. pull_request, pull_request_target, issue_comment (on a pull
+// request) and workflow_run events are understood; other events only set Name.
+func ParseEvent(name string, payload []byte) (*Event, error) {
+	event := &Event{Name: name}
+
+	var p eventPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s event payload: %w", name, err)
+	}
+
+	switch name {
+	case "pull_request", "pull_request_target":
+		if p.PullRequest != nil {
+			event.setPullRequest(p.PullRequest)
+		}
+	case "issue_comment":
+		// Comments on plain issues have no pull request to act on
+		if p.Issue != nil && p.Issue.PullRequest != nil {
+			event.PRNumber = p.Issue.Number
+		}
+		if p.Comment != nil {
+			event.CommentID = p.Comment.ID
+			event.Commenter = p.Comment.User.Login
+		}
+	case "workflow_run":
+		if run := p.WorkflowRun; run != nil {
+			// pull_requests is empty for runs from forks; the head is still known
+			if len(run.PullRequests) > 0 {
+				event.setPullRequest(&run.PullRequests[0])
+			}
+			event.HeadSHA = run.HeadSHA
+			event.HeadRef = run.HeadBranch
+		}
+	}
+
+	return event, nil
+}
+
+// applyEvent fills the fields inputs left unset from the triggering event
+// CommitSHA is resolved separately, by getCommitSHA.
+func (c *Config) applyEvent(event *Event) {
+	if c.PRNumber == 0 {
+		c.PRNumber = event.PRNumber
+	}
+	if c.BaseSHA == "" {
+		c.BaseSHA = event.BaseSHA
+	}
+	if c.BaseRef == "" {
+		c.BaseRef = event.BaseRef
+	}
+	if c.HeadRef == "" {
+		c.HeadRef = event.HeadRef
+	}
+	if c.CommentID == 0 {
+		c.CommentID = event.CommentID
+	}
+	if c.Requester == "" {
+		c.Requester = event.Commenter
+	}
+}
+
+// setPullRequest copies the number, commits and branches of pr
+func (e *Event) setPullRequest(pr *eventPullRequest) {
+	e.PRNumber = pr.Number
+	e.HeadSHA = pr.Head.SHA
+	e.HeadRef = pr.Head.Ref
+	e.BaseSHA = pr.Base.SHA
+	e.BaseRef = pr.Base.Ref
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const pullRequestPayload = `{
+  "action": "synchronize",
+  "number": 42,
+  "pull_request": {
+    "number": 42,
+    "head": {"sha": "headsha", "ref": "feature/ignore"},
+    "base": {"sha": "basesha", "ref": "main"}
+  }
+}`
+
+// TestParseEvent tests extracting the pull request and comment from event payloads
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		payload string
+		want    Event
+	}{
+		{
+			name:    "pull_request",
+			event:   "pull_request",
+			payload: pullRequestPayload,
+			want:    Event{PRNumber: 42, HeadSHA: "headsha", HeadRef: "feature/ignore", BaseSHA: "basesha", BaseRef: "main"},
+		},
+		{
+			name:    "pull_request_target",
+			event:   "pull_request_target",
+			payload: pullRequestPayload,
+			want:    Event{PRNumber: 42, HeadSHA: "headsha", HeadRef: "feature/ignore", BaseSHA: "basesha", BaseRef: "main"},
+		},
+		{
+			name:  "issue_comment on a pull request",
+			event: "issue_comment",
+			payload: `{
+				"issue": {"number": 7, "pull_request": {"url": "https://api.github.com/repos/o/r/pulls/7"}},
+				"comment": {"id": 1234567890, "body": "@github-actions /clear", "user": {"login": "octocat"}}
+			}`,
+			want: Event{PRNumber: 7, CommentID: 1234567890, Commenter: "octocat"},
+		},
+		{
+			name:  "issue_comment on an issue",
+			event: "issue_comment",
+			payload: `{
+				"issue": {"number": 7},
+				"comment": {"id": 1, "user": {"login": "octocat"}}
+			}`,
+			want: Event{CommentID: 1, Commenter: "octocat"},
+		},
+		{
+			name:  "workflow_run",
+			event: "workflow_run",
+			payload: `{
+				"workflow_run": {
+					"head_sha": "runhead",
+					"head_branch": "feature/ignore",
+					"pull_requests": [{"number": 9, "head": {"sha": "prhead", "ref": "feature/ignore"}, "base": {"sha": "basesha", "ref": "main"}}]
+				}
+			}`,
+			want: Event{PRNumber: 9, HeadSHA: "runhead", HeadRef: "feature/ignore", BaseSHA: "basesha", BaseRef: "main"},
+		},
+		{
+			name:    "workflow_run from a fork",
+			event:   "workflow_run",
+			payload: `{"workflow_run": {"head_sha": "runhead", "head_branch": "patch-1", "pull_requests": []}}`,
+			want:    Event{HeadSHA: "runhead", HeadRef: "patch-1"},
+		},
+		{
+			name:    "unsupported event",
+			event:   "push",
+			payload: `{"after": "abc123", "pull_request": {"number": 1}}`,
+			want:    Event{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEvent(tt.event, []byte(tt.payload))
+			if err != nil {
+				t.Fatalf("ParseEvent() unexpected error: %v", err)
+			}
+			tt.want.Name = tt.event
+			if *got != tt.want {
+				t.Errorf("ParseEvent() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseEvent("pull_request", []byte("{")); err == nil {
+		t.Error("ParseEvent() with malformed payload expected error, got nil")
+	}
+}
+
+// TestParseFromEnv_Event tests that the event fills inputs the workflow left unset
+func TestParseFromEnv_Event(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(path, []byte(pullRequestPayload), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GITHUB_EVENT_NAME", "pull_request")
+	t.Setenv("GITHUB_EVENT_PATH", path)
+	t.Setenv("INPUT_GITHUB-TOKEN", "test-token")
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+	t.Setenv("GITHUB_BASE_REF", "")
+	t.Setenv("INPUT_COMMIT-SHA", "")
+	t.Setenv("INPUT_BASE-SHA", "")
+	t.Setenv("INPUT_PR-NUMBER", "")
+
+	cfg, err := ParseFromEnv()
+	if err != nil {
+		t.Fatalf("ParseFromEnv() unexpected error: %v", err)
+	}
+	if cfg.PRNumber != 42 || cfg.CommitSHA != "headsha" || cfg.BaseSHA != "basesha" || cfg.BaseRef != "main" {
+		t.Errorf("PRNumber = %d, CommitSHA = %q, BaseSHA = %q, BaseRef = %q, want 42, headsha, basesha and main",
+			cfg.PRNumber, cfg.CommitSHA, cfg.BaseSHA, cfg.BaseRef)
+	}
+
+	// Explicit inputs win over the event
+	t.Setenv("INPUT_PR-NUMBER", "43")
+	t.Setenv("INPUT_COMMIT-SHA", "inputsha")
+	t.Setenv("INPUT_BASE-SHA", "inputbase")
+
+	cfg, err = ParseFromEnv()
+	if err != nil {
+		t.Fatalf("ParseFromEnv() unexpected error: %v", err)
+	}
+	if cfg.PRNumber != 43 || cfg.CommitSHA != "inputsha" || cfg.BaseSHA != "inputbase" {
+		t.Errorf("PRNumber = %d, CommitSHA = %q, BaseSHA = %q, want 43, inputsha and inputbase",
+			cfg.PRNumber, cfg.CommitSHA, cfg.BaseSHA)
+	}
+
+	t.Setenv("GITHUB_EVENT_PATH", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := ParseFromEnv(); err == nil {
+		t.Error("ParseFromEnv() with missing event payload expected error, got nil")
+	}
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// CLI subcommands
+const (
+	CommandDiff  = "diff"
+	CommandAudit = "audit"
+	CommandClear = "clear"
+)
+
+// Output formats for rendering comments to stdout
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+)
+
+// ParseArgs parses the flags of a command line subcommand ("diff", "audit" or
+// "clear") into a Config. Flags are named after the action inputs, and the
+// token and repository fall back to GITHUB_TOKEN and GITHUB_REPOSITORY, then
+// to the origin remote. Usage is written to output; -h returns flag.ErrHelp.
+//
+// diff renders the comments to stdout instead of posting them unless a pull
+// request is given, so it needs no token. audit does the same unless
+// --audit-issue is set.
+func ParseArgs(command string, args []string, output io.Writer) (*Config, error) {
+	cfg := &Config{}
+	var head, appKeyFile string
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	fs.StringVar(&cfg.GitHubToken, "token", os.Getenv("GITHUB_TOKEN"), "GitHub token (default $GITHUB_TOKEN)")
+	fs.StringVar(&cfg.Repository, "repo", "", "repository as owner/repo (default $GITHUB_REPOSITORY or the origin remote)")
+	fs.StringVar(&cfg.GHHost, "gh-host", "", "GitHub Enterprise Server hostname")
+	fs.Int64Var(&cfg.AppID, "app-id", 0, "GitHub App ID, to authenticate as the app instead of with a token")
+	fs.StringVar(&appKeyFile, "app-private-key", "", "path to the GitHub App's PEM private key")
+	fs.Int64Var(&cfg.AppInstallationID, "app-installation-id", 0, "GitHub App installation (default: the installation on --repo)")
+	fs.BoolVar(&cfg.Debug, "debug", false, "enable debug logging")
+
+	switch command {
+	case CommandDiff, CommandAudit:
+		fs.StringVar(&head, "head", "HEAD", "commit to comment on (diff) or audit")
+		fs.StringVar(&cfg.GitBackend, "git-backend", "cli", "how git objects are read: cli or go-git")
+		fs.StringVar(&cfg.DiffSource, "diff-source", "auto", "where files are read from: auto, git or api")
+		fs.Var((*listFlag)(&cfg.IgnoreFiles), "ignore-files", "comma-separated globs of ignore files (default .gitleaksignore)")
+		fs.StringVar(&cfg.TemplateDir, "template-dir", ".github/gitleaks-diff-comment", "directory of comment template overrides")
+		fs.BoolVar(&cfg.ValidateEntries, "validate-entries", true, "check that referenced files and lines exist")
+		fs.IntVar(&cfg.WildcardThreshold, "wildcard-threshold", 25, "files a wildcard may match before the warning escalates (0 disables)")
+		fs.BoolVar(&cfg.Verify, "verify", false, "compare entries with a gitleaks scan of the working tree")
+		fs.StringVar(&cfg.GitleaksPath, "gitleaks-path", "gitleaks", "gitleaks executable used by --verify")
+
+		if command == CommandAudit {
+			cfg.Mode = "audit"
+			fs.BoolVar(&cfg.AuditIssue, "audit-issue", false, "keep a tracking issue up to date with the report (needs a token)")
+			fs.StringVar(&cfg.AuditIssueTitle, "audit-issue-title", "Gitleaks exclusion audit", "title of the audit tracking issue")
+		} else {
+			cfg.Mode = "diff"
+			fs.StringVar(&cfg.BaseSHA, "base", "", "branch or commit the changes are based on (required); the diff starts at its merge base with --head, like a pull request's")
+			fs.IntVar(&cfg.PRNumber, "pr", 0, "pull request to post comments on; without it comments are only printed")
+			fs.BoolVar(&cfg.DryRun, "dry-run", false, "print the comments instead of posting them, even with --pr")
+			fs.StringVar(&cfg.Format, "format", FormatMarkdown, "output of a dry run: markdown or json")
+			fs.Var((*listFlag)(&cfg.ConfigFiles), "config-files", "comma-separated globs of gitleaks config files (default .gitleaks.toml)")
+			fs.StringVar(&cfg.CommentMode, "comment-mode", "override", "override or append")
+			fs.StringVar(&cfg.OutputMode, "output-mode", "comments", "comments, check or both")
+			fs.StringVar(&cfg.DeliveryMode, "delivery-mode", "comments", "comments or review")
+			fs.BoolVar(&cfg.SummaryComment, "summary-comment", true, "keep a summary comment tabulating every change")
+			fs.BoolVar(&cfg.InlineAllow, "inline-allow", true, "comment on added gitleaks:allow annotations")
+			fs.BoolVar(&cfg.ShowSnippets, "show-snippets", true, "quote the excluded line with secrets redacted")
+			fs.BoolVar(&cfg.Policy.ForbidWildcards, "policy-forbid-wildcards", false, "fail on wildcard entries")
+			fs.BoolVar(&cfg.Policy.RequireLineNumber, "policy-require-line-number", false, "fail on entries excluding a whole file")
+			fs.Var((*listFlag)(&cfg.Policy.ForbiddenPaths), "policy-forbidden-paths", "comma-separated globs of files that must not be excluded")
+			fs.IntVar(&cfg.Policy.MaxNewEntries, "policy-max-new-entries", 0, "maximum entries a change may add (0 disables)")
+			fs.BoolVar(&cfg.Policy.RequireReason, "policy-require-reason", false, "fail on entries without a reason")
+			fs.BoolVar(&cfg.Policy.RequireTicket, "policy-require-ticket", false, "fail on entries without a ticket")
+			fs.IntVar(&cfg.Policy.MaxExpiryDays, "policy-max-expiry-days", 0, "days ahead an entry may expire (0 disables)")
+			fs.Var((*listFlag)(&cfg.Policy.WarnOnly), "policy-warn-only", "comma-separated policy rules that only warn")
+		}
+	case CommandClear:
+		cfg.Command = CommandClear
+		fs.IntVar(&cfg.PRNumber, "pr", 0, "pull request to clear bot comments from (required)")
+		fs.StringVar(&cfg.Requester, "requester", os.Getenv("GITHUB_ACTOR"), "user whose permissions are checked (default $GITHUB_ACTOR)")
+	default:
+		return nil, fmt.Errorf("unknown command %q (available: %s, %s, %s, %s)", command, CommandDiff, CommandAudit, CommandClear, CommandServe)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	cfg.explicit = make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		cfg.explicit[f.Name] = true
+	})
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q\n"+
+			"  → Run '%s -h' for the available flags", fs.Arg(0), command)
+	}
+
+	// Without a pull request or tracking issue there is nothing to post
+	switch command {
+	case CommandDiff:
+		cfg.DryRun = cfg.DryRun || cfg.PRNumber == 0
+		cfg.CommitSHA = resolveRevision(head)
+		if cfg.BaseSHA != "" {
+			cfg.BaseSHA = mergeBase(cfg.BaseSHA, cfg.CommitSHA)
+		}
+	case CommandAudit:
+		cfg.DryRun = !cfg.AuditIssue
+		cfg.CommitSHA = resolveRevision(head)
+	}
+
+	if cfg.Repository == "" {
+		cfg.Repository = os.Getenv("GITHUB_REPOSITORY")
+	}
+	if cfg.Repository == "" {
+		cfg.Repository = repositoryFromRemote()
+	}
+	if cfg.CommentMode == "" {
+		cfg.CommentMode = "override"
+	}
+	if len(cfg.IgnoreFiles) == 0 {
+		cfg.IgnoreFiles = []string{".gitleaksignore"}
+	}
+	if len(cfg.ConfigFiles) == 0 {
+		cfg.ConfigFiles = []string{".gitleaks.toml"}
+	}
+	cfg.TemplateDir = strings.Trim(strings.TrimSpace(cfg.TemplateDir), "/")
+
+	if appKeyFile != "" {
+		key, err := os.ReadFile(appKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --app-private-key: %w\n"+
+				"  → Action: Pass the path of the .pem file downloaded from the GitHub App's settings", err)
+		}
+		cfg.AppPrivateKey = string(key)
+	}
+
+	if command == CommandDiff && cfg.BaseSHA == "" {
+		return nil, fmt.Errorf("--base is required\n" +
+			"  → Action: Pass the branch or commit to compare against\n" +
+			"  → Example: gitleaks-diff-comment diff --base main --head HEAD")
+	}
+
+	if command == CommandClear && cfg.Requester == "" {
+		return nil, fmt.Errorf("--requester is required\n" +
+			"  → Action: Pass the GitHub user whose permissions allow clearing comments\n" +
+			"  → Example: gitleaks-diff-comment clear --pr 42 --requester octocat")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// listFlag is a comma-separated flag value; repeating the flag adds to the list
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, parseList(value)...)
+	return nil
+}
+
+// resolveRevision resolves rev (e.g. "HEAD" or a branch) to a full commit SHA
+// in the current repository, or returns it unchanged if git cannot
+func resolveRevision(rev string) string {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if output, err := cmd.Output(); err == nil {
+		if sha := strings.TrimSpace(string(output)); sha != "" {
+			return sha
+		}
+	}
+	return rev
+}
+
+// mergeBase returns the merge base of base and head in the current
+// repository, so the preview shows what the pull request diff will, not
+// changes base gained after head branched off. Returns base unchanged if git
+// cannot resolve it.
+func mergeBase(base, head string) string {
+	output, err := exec.Command("git", "merge-base", base, head).Output()
+	if err != nil {
+		return base
+	}
+	if sha := strings.TrimSpace(string(output)); sha != "" {
+		return sha
+	}
+	return base
+}
+
+// remoteRegex extracts owner/repo from an HTTPS or SSH remote URL
+var remoteRegex = regexp.MustCompile(`[:/]([^/:]+/[^/]+?)(?:\.git)?/?$`)
+
+// repositoryFromRemote returns owner/repo of the origin remote, or empty
+func repositoryFromRemote() string {
+	output, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return ""
+	}
+	return parseRemoteURL(strings.TrimSpace(string(output)))
+}
+
+// parseRemoteURL returns owner/repo from a remote URL such as
+// git@github.com:owner/repo.git or https://github.com/owner/repo
+func parseRemoteURL(url string) string {
+	if m := remoteRegex.FindStringSubmatch(url); m != nil {
+		return m[1]
+	}
+	return ""
+}
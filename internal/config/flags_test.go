@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseArgs_Diff tests that diff is a dry run unless a pull request is given
+func TestParseArgs_Diff(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITHUB_REPOSITORY", "")
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantDryRun bool
+	}{
+		{
+			name:       "no pull request",
+			args:       []string{"--base", "main", "--repo", "owner/repo"},
+			wantDryRun: true,
+		},
+		{
+			name:       "pull request",
+			args:       []string{"--base", "main", "--repo", "owner/repo", "--pr", "42", "--token", "test-token"},
+			wantDryRun: false,
+		},
+		{
+			name:       "pull request with dry run",
+			args:       []string{"--base", "main", "--repo", "owner/repo", "--pr", "42", "--dry-run"},
+			wantDryRun: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseArgs(CommandDiff, tt.args, io.Discard)
+			if err != nil {
+				t.Fatalf("ParseArgs() unexpected error: %v", err)
+			}
+			if cfg.DryRun != tt.wantDryRun {
+				t.Errorf("DryRun = %v, want %v", cfg.DryRun, tt.wantDryRun)
+			}
+			if cfg.CommitSHA == "" || cfg.Mode != "diff" || cfg.Format != FormatMarkdown {
+				t.Errorf("CommitSHA = %q, Mode = %q, Format = %q, want a commit, diff and markdown",
+					cfg.CommitSHA, cfg.Mode, cfg.Format)
+			}
+		})
+	}
+}
+
+// TestParseArgs_Clear tests that clear runs outside Actions once a requester is given
+func TestParseArgs_Clear(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "")
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+
+	cfg, err := ParseArgs(CommandClear, []string{"--pr", "42", "--requester", "octocat"}, io.Discard)
+	if err != nil {
+		t.Fatalf("ParseArgs() unexpected error: %v", err)
+	}
+	if cfg.PRNumber != 42 || cfg.Requester != "octocat" {
+		t.Errorf("PRNumber = %d, Requester = %q, want 42 and octocat", cfg.PRNumber, cfg.Requester)
+	}
+}
+
+// TestParseArgs_Flags tests list flags and defaults shared with the action inputs
+func TestParseArgs_Flags(t *testing.T) {
+	args := []string{
+		"--base", "main",
+		"--repo", "owner/repo",
+		"--ignore-files", ".gitleaksignore, **/.gitleaksignore",
+		"--policy-forbidden-paths", "src/",
+		"--policy-forbidden-paths", "config/prod/**",
+		"--format", "json",
+	}
+
+	cfg, err := ParseArgs(CommandDiff, args, io.Discard)
+	if err != nil {
+		t.Fatalf("ParseArgs() unexpected error: %v", err)
+	}
+
+	if want := []string{".gitleaksignore", "**/.gitleaksignore"}; !reflect.DeepEqual(cfg.IgnoreFiles, want) {
+		t.Errorf("IgnoreFiles = %v, want %v", cfg.IgnoreFiles, want)
+	}
+	if want := []string{"src/", "config/prod/**"}; !reflect.DeepEqual(cfg.Policy.ForbiddenPaths, want) {
+		t.Errorf("Policy.ForbiddenPaths = %v, want %v", cfg.Policy.ForbiddenPaths, want)
+	}
+	if want := []string{".gitleaks.toml"}; !reflect.DeepEqual(cfg.ConfigFiles, want) {
+		t.Errorf("ConfigFiles = %v, want %v", cfg.ConfigFiles, want)
+	}
+	if cfg.Format != FormatJSON || cfg.CommentMode != "override" || !cfg.SummaryComment {
+		t.Errorf("Format = %q, CommentMode = %q, SummaryComment = %v, want json, override and true",
+			cfg.Format, cfg.CommentMode, cfg.SummaryComment)
+	}
+}
+
+// TestParseArgs_Errors tests rejected command lines
+func TestParseArgs_Errors(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITHUB_ACTOR", "")
+
+	tests := []struct {
+		name      string
+		command   string
+		args      []string
+		wantError string
+	}{
+		{
+			name:      "unknown command",
+			command:   "post",
+			wantError: "unknown command",
+		},
+		{
+			name:      "missing base",
+			command:   CommandDiff,
+			args:      []string{"--repo", "owner/repo"},
+			wantError: "--base is required",
+		},
+		{
+			name:      "unknown format",
+			command:   CommandDiff,
+			args:      []string{"--base", "main", "--repo", "owner/repo", "--format", "html"},
+			wantError: "format must be 'markdown' or 'json'",
+		},
+		{
+			name:      "posting without token",
+			command:   CommandDiff,
+			args:      []string{"--base", "main", "--repo", "owner/repo", "--pr", "42"},
+			wantError: "GitHub token is required",
+		},
+		{
+			name:      "audit issue without token",
+			command:   CommandAudit,
+			args:      []string{"--repo", "owner/repo", "--audit-issue"},
+			wantError: "GitHub token is required",
+		},
+		{
+			name:      "clear without requester",
+			command:   CommandClear,
+			args:      []string{"--repo", "owner/repo", "--pr", "42", "--token", "test-token"},
+			wantError: "--requester is required",
+		},
+		{
+			name:      "positional argument",
+			command:   CommandDiff,
+			args:      []string{"--base", "main", "extra"},
+			wantError: "unexpected argument",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseArgs(tt.command, tt.args, io.Discard)
+			if err == nil {
+				t.Fatalf("ParseArgs() expected error containing %q, got nil", tt.wantError)
+			}
+			if !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("ParseArgs() error = %q, want error containing %q", err.Error(), tt.wantError)
+			}
+		})
+	}
+
+	if _, err := ParseArgs(CommandDiff, []string{"-h"}, io.Discard); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("ParseArgs(-h) error = %v, want flag.ErrHelp", err)
+	}
+}
+
+// TestParseArgs_DivergedBase tests that diff compares --head with its merge
+// base, not with commits --base gained after the branch point
+func TestParseArgs_DivergedBase(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GITHUB_REPOSITORY", "")
+	t.Chdir(t.TempDir())
+
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(content string) string {
+		t.Helper()
+		if err := os.WriteFile(".gitleaksignore", []byte(content), 0o644); err != nil {
+			t.Fatalf("write .gitleaksignore: %v", err)
+		}
+		git("add", "-A")
+		git("-c", "user.email=test@example.com", "-c", "user.name=test", "-c", "commit.gpgsign=false",
+			"commit", "-q", "-m", "test")
+		return git("rev-parse", "HEAD")
+	}
+
+	git("init", "-q", "-b", "main")
+	branchPoint := commit("a.txt:1\n")
+	git("checkout", "-q", "-b", "feature")
+	head := commit("a.txt:1\nfeature.txt:2\n")
+	git("checkout", "-q", "main")
+	commit("a.txt:1\nmain.txt:3\n")
+
+	cfg, err := ParseArgs(CommandDiff, []string{"--base", "main", "--head", "feature", "--repo", "owner/repo"}, io.Discard)
+	if err != nil {
+		t.Fatalf("ParseArgs() unexpected error: %v", err)
+	}
+	if cfg.BaseSHA != branchPoint || cfg.CommitSHA != head {
+		t.Errorf("BaseSHA = %q, CommitSHA = %q, want merge base %s and head %s", cfg.BaseSHA, cfg.CommitSHA, branchPoint, head)
+	}
+}
+
+// TestParseRemoteURL tests extracting owner/repo from git remote URLs
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"git@github.com:owner/repo.git", "owner/repo"},
+		{"https://github.com/owner/repo", "owner/repo"},
+		{"https://github.com/owner/repo.git", "owner/repo"},
+		{"https://github.company.com:8443/owner/repo/", "owner/repo"},
+		{"ssh://git@github.com/owner/repo.git", "owner/repo"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseRemoteURL(tt.url); got != tt.want {
+			t.Errorf("parseRemoteURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
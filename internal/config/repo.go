@@ -0,0 +1,288 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/commands"
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigPath is the repository configuration file, read from the base branch
+const RepoConfigPath = ".github/gitleaks-diff-comment.yml"
+
+// RepoConfig is the repository configuration file. Keys are named after the
+// action inputs; unset keys leave the input (or its default) in place.
+type RepoConfig struct {
+	IgnoreFiles       stringList `yaml:"ignore-files"`
+	ConfigFiles       stringList `yaml:"config-files"`
+	CommentMode       *string    `yaml:"comment-mode"`
+	OutputMode        *string    `yaml:"output-mode"`
+	DeliveryMode      *string    `yaml:"delivery-mode"`
+	SummaryComment    *bool      `yaml:"summary-comment"`
+	InlineAllow       *bool      `yaml:"inline-allow"`
+	ValidateEntries   *bool      `yaml:"validate-entries"`
+	WildcardThreshold *int       `yaml:"wildcard-threshold"`
+	ShowSnippets      *bool      `yaml:"show-snippets"`
+	TemplateDir       *string    `yaml:"template-dir"`
+
+	Policy   *RepoPolicy   `yaml:"policy"`
+	Commands *RepoCommands `yaml:"commands"`
+
+	// lines maps each key (e.g. "policy.max-new-entries") to its line, for errors
+	lines map[string]int
+}
+
+// RepoPolicy is the policy section of the repository configuration file,
+// named after the policy-* inputs
+type RepoPolicy struct {
+	ForbidWildcards   bool       `yaml:"forbid-wildcards"`
+	RequireLineNumber bool       `yaml:"require-line-number"`
+	ForbiddenPaths    stringList `yaml:"forbidden-paths"`
+	MaxNewEntries     int        `yaml:"max-new-entries"`
+	RequireReason     bool       `yaml:"require-reason"`
+	RequireTicket     bool       `yaml:"require-ticket"`
+	MaxExpiryDays     int        `yaml:"max-expiry-days"`
+	WarnOnly          stringList `yaml:"warn-only"`
+}
+
+// RepoCommands is the commands section of the repository configuration file
+type RepoCommands struct {
+	// Permission is the lowest permission level allowed to run commands
+	Permission string `yaml:"permission"`
+
+	// Disabled lists commands that may not be run, e.g. [clear]
+	Disabled stringList `yaml:"disabled"`
+}
+
+// stringList is a YAML sequence of strings, or a comma-separated string like the inputs
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = parseList(node.Value)
+		return nil
+	}
+	var items []string
+	if err := node.Decode(&items); err != nil {
+		return err
+	}
+	*l = parseList(strings.Join(items, ","))
+	return nil
+}
+
+// ParseRepoConfig parses and validates the repository configuration file
+// Unknown keys and invalid values are reported with their line numbers.
+func ParseRepoConfig(content []byte) (*RepoConfig, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", RepoConfigPath, err)
+	}
+
+	rc := &RepoConfig{lines: make(map[string]int)}
+	if len(root.Content) == 0 {
+		return rc, nil // Empty file
+	}
+	recordLines(root.Content[0], "", rc.lines)
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(rc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", RepoConfigPath, err)
+	}
+
+	if err := rc.validate(); err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// recordLines records the line of every mapping key below node
+func recordLines(node *yaml.Node, prefix string, lines map[string]int) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := prefix + node.Content[i].Value
+		lines[key] = node.Content[i].Line
+		recordLines(node.Content[i+1], key+".", lines)
+	}
+}
+
+// errorAt returns an error naming the file and the line of key
+func (rc *RepoConfig) errorAt(key, format string, args ...any) error {
+	return fmt.Errorf("%s line %d: %s: %s\n"+
+		"  → Action: Fix '%s' in %s on the base branch\n"+
+		"  → The file is read from the base branch, so the fix must be merged before it applies",
+		RepoConfigPath, rc.lines[key], key, fmt.Sprintf(format, args...), key, RepoConfigPath)
+}
+
+// validate checks the values a YAML type check cannot
+func (rc *RepoConfig) validate() error {
+	oneOf := func(key string, value *string, allowed ...string) error {
+		if value != nil && !slices.Contains(allowed, *value) {
+			return rc.errorAt(key, "must be one of %s, got: %q", strings.Join(allowed, ", "), *value)
+		}
+		return nil
+	}
+
+	if err := oneOf("comment-mode", rc.CommentMode, "override", "append"); err != nil {
+		return err
+	}
+	if err := oneOf("output-mode", rc.OutputMode, "comments", "check", "both"); err != nil {
+		return err
+	}
+	if err := oneOf("delivery-mode", rc.DeliveryMode, "comments", "review"); err != nil {
+		return err
+	}
+	if rc.WildcardThreshold != nil && *rc.WildcardThreshold < 0 {
+		return rc.errorAt("wildcard-threshold", "must not be negative, got: %d", *rc.WildcardThreshold)
+	}
+
+	for _, pattern := range rc.IgnoreFiles {
+		if err := diff.ValidateGlob(pattern); err != nil {
+			return rc.errorAt("ignore-files", "%v", err)
+		}
+	}
+	for _, pattern := range rc.ConfigFiles {
+		if err := diff.ValidateGlob(pattern); err != nil {
+			return rc.errorAt("config-files", "%v", err)
+		}
+	}
+
+	if p := rc.Policy; p != nil {
+		for _, pattern := range p.ForbiddenPaths {
+			if err := (&diff.Policy{ForbiddenPaths: []string{pattern}}).Validate(); err != nil {
+				return rc.errorAt("policy.forbidden-paths", "%v", err)
+			}
+		}
+		if p.MaxNewEntries < 0 {
+			return rc.errorAt("policy.max-new-entries", "must not be negative, got: %d", p.MaxNewEntries)
+		}
+		if p.MaxExpiryDays < 0 {
+			return rc.errorAt("policy.max-expiry-days", "must not be negative, got: %d", p.MaxExpiryDays)
+		}
+		for _, rule := range p.WarnOnly {
+			if !slices.Contains(diff.PolicyRules(), rule) {
+				return rc.errorAt("policy.warn-only", "unknown policy rule %q (available: %s)",
+					rule, strings.Join(diff.PolicyRules(), ", "))
+			}
+		}
+	}
+
+	if c := rc.Commands; c != nil {
+		if c.Permission != "" && !slices.Contains(commands.CommandPermissions, c.Permission) {
+			return rc.errorAt("commands.permission", "must be one of %s, got: %q",
+				strings.Join(commands.CommandPermissions, ", "), c.Permission)
+		}
+		for _, name := range c.Disabled {
+			if name != "clear" {
+				return rc.errorAt("commands.disabled", "unknown command %q (available: clear)", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isSet returns true if the input was set explicitly, so it takes precedence
+// over the repository configuration file
+func (c *Config) isSet(input string) bool {
+	return c.explicit[input]
+}
+
+// ApplyRepoConfig merges the repository configuration file into c
+// Inputs set explicitly in the workflow (or flags on the command line) take
+// precedence over the file, which takes precedence over the defaults. Policy
+// rules are combined instead, so inputs can add rules but never relax the
+// file's: switches are enabled by either, lists are joined, the lower limit
+// wins, and only the file decides which of its rules merely warn.
+func (c *Config) ApplyRepoConfig(rc *RepoConfig) error {
+	setList := func(input string, dst *[]string, value stringList) {
+		if len(value) > 0 && !c.isSet(input) {
+			*dst = value
+		}
+	}
+	setString := func(input string, dst *string, value *string) {
+		if value != nil && !c.isSet(input) {
+			*dst = *value
+		}
+	}
+	setBool := func(input string, dst *bool, value *bool) {
+		if value != nil && !c.isSet(input) {
+			*dst = *value
+		}
+	}
+
+	setList("ignore-files", &c.IgnoreFiles, rc.IgnoreFiles)
+	setList("config-files", &c.ConfigFiles, rc.ConfigFiles)
+	setString("comment-mode", &c.CommentMode, rc.CommentMode)
+	setString("output-mode", &c.OutputMode, rc.OutputMode)
+	setString("delivery-mode", &c.DeliveryMode, rc.DeliveryMode)
+	setBool("summary-comment", &c.SummaryComment, rc.SummaryComment)
+	setBool("inline-allow", &c.InlineAllow, rc.InlineAllow)
+	setBool("validate-entries", &c.ValidateEntries, rc.ValidateEntries)
+	setBool("show-snippets", &c.ShowSnippets, rc.ShowSnippets)
+	if rc.WildcardThreshold != nil && !c.isSet("wildcard-threshold") {
+		c.WildcardThreshold = *rc.WildcardThreshold
+	}
+	if rc.TemplateDir != nil && !c.isSet("template-dir") {
+		c.TemplateDir = strings.Trim(strings.TrimSpace(*rc.TemplateDir), "/")
+	}
+
+	if p := rc.Policy; p != nil {
+		// Inputs may only downgrade rules the file does not enable
+		fileRules := p.policy().EnabledRules()
+		warnOnly := slices.Clone(p.WarnOnly)
+		for _, rule := range c.Policy.WarnOnly {
+			if !slices.Contains(fileRules, rule) && !slices.Contains(warnOnly, rule) {
+				warnOnly = append(warnOnly, rule)
+			}
+		}
+
+		c.Policy.ForbidWildcards = c.Policy.ForbidWildcards || p.ForbidWildcards
+		c.Policy.RequireLineNumber = c.Policy.RequireLineNumber || p.RequireLineNumber
+		c.Policy.RequireReason = c.Policy.RequireReason || p.RequireReason
+		c.Policy.RequireTicket = c.Policy.RequireTicket || p.RequireTicket
+		c.Policy.ForbiddenPaths = append(slices.Clone(p.ForbiddenPaths), c.Policy.ForbiddenPaths...)
+		c.Policy.MaxNewEntries = lowerLimit(p.MaxNewEntries, c.Policy.MaxNewEntries)
+		c.Policy.MaxExpiryDays = lowerLimit(p.MaxExpiryDays, c.Policy.MaxExpiryDays)
+		c.Policy.WarnOnly = warnOnly
+	}
+
+	if rc.Commands != nil {
+		if rc.Commands.Permission != "" {
+			c.CommandPermission = rc.Commands.Permission
+		}
+		c.DisabledCommands = rc.Commands.Disabled
+	}
+
+	return c.Validate()
+}
+
+// policy returns the rules of the policy section
+func (p *RepoPolicy) policy() *diff.Policy {
+	return &diff.Policy{
+		ForbidWildcards:   p.ForbidWildcards,
+		RequireLineNumber: p.RequireLineNumber,
+		ForbiddenPaths:    p.ForbiddenPaths,
+		MaxNewEntries:     p.MaxNewEntries,
+		RequireReason:     p.RequireReason,
+		RequireTicket:     p.RequireTicket,
+		MaxExpiryDays:     p.MaxExpiryDays,
+		WarnOnly:          p.WarnOnly,
+	}
+}
+
+// lowerLimit returns the stricter of two limits, where 0 means no limit
+func lowerLimit(a, b int) int {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
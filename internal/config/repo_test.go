@@ -0,0 +1,245 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newRepoTestConfig returns a valid configuration with the given inputs set explicitly
+func newRepoTestConfig(explicit ...string) *Config {
+	cfg := &Config{
+		GitHubToken:       "token",
+		PRNumber:          1,
+		Repository:        "owner/repo",
+		CommitSHA:         "abc123",
+		CommentMode:       "override",
+		OutputMode:        "comments",
+		DeliveryMode:      "comments",
+		IgnoreFiles:       []string{".gitleaksignore"},
+		WildcardThreshold: 25,
+		explicit:          make(map[string]bool),
+	}
+	for _, input := range explicit {
+		cfg.explicit[input] = true
+	}
+	return cfg
+}
+
+// TestParseRepoConfig tests parsing the repository configuration file
+func TestParseRepoConfig(t *testing.T) {
+	rc, err := ParseRepoConfig([]byte(`
+ignore-files:
+  - "**/.gitleaksignore"
+  - .gitleaksignore
+config-files: .gitleaks.toml, config/gitleaks.toml
+comment-mode: append
+summary-comment: false
+wildcard-threshold: 10
+policy:
+  forbid-wildcards: true
+  forbidden-paths: [src/, "*.pem"]
+  max-new-entries: 5
+commands:
+  permission: admin
+  disabled: [clear]
+`))
+	if err != nil {
+		t.Fatalf("ParseRepoConfig() unexpected error: %v", err)
+	}
+
+	if want := []string{"**/.gitleaksignore", ".gitleaksignore"}; !reflect.DeepEqual([]string(rc.IgnoreFiles), want) {
+		t.Errorf("IgnoreFiles = %v, want %v", rc.IgnoreFiles, want)
+	}
+	if want := []string{".gitleaks.toml", "config/gitleaks.toml"}; !reflect.DeepEqual([]string(rc.ConfigFiles), want) {
+		t.Errorf("ConfigFiles = %v, want %v", rc.ConfigFiles, want)
+	}
+	if rc.CommentMode == nil || *rc.CommentMode != "append" {
+		t.Errorf("CommentMode = %v, want append", rc.CommentMode)
+	}
+	if rc.SummaryComment == nil || *rc.SummaryComment {
+		t.Errorf("SummaryComment = %v, want false", rc.SummaryComment)
+	}
+	if rc.OutputMode != nil || rc.InlineAllow != nil {
+		t.Errorf("unset keys should stay nil, got OutputMode = %v, InlineAllow = %v", rc.OutputMode, rc.InlineAllow)
+	}
+	if rc.Policy == nil || !rc.Policy.ForbidWildcards || rc.Policy.MaxNewEntries != 5 || len(rc.Policy.ForbiddenPaths) != 2 {
+		t.Errorf("Policy = %+v, want wildcards forbidden, 2 forbidden paths and at most 5 new entries", rc.Policy)
+	}
+	if rc.Commands == nil || rc.Commands.Permission != "admin" || !reflect.DeepEqual([]string(rc.Commands.Disabled), []string{"clear"}) {
+		t.Errorf("Commands = %+v, want admin and clear disabled", rc.Commands)
+	}
+
+	if _, err := ParseRepoConfig(nil); err != nil {
+		t.Errorf("ParseRepoConfig() with empty file unexpected error: %v", err)
+	}
+}
+
+// TestParseRepoConfig_Errors tests that mistakes are reported with their line
+func TestParseRepoConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantError string
+	}{
+		{
+			name:      "unknown key",
+			content:   "comment-mode: append\nignore-file: .gitleaksignore\n",
+			wantError: "line 2: field ignore-file not found",
+		},
+		{
+			name:      "unknown policy key",
+			content:   "policy:\n  forbid-wildcard: true\n",
+			wantError: "line 2: field forbid-wildcard not found",
+		},
+		{
+			name:      "wrong type",
+			content:   "summary-comment: sometimes\n",
+			wantError: "line 1",
+		},
+		{
+			name:      "invalid comment mode",
+			content:   "# settings\nignore-files: .gitleaksignore\ncomment-mode: replace\n",
+			wantError: "line 3: comment-mode: must be one of override, append",
+		},
+		{
+			name:      "negative threshold",
+			content:   "wildcard-threshold: -1\n",
+			wantError: "line 1: wildcard-threshold: must not be negative",
+		},
+		{
+			name:      "invalid glob",
+			content:   "ignore-files: \"[\"\n",
+			wantError: "line 1: ignore-files:",
+		},
+		{
+			name:      "unknown warn-only rule",
+			content:   "policy:\n  max-new-entries: 3\n  warn-only: [max-entries]\n",
+			wantError: "line 3: policy.warn-only: unknown policy rule \"max-entries\"",
+		},
+		{
+			name:      "invalid permission",
+			content:   "commands:\n  permission: maintain\n",
+			wantError: "line 2: commands.permission: must be one of read, write, admin",
+		},
+		{
+			name:      "unknown command",
+			content:   "commands:\n  disabled: [reset]\n",
+			wantError: "line 2: commands.disabled: unknown command \"reset\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRepoConfig([]byte(tt.content))
+			if err == nil {
+				t.Fatalf("ParseRepoConfig() expected error containing %q, got nil", tt.wantError)
+			}
+			if !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("ParseRepoConfig() error = %q, want it to contain %q", err, tt.wantError)
+			}
+			if !strings.HasPrefix(err.Error(), RepoConfigPath) {
+				t.Errorf("ParseRepoConfig() error = %q, want it to name %s", err, RepoConfigPath)
+			}
+		})
+	}
+}
+
+// TestApplyRepoConfig tests that explicit inputs win over the file, which wins over defaults
+func TestApplyRepoConfig(t *testing.T) {
+	rc, err := ParseRepoConfig([]byte(`
+ignore-files: "**/.gitleaksignore"
+comment-mode: append
+delivery-mode: review
+inline-allow: false
+wildcard-threshold: 5
+template-dir: /.github/templates/
+commands:
+  permission: admin
+  disabled: clear
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newRepoTestConfig("comment-mode", "wildcard-threshold")
+	cfg.InlineAllow = true
+	if err := cfg.ApplyRepoConfig(rc); err != nil {
+		t.Fatalf("ApplyRepoConfig() unexpected error: %v", err)
+	}
+
+	// Explicit inputs
+	if cfg.CommentMode != "override" || cfg.WildcardThreshold != 25 {
+		t.Errorf("CommentMode = %q, WildcardThreshold = %d, want the inputs override and 25",
+			cfg.CommentMode, cfg.WildcardThreshold)
+	}
+	// Defaults replaced by the file
+	if !reflect.DeepEqual(cfg.IgnoreFiles, []string{"**/.gitleaksignore"}) || cfg.DeliveryMode != "review" ||
+		cfg.InlineAllow || cfg.TemplateDir != ".github/templates" {
+		t.Errorf("IgnoreFiles = %v, DeliveryMode = %q, InlineAllow = %v, TemplateDir = %q, want the file's values",
+			cfg.IgnoreFiles, cfg.DeliveryMode, cfg.InlineAllow, cfg.TemplateDir)
+	}
+	// Defaults the file leaves alone
+	if cfg.OutputMode != "comments" {
+		t.Errorf("OutputMode = %q, want the default comments", cfg.OutputMode)
+	}
+	if cfg.CommandPermission != "admin" || !reflect.DeepEqual(cfg.DisabledCommands, []string{"clear"}) {
+		t.Errorf("CommandPermission = %q, DisabledCommands = %v, want admin and [clear]",
+			cfg.CommandPermission, cfg.DisabledCommands)
+	}
+}
+
+// TestApplyRepoConfig_Policy tests that inputs can tighten the file's policy but not relax it
+func TestApplyRepoConfig_Policy(t *testing.T) {
+	rc, err := ParseRepoConfig([]byte(`
+policy:
+  forbid-wildcards: true
+  forbidden-paths: [secrets/]
+  max-new-entries: 10
+  require-reason: true
+  warn-only: require-reason
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newRepoTestConfig("policy-require-line-number", "policy-forbidden-paths", "policy-max-new-entries", "policy-warn-only")
+	cfg.Policy.RequireLineNumber = true
+	cfg.Policy.ForbiddenPaths = []string{"*.pem"}
+	cfg.Policy.MaxNewEntries = 3
+	cfg.Policy.WarnOnly = []string{"no-wildcards", "require-line-number"}
+	if err := cfg.ApplyRepoConfig(rc); err != nil {
+		t.Fatalf("ApplyRepoConfig() unexpected error: %v", err)
+	}
+
+	p := cfg.Policy
+	if !p.ForbidWildcards || !p.RequireLineNumber || !p.RequireReason {
+		t.Errorf("Policy = %+v, want wildcards, missing line numbers and missing reasons checked", p)
+	}
+	if want := []string{"secrets/", "*.pem"}; !reflect.DeepEqual(p.ForbiddenPaths, want) {
+		t.Errorf("ForbiddenPaths = %v, want %v", p.ForbiddenPaths, want)
+	}
+	if p.MaxNewEntries != 3 {
+		t.Errorf("MaxNewEntries = %d, want the lower limit 3", p.MaxNewEntries)
+	}
+	// no-wildcards is enabled by the file, so the input cannot downgrade it
+	if want := []string{"require-reason", "require-line-number"}; !reflect.DeepEqual(p.WarnOnly, want) {
+		t.Errorf("WarnOnly = %v, want %v", p.WarnOnly, want)
+	}
+}
+
+// TestLowerLimit tests choosing the stricter limit, where 0 means no limit
+func TestLowerLimit(t *testing.T) {
+	tests := []struct{ a, b, want int }{
+		{0, 0, 0},
+		{0, 5, 5},
+		{5, 0, 5},
+		{3, 5, 3},
+		{5, 3, 3},
+	}
+	for _, tt := range tests {
+		if got := lowerLimit(tt.a, tt.b); got != tt.want {
+			t.Errorf("lowerLimit(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommandServe runs the bot as a webhook server
+const CommandServe = "serve"
+
+// ServeConfig holds the settings of the webhook server. Per-repository
+// settings come from each repository's configuration file (RepoConfigPath).
+type ServeConfig struct {
+	// Addr is the address the server listens on, e.g. ":8080"
+	Addr string
+
+	// Path is the URL path webhooks are delivered to
+	Path string
+
+	// WebhookSecret is the secret deliveries are signed with (X-Hub-Signature-256)
+	WebhookSecret string
+
+	// Workers is the number of deliveries processed at the same time
+	Workers int
+
+	// QueueSize is the number of deliveries that may wait for a worker;
+	// further deliveries are rejected until the queue drains
+	QueueSize int
+
+	// JobTimeout bounds the processing of one delivery
+	JobTimeout time.Duration
+
+	// ShutdownTimeout is how long queued and running deliveries may take to
+	// finish once the server is asked to stop
+	ShutdownTimeout time.Duration
+
+	// GitHubToken authenticates every repository when no GitHub App is configured
+	GitHubToken string
+
+	// AppID and AppPrivateKey authenticate as a GitHub App; each delivery
+	// uses the installation it was sent for
+	AppID         int64
+	AppPrivateKey string
+
+	// GHHost is the GitHub Enterprise Server hostname (empty = GitHub.com)
+	GHHost string
+
+	// Debug enables debug logging for every delivery
+	Debug bool
+}
+
+// ParseServeArgs parses the flags of the serve command. The secret and
+// credentials fall back to GITHUB_WEBHOOK_SECRET, GITHUB_TOKEN, GITHUB_APP_ID
+// and GITHUB_APP_PRIVATE_KEY, so they need not appear on the command line.
+func ParseServeArgs(args []string, output io.Writer) (*ServeConfig, error) {
+	cfg := &ServeConfig{
+		WebhookSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+		GitHubToken:   os.Getenv("GITHUB_TOKEN"),
+		AppPrivateKey: os.Getenv("GITHUB_APP_PRIVATE_KEY"),
+	}
+	var appKeyFile string
+	fs := flag.NewFlagSet(CommandServe, flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	fs.StringVar(&cfg.Addr, "addr", ":8080", "address to listen on")
+	fs.StringVar(&cfg.Path, "path", "/webhook", "URL path webhooks are delivered to")
+	fs.IntVar(&cfg.Workers, "workers", 4, "deliveries processed at the same time")
+	fs.IntVar(&cfg.QueueSize, "queue-size", 100, "deliveries that may wait for a worker before new ones are rejected")
+	fs.DurationVar(&cfg.JobTimeout, "job-timeout", 10*time.Minute, "time limit for processing one delivery")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", 30*time.Second, "time queued deliveries may take to finish on shutdown")
+	fs.Int64Var(&cfg.AppID, "app-id", 0, "GitHub App ID (default $GITHUB_APP_ID)")
+	fs.StringVar(&appKeyFile, "app-private-key", "", "path to the GitHub App's PEM private key (default $GITHUB_APP_PRIVATE_KEY, the PEM itself)")
+	fs.StringVar(&cfg.GHHost, "gh-host", "", "GitHub Enterprise Server hostname")
+	fs.BoolVar(&cfg.Debug, "debug", false, "enable debug logging")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q\n"+
+			"  → Run '%s -h' for the available flags", fs.Arg(0), CommandServe)
+	}
+
+	if cfg.AppID == 0 {
+		if appIDStr := os.Getenv("GITHUB_APP_ID"); appIDStr != "" {
+			appID, err := strconv.ParseInt(appIDStr, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid GITHUB_APP_ID: %w", err)
+			}
+			cfg.AppID = appID
+		}
+	}
+	if appKeyFile != "" {
+		key, err := os.ReadFile(appKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --app-private-key: %w\n"+
+				"  → Action: Pass the path of the .pem file downloaded from the GitHub App's settings", err)
+		}
+		cfg.AppPrivateKey = string(key)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks if the server configuration is valid
+func (s *ServeConfig) Validate() error {
+	// Unsigned deliveries could be forged by anyone who can reach the server
+	if s.WebhookSecret == "" {
+		return errors.New("webhook secret is required (GITHUB_WEBHOOK_SECRET)\n" +
+			"  → Action: Set the same secret in the webhook or GitHub App settings and in GITHUB_WEBHOOK_SECRET\n" +
+			"  → Example: GITHUB_WEBHOOK_SECRET=$(openssl rand -hex 32)")
+	}
+	if s.GitHubToken == "" && !s.UsesApp() {
+		return errors.New("GitHub credentials are required\n" +
+			"  → Action: Authenticate as a GitHub App with --app-id and --app-private-key\n" +
+			"  → Or pass a token with access to every repository in GITHUB_TOKEN")
+	}
+	if (s.AppID != 0) != (s.AppPrivateKey != "") {
+		return errors.New("GitHub App authentication needs both an app ID and a private key\n" +
+			"  → Action: Pass --app-id and --app-private-key, or neither to use GITHUB_TOKEN")
+	}
+	if !strings.HasPrefix(s.Path, "/") {
+		return fmt.Errorf("path must start with '/', got: %s\n"+
+			"  → Example: --path /webhook", s.Path)
+	}
+	if s.Workers < 1 || s.QueueSize < 1 {
+		return fmt.Errorf("workers and queue-size must be positive, got: %d and %d\n"+
+			"  → Example: --workers 4 --queue-size 100", s.Workers, s.QueueSize)
+	}
+	if s.JobTimeout <= 0 || s.ShutdownTimeout <= 0 {
+		return errors.New("job-timeout and shutdown-timeout must be positive\n" +
+			"  → Example: --job-timeout 10m --shutdown-timeout 30s")
+	}
+	return nil
+}
+
+// UsesApp returns true if the server authenticates as a GitHub App
+func (s *ServeConfig) UsesApp() bool {
+	return s.AppID != 0 && s.AppPrivateKey != ""
+}
+
+// JobConfig returns the configuration for one webhook delivery: the event's
+// pull request in repository, with default settings that the repository's
+// configuration file may override. Files are read through the API, as the
+// server has no checkout. command is empty to comment on the pull request's
+// changes, or the command to run, e.g. "clear".
+func (s *ServeConfig) JobConfig(repository string, installationID int64, event *Event, command string) (*Config, error) {
+	cfg := &Config{
+		GitHubToken:       s.GitHubToken,
+		GHHost:            s.GHHost,
+		Debug:             s.Debug,
+		Repository:        repository,
+		CommitSHA:         event.HeadSHA,
+		Mode:              "diff",
+		Command:           command,
+		CommentMode:       "override",
+		OutputMode:        "comments",
+		DeliveryMode:      "comments",
+		GitBackend:        "cli",
+		DiffSource:        "api",
+		IgnoreFiles:       []string{".gitleaksignore"},
+		ConfigFiles:       []string{".gitleaks.toml"},
+		WildcardThreshold: 25,
+		TemplateDir:       ".github/gitleaks-diff-comment",
+		SummaryComment:    true,
+		InlineAllow:       true,
+		ValidateEntries:   true,
+		ShowSnippets:      false,
+		GitleaksPath:      "gitleaks",
+		AuditIssueTitle:   "Gitleaks exclusion audit",
+	}
+	if s.UsesApp() {
+		cfg.AppID = s.AppID
+		cfg.AppPrivateKey = s.AppPrivateKey
+		cfg.AppInstallationID = installationID
+	}
+	cfg.applyEvent(event)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
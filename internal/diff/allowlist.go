@@ -0,0 +1,299 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// GitleaksConfigPath is the default gitleaks configuration file
+const GitleaksConfigPath = ".gitleaks.toml"
+
+// AllowlistKind identifies which allowlist array an element belongs to
+type AllowlistKind string
+
+const (
+	// AllowlistPaths are regular expressions matched against file paths
+	AllowlistPaths AllowlistKind = "paths"
+
+	// AllowlistRegexes are regular expressions matched against findings
+	AllowlistRegexes AllowlistKind = "regexes"
+
+	// AllowlistStopwords are words that, when found in a secret, suppress it
+	AllowlistStopwords AllowlistKind = "stopwords"
+
+	// AllowlistCommits are commit SHAs whose findings are ignored
+	AllowlistCommits AllowlistKind = "commits"
+)
+
+// isAllowlistKind reports whether key names one of the allowlist arrays
+func isAllowlistKind(key string) bool {
+	switch AllowlistKind(key) {
+	case AllowlistPaths, AllowlistRegexes, AllowlistStopwords, AllowlistCommits:
+		return true
+	}
+	return false
+}
+
+// AllowlistEntry is a single element of an allowlist array in a gitleaks config
+type AllowlistEntry struct {
+	// Kind is the array the element appears in (paths, regexes, ...)
+	Kind AllowlistKind `json:"kind"`
+
+	// Value is the decoded string value
+	Value string `json:"value"`
+
+	// RuleIDs are the rules the allowlist applies to; empty means all rules
+	// Set from the enclosing [[rules]] id or from an [[allowlists]] targetRules.
+	RuleIDs []string `json:"rule_ids,omitempty"`
+
+	// Description is the allowlist's description, if any
+	Description string `json:"description,omitempty"`
+
+	// LineNumber is the 1-indexed line the element starts on
+	LineNumber int `json:"line_number"`
+}
+
+// IsGlobal returns true if the entry applies to every rule
+func (e *AllowlistEntry) IsGlobal() bool {
+	return len(e.RuleIDs) == 0
+}
+
+// key identifies an entry independent of its position in the file
+func (e *AllowlistEntry) key() string {
+	return string(e.Kind) + "\x00" + e.Value + "\x00" + strings.Join(e.RuleIDs, ",")
+}
+
+// AllowlistChange represents an allowlist element added to or removed from a gitleaks config
+type AllowlistChange struct {
+	// FilePath is the path of the gitleaks config file
+	FilePath string `json:"file_path"`
+
+	// Operation is OperationAddition or OperationDeletion
+	Operation OperationType `json:"operation"`
+
+	// LineNumber is the line in the new file (additions)
+	LineNumber int `json:"line_number,omitempty"`
+
+	// OldLineNumber is the line in the old file (deletions)
+	OldLineNumber int `json:"old_line_number,omitempty"`
+
+	// Entry is the allowlist element that was added or removed
+	Entry AllowlistEntry `json:"entry"`
+}
+
+// allowlistTable accumulates the entries of one allowlist table
+// Keys such as targetRules and description may follow the arrays they
+// apply to, so entries are finalized only when the table ends.
+type allowlistTable struct {
+	ruleIDs     []string
+	description string
+	entries     []AllowlistEntry
+}
+
+// ScanAllowlists extracts every allowlist array element from a gitleaks config
+//
+// Supported layouts:
+//
+//	[allowlist]              global allowlist
+//	[[allowlists]]           global allowlists, optionally scoped by targetRules
+//	[[rules]] / [rules.allowlist] / [[rules.allowlists]]  per-rule allowlists
+//
+// Elements are returned in file order.
+func ScanAllowlists(content []byte) ([]AllowlistEntry, error) {
+	s := newTOMLScanner(content)
+
+	var (
+		entries []AllowlistEntry
+		current *allowlistTable
+		ruleID  string
+		inRule  bool
+	)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		for _, entry := range current.entries {
+			entry.RuleIDs = current.ruleIDs
+			entry.Description = current.description
+			entries = append(entries, entry)
+		}
+		current = nil
+	}
+
+	onHeader := func(name string, isArray bool) {
+		flush()
+
+		switch {
+		case name == "rules" && isArray:
+			inRule, ruleID = true, ""
+		case name == "allowlist" || name == "allowlists":
+			inRule = false
+			current = &allowlistTable{}
+		case inRule && (name == "rules.allowlist" || name == "rules.allowlists"):
+			current = &allowlistTable{ruleIDs: ruleIDList(ruleID)}
+		case !strings.HasPrefix(name, "rules."):
+			inRule = false
+		}
+	}
+
+	onKey := func(line int, key string, value tomlValue) error {
+		switch {
+		case current != nil && isAllowlistKind(key):
+			current.entries = append(current.entries, toAllowlistEntries(key, value)...)
+		case current != nil && key == "targetRules" && current.ruleIDs == nil:
+			for _, str := range value.Strings {
+				current.ruleIDs = append(current.ruleIDs, str.Value)
+			}
+		case current != nil && key == "description" && len(value.Strings) == 1:
+			current.description = value.Strings[0].Value
+		case current == nil && inRule && key == "id" && len(value.Strings) == 1:
+			ruleID = value.Strings[0].Value
+		case current == nil && inRule && strings.HasPrefix(key, "allowlist."):
+			// Dotted keys such as allowlist.paths = [...] inside [[rules]]
+			if kind := strings.TrimPrefix(key, "allowlist."); isAllowlistKind(kind) {
+				for _, entry := range toAllowlistEntries(kind, value) {
+					entry.RuleIDs = ruleIDList(ruleID)
+					entries = append(entries, entry)
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := s.walk(onHeader, onKey); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return entries, nil
+}
+
+// toAllowlistEntries converts the string elements of an allowlist array
+func toAllowlistEntries(kind string, value tomlValue) []AllowlistEntry {
+	entries := make([]AllowlistEntry, 0, len(value.Strings))
+	for _, str := range value.Strings {
+		entries = append(entries, AllowlistEntry{
+			Kind:       AllowlistKind(kind),
+			Value:      str.Value,
+			LineNumber: str.Line,
+		})
+	}
+	return entries
+}
+
+// ruleIDList returns id as a single-element list, or nil if empty
+func ruleIDList(id string) []string {
+	if id == "" {
+		return nil
+	}
+	return []string{id}
+}
+
+// DiffAllowlists compares the allowlists in two versions of a gitleaks config
+// Elements that merely moved, or whose own line did not change, are not reported.
+// A nil oldContent or newContent denotes a file that does not exist on that side.
+func DiffAllowlists(path string, oldContent, newContent []byte) ([]AllowlistChange, error) {
+	oldEntries, err := ScanAllowlists(oldContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse old %s: %w", path, err)
+	}
+	newEntries, err := ScanAllowlists(newContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse new %s: %w", path, err)
+	}
+
+	// Only lines that actually changed can carry a review comment
+	deletedLines := make(map[int]bool)
+	insertedLines := make(map[int]bool)
+	for _, e := range myersDiff(splitLines(oldContent), splitLines(newContent)) {
+		switch e.kind {
+		case editDelete:
+			deletedLines[e.oldPos+1] = true
+		case editInsert:
+			insertedLines[e.newPos+1] = true
+		}
+	}
+
+	oldCount := make(map[string]int)
+	for _, entry := range oldEntries {
+		oldCount[entry.key()]++
+	}
+	newCount := make(map[string]int)
+	for _, entry := range newEntries {
+		newCount[entry.key()]++
+	}
+
+	changes := []AllowlistChange{}
+
+	// Removed elements first, matching the order used for .gitleaksignore
+	seen := make(map[string]int)
+	for _, entry := range oldEntries {
+		k := entry.key()
+		seen[k]++
+		if seen[k] <= newCount[k] || !deletedLines[entry.LineNumber] {
+			continue
+		}
+		changes = append(changes, AllowlistChange{
+			FilePath:      path,
+			Operation:     OperationDeletion,
+			OldLineNumber: entry.LineNumber,
+			Entry:         entry,
+		})
+	}
+
+	seen = make(map[string]int)
+	for _, entry := range newEntries {
+		k := entry.key()
+		seen[k]++
+		if seen[k] <= oldCount[k] || !insertedLines[entry.LineNumber] {
+			continue
+		}
+		changes = append(changes, AllowlistChange{
+			FilePath:   path,
+			Operation:  OperationAddition,
+			LineNumber: entry.LineNumber,
+			Entry:      entry,
+		})
+	}
+
+	return changes, nil
+}
+
+// ParseAllowlistDiff computes allowlist changes in every gitleaks config
+// matching patterns for exactly the given range
+func ParseAllowlistDiff(ctx context.Context, reader ObjectReader, rng Range, patterns []string) ([]AllowlistChange, error) {
+	if len(patterns) == 0 {
+		patterns = []string{GitleaksConfigPath}
+	}
+
+	files, err := reader.ChangedFiles(ctx, rng.Base, rng.Head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files in range %s: %w", rng, err)
+	}
+
+	changes := []AllowlistChange{}
+	for _, file := range files {
+		if !MatchAnyGlob(patterns, file) {
+			continue
+		}
+
+		oldContent, err := readOptionalBlob(ctx, reader, rng.Base, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at base of range %s: %w", file, rng, err)
+		}
+		newContent, err := readOptionalBlob(ctx, reader, rng.Head, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at head of range %s: %w", file, rng, err)
+		}
+
+		fileChanges, err := DiffAllowlists(file, oldContent, newContent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze allowlists for range %s: %w", rng, err)
+		}
+		changes = append(changes, fileChanges...)
+	}
+
+	return changes, nil
+}
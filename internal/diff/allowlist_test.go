@@ -0,0 +1,181 @@
+package diff
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const testGitleaksConfig = `title = "custom config"
+
+[extend]
+useDefault = true
+
+[allowlist]
+description = "global allowlist"
+paths = [
+  '''(^|/)testdata/''',
+  "vendor/.*", # third party code
+]
+regexes = ['''AKIA.*TEST''']
+stopwords = ["example"]
+
+[[rules]]
+id = "aws-access-key"
+regex = '''(A3T[A-Z0-9]|AKIA)[A-Z0-9]{16}'''
+keywords = ["akia"]
+
+  [rules.allowlist]
+  regexes = [
+    """AKIA\\w+EXAMPLE""",
+  ]
+
+[[rules]]
+id = "generic-api-key"
+allowlist.stopwords = ["dummy"]
+
+[[allowlists]]
+commits = ["cd5226711335c68be1e720b318b7bc3135a30eb2"]
+targetRules = ["generic-api-key", "aws-access-key"]
+`
+
+func TestScanAllowlists(t *testing.T) {
+	entries, err := ScanAllowlists([]byte(testGitleaksConfig))
+	if err != nil {
+		t.Fatalf("ScanAllowlists() unexpected error: %v", err)
+	}
+
+	want := []struct {
+		kind  AllowlistKind
+		value string
+		rules string
+		line  int
+	}{
+		{AllowlistPaths, "(^|/)testdata/", "", 9},
+		{AllowlistPaths, "vendor/.*", "", 10},
+		{AllowlistRegexes, "AKIA.*TEST", "", 12},
+		{AllowlistStopwords, "example", "", 13},
+		{AllowlistRegexes, `AKIA\w+EXAMPLE`, "aws-access-key", 22},
+		{AllowlistStopwords, "dummy", "generic-api-key", 27},
+		{AllowlistCommits, "cd5226711335c68be1e720b318b7bc3135a30eb2", "generic-api-key,aws-access-key", 30},
+	}
+
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(want), len(entries), entries)
+	}
+	for i, w := range want {
+		got := entries[i]
+		if got.Kind != w.kind || got.Value != w.value || strings.Join(got.RuleIDs, ",") != w.rules || got.LineNumber != w.line {
+			t.Errorf("entries[%d] = %+v, want %s %q rules=%q line %d", i, got, w.kind, w.value, w.rules, w.line)
+		}
+	}
+
+	if entries[0].Description != "global allowlist" {
+		t.Errorf("entries[0].Description = %q, want %q", entries[0].Description, "global allowlist")
+	}
+}
+
+func TestScanAllowlists_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unterminated array", "[allowlist]\npaths = [\n  \"a\",\n", "line 2: unterminated array"},
+		{"unterminated string", "[allowlist]\nregexes = [\"abc]\n", "line 2: unterminated string"},
+		{"unterminated header", "[allowlist\n", "line 1: unterminated table header"},
+		{"missing equals", "[allowlist]\npaths\n", "line 2: expected '='"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ScanAllowlists([]byte(tt.content))
+			if err == nil {
+				t.Fatal("ScanAllowlists() expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiffAllowlists(t *testing.T) {
+	oldConfig := `[allowlist]
+regexes = [
+  '''old-regex''',
+  '''kept-regex''',
+]
+
+[[rules]]
+id = "aws-access-key"
+[rules.allowlist]
+paths = ["moved/.*"]
+`
+	newConfig := `[allowlist]
+regexes = [
+  '''kept-regex''',
+  '''AKIA.*TEST''',
+]
+
+[[rules]]
+id = "aws-access-key"
+[rules.allowlist]
+stopwords = ["dummy"]
+paths = ["moved/.*"]
+`
+
+	changes, err := DiffAllowlists(".gitleaks.toml", []byte(oldConfig), []byte(newConfig))
+	if err != nil {
+		t.Fatalf("DiffAllowlists() unexpected error: %v", err)
+	}
+
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d: %+v", len(changes), changes)
+	}
+	if changes[0].Operation != OperationDeletion || changes[0].Entry.Value != "old-regex" || changes[0].OldLineNumber != 3 {
+		t.Errorf("changes[0] = %+v, want deletion of old-regex at old line 3", changes[0])
+	}
+	if changes[1].Operation != OperationAddition || changes[1].Entry.Value != "AKIA.*TEST" || changes[1].LineNumber != 4 || !changes[1].Entry.IsGlobal() {
+		t.Errorf("changes[1] = %+v, want global addition of AKIA.*TEST at line 4", changes[1])
+	}
+	if changes[2].Entry.Kind != AllowlistStopwords || changes[2].LineNumber != 10 || strings.Join(changes[2].Entry.RuleIDs, ",") != "aws-access-key" {
+		t.Errorf("changes[2] = %+v, want stopword for aws-access-key at line 10", changes[2])
+	}
+}
+
+func TestDiffAllowlists_NewFile(t *testing.T) {
+	changes, err := DiffAllowlists(".gitleaks.toml", nil, []byte("[allowlist]\nstopwords = [\"example\"]\n"))
+	if err != nil {
+		t.Fatalf("DiffAllowlists() unexpected error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Operation != OperationAddition || changes[0].LineNumber != 2 {
+		t.Errorf("expected single addition at line 2, got %+v", changes)
+	}
+}
+
+func TestParseAllowlistDiff(t *testing.T) {
+	repo := newTestRepo(t)
+	base := repo.commit(map[string]string{".gitleaks.toml": "[allowlist]\npaths = []\n"})
+	head := repo.commit(map[string]string{
+		".gitleaks.toml":  "[allowlist]\npaths = [\n  \"fixtures/.*\",\n]\n",
+		".gitleaksignore": "a.txt\n",
+	})
+
+	for _, backend := range []string{BackendGitCLI, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			reader, err := NewObjectReader(backend, repo.dir)
+			if err != nil {
+				t.Fatalf("NewObjectReader() unexpected error: %v", err)
+			}
+
+			changes, err := ParseAllowlistDiff(context.Background(), reader, Range{Base: base, Head: head}, nil)
+			if err != nil {
+				t.Fatalf("ParseAllowlistDiff() unexpected error: %v", err)
+			}
+			if len(changes) != 1 || changes[0].FilePath != ".gitleaks.toml" || changes[0].Entry.Value != "fixtures/.*" || changes[0].LineNumber != 3 {
+				t.Errorf("expected addition of fixtures/.* at .gitleaks.toml:3, got %+v", changes)
+			}
+		})
+	}
+}
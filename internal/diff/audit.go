@@ -0,0 +1,187 @@
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Audit problem kinds
+const (
+	// AuditExpired is an entry whose expires date has passed
+	AuditExpired = "expired"
+
+	// AuditInvalidExpiry is an entry whose expires date is not YYYY-MM-DD
+	AuditInvalidExpiry = "invalid-expiry"
+
+	// AuditMissingFile is an entry whose file no longer exists, or a wildcard matching nothing
+	AuditMissingFile = "missing-file"
+
+	// AuditMissingLine is an entry whose line is past the end of its file
+	AuditMissingLine = "missing-line"
+
+	// AuditUnused is an entry that suppresses no current finding
+	AuditUnused = "unused"
+)
+
+// RepositoryReader reads files and lists trees, e.g. a local checkout or the GitHub API
+type RepositoryReader interface {
+	BlobReader
+	TreeReader
+}
+
+// AuditProblem is a reason an existing entry needs attention
+type AuditProblem struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// AuditEntry is an entry with at least one problem
+type AuditEntry struct {
+	Change   DiffChange     `json:"change"`
+	Problems []AuditProblem `json:"problems"`
+}
+
+// AuditReport lists the entries of the ignore files that need attention
+type AuditReport struct {
+	// Revision is the commit the ignore files were read at
+	Revision string `json:"revision"`
+
+	// Total is the number of entries audited
+	Total int `json:"total"`
+
+	// Entries are the entries with problems, in file order
+	Entries []AuditEntry `json:"entries,omitempty"`
+
+	// Verified is true if entries were checked against a gitleaks scan, so
+	// unused entries could be detected
+	Verified bool `json:"verified"`
+}
+
+// Count returns the number of entries with a problem of kind
+func (r *AuditReport) Count(kind string) int {
+	count := 0
+	for _, entry := range r.Entries {
+		for _, problem := range entry.Problems {
+			if problem.Kind == kind {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// ReadIgnoreEntries reads every entry of the ignore files matching patterns at rev
+// Entries are returned as additions, so they can be validated and verified
+// like new ones.
+func ReadIgnoreEntries(ctx context.Context, reader RepositoryReader, rev string, patterns []string) ([]DiffChange, error) {
+	if len(patterns) == 0 {
+		patterns = []string{GitleaksIgnorePath}
+	}
+
+	files, err := reader.ListFiles(ctx, rev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files at %s: %w", rev, err)
+	}
+
+	changes := []DiffChange{}
+	for _, file := range files {
+		if !MatchAnyGlob(patterns, file) {
+			continue
+		}
+
+		content, err := reader.ReadBlob(ctx, rev, file)
+		if errors.Is(err, ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at %s: %w", file, rev, err)
+		}
+		changes = append(changes, ParseIgnoreFile(file, content)...)
+	}
+
+	return changes, nil
+}
+
+// ParseIgnoreFile returns every entry of the ignore file at path as an
+// addition, with the metadata from the comment block directly above it
+func ParseIgnoreFile(path string, content []byte) []DiffChange {
+	var changes []DiffChange
+	var comments []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			changes = append(changes, DiffChange{
+				FilePath:   path,
+				Operation:  OperationAddition,
+				LineNumber: lineNum,
+				Content:    line,
+				Metadata:   ParseEntryMetadata(comments),
+			})
+		}
+		comments = trackComments(comments, line)
+	}
+
+	return changes
+}
+
+// Audit reports the entries in changes that have expired, point at files or
+// lines that no longer exist, or suppress no current finding
+// Missing files and lines are only detected if the changes were validated,
+// and unused entries only if they were verified.
+func Audit(revision string, changes []DiffChange) *AuditReport {
+	report := &AuditReport{Revision: revision, Total: len(changes)}
+	today := now()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, change := range changes {
+		var problems []AuditProblem
+
+		if meta := change.Metadata; meta != nil && meta.Expires != "" {
+			expires, err := meta.ExpiryDate()
+			switch {
+			case err != nil:
+				problems = append(problems, AuditProblem{AuditInvalidExpiry,
+					fmt.Sprintf("expiry date `%s` is not in YYYY-MM-DD format", meta.Expires)})
+			case expires.Before(today):
+				problems = append(problems, AuditProblem{AuditExpired,
+					fmt.Sprintf("expired on %s", meta.Expires)})
+			}
+		}
+
+		if v := change.Validation; v != nil {
+			entry, _ := change.Entry()
+			switch {
+			case entry != nil && entry.IsPattern && !v.FileExists:
+				problems = append(problems, AuditProblem{AuditMissingFile, "matches no files"})
+			case !v.FileExists:
+				problems = append(problems, AuditProblem{AuditMissingFile, "file no longer exists"})
+			case !v.LineExists:
+				problems = append(problems, AuditProblem{AuditMissingLine,
+					fmt.Sprintf("file has only %d lines", v.LineCount)})
+			}
+		}
+
+		if v := change.Verification; v != nil {
+			report.Verified = true
+			if !v.Suppresses() {
+				problems = append(problems, AuditProblem{AuditUnused, "matches no current finding"})
+			}
+		}
+
+		if len(problems) > 0 {
+			report.Entries = append(report.Entries, AuditEntry{Change: change, Problems: problems})
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,92 @@
+package diff
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadIgnoreEntries(t *testing.T) {
+	reader := &fakeTreeReader{
+		fakeBlobReader: fakeBlobReader{files: map[string]string{
+			"main:.gitleaksignore": "# test keys\n" +
+				"# reason: fixture; expires: 2026-01-31\n" +
+				"test/key.pem:1\n" +
+				"\n" +
+				"*.env\n",
+			"main:svc/.gitleaksignore": "config.yml:4\n",
+		}},
+		tree: []string{".gitleaksignore", "README.md", "svc/.gitleaksignore"},
+	}
+
+	changes, err := ReadIgnoreEntries(context.Background(), reader, "main", []string{".gitleaksignore", "**/.gitleaksignore"})
+	if err != nil {
+		t.Fatalf("ReadIgnoreEntries() unexpected error: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 entries, got %d: %+v", len(changes), changes)
+	}
+
+	if got := changes[0]; got.LineNumber != 3 || got.Metadata == nil || got.Metadata.Reason != "fixture" {
+		t.Errorf("first entry = %+v, want line 3 with its reason", got)
+	}
+	if got := changes[1]; got.LineNumber != 5 || got.Metadata != nil {
+		t.Errorf("second entry = %+v, want line 5 without metadata", got)
+	}
+	if got := changes[2]; got.FilePath != "svc/.gitleaksignore" || !got.IsAddition() {
+		t.Errorf("third entry = %+v, want an addition from the nested file", got)
+	}
+	if entry, _ := changes[2].Entry(); entry.FilePattern != "svc/config.yml" {
+		t.Errorf("nested entry resolves to %q, want svc/config.yml", entry.FilePattern)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	found := &EntryValidation{FileExists: true, LineExists: true}
+	used := &EntryVerification{Findings: []Finding{{RuleID: "private-key"}}}
+
+	changes := []DiffChange{
+		{Content: "current.pem:1", Metadata: &EntryMetadata{Expires: "2026-10-16"}, Validation: found, Verification: used},
+		{Content: "expired.pem:1", Metadata: &EntryMetadata{Expires: "2026-10-15"}, Validation: found, Verification: used},
+		{Content: "typo.pem:1", Metadata: &EntryMetadata{Expires: "next year"}, Validation: found, Verification: used},
+		{Content: "gone.pem:1", Validation: &EntryValidation{}, Verification: &EntryVerification{}},
+		{Content: "*.tmp", Validation: &EntryValidation{}, Verification: used},
+		{Content: "short.pem:9", Validation: &EntryValidation{FileExists: true, LineCount: 3}, Verification: used},
+		{Content: "unchecked.pem:1"},
+	}
+
+	report := Audit("abc123", changes)
+
+	if report.Total != 7 || !report.Verified {
+		t.Errorf("Total = %d, Verified = %v, want 7 and true", report.Total, report.Verified)
+	}
+
+	want := map[string]string{
+		"expired.pem:1": "expired: expired on 2026-10-15",
+		"typo.pem:1":    "invalid-expiry: expiry date `next year` is not in YYYY-MM-DD format",
+		"gone.pem:1":    "missing-file: file no longer exists, unused: matches no current finding",
+		"*.tmp":         "missing-file: matches no files",
+		"short.pem:9":   "missing-line: file has only 3 lines",
+	}
+	if len(report.Entries) != len(want) {
+		t.Fatalf("expected %d flagged entries, got %d: %+v", len(want), len(report.Entries), report.Entries)
+	}
+	for _, entry := range report.Entries {
+		var problems []string
+		for _, p := range entry.Problems {
+			problems = append(problems, p.Kind+": "+p.Message)
+		}
+		if got := strings.Join(problems, ", "); got != want[entry.Change.Content] {
+			t.Errorf("%s problems = %q, want %q", entry.Change.Content, got, want[entry.Change.Content])
+		}
+	}
+
+	if report.Count(AuditMissingFile) != 2 || report.Count(AuditUnused) != 1 {
+		t.Errorf("Count(missing-file) = %d, Count(unused) = %d, want 2 and 1",
+			report.Count(AuditMissingFile), report.Count(AuditUnused))
+	}
+}
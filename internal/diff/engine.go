@@ -0,0 +1,338 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// DefaultContextLines is the number of unchanged lines shown around each hunk
+const DefaultContextLines = 3
+
+// editKind represents a single step in an edit script
+type editKind int
+
+const (
+	editEqual editKind = iota
+	editDelete
+	editInsert
+)
+
+// edit is one line-level operation produced by myersDiff
+type edit struct {
+	kind editKind
+
+	// oldPos and newPos are the 0-indexed positions in the old and new
+	// line slices at which this edit applies
+	oldPos int
+	newPos int
+
+	// text is the line content (without trailing newline)
+	text string
+}
+
+// UnifiedDiff computes a unified diff between two versions of a file.
+// The output has the same shape as `git diff` for a single file, so it can be
+// fed directly into parseDiffOutput. A nil oldContent or newContent denotes
+// a file that does not exist on that side.
+// Returns nil when both versions are identical.
+func UnifiedDiff(path string, oldContent, newContent []byte, contextLines int) []byte {
+	oldLines := splitLines(oldContent)
+	newLines := splitLines(newContent)
+
+	edits := myersDiff(oldLines, newLines)
+	hunks := groupHunks(edits, contextLines)
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "diff --git a/%s b/%s\n", path, path)
+	if oldContent == nil {
+		buf.WriteString("--- /dev/null\n")
+	} else {
+		fmt.Fprintf(&buf, "--- a/%s\n", path)
+	}
+	if newContent == nil {
+		buf.WriteString("+++ /dev/null\n")
+	} else {
+		fmt.Fprintf(&buf, "+++ b/%s\n", path)
+	}
+
+	for _, h := range hunks {
+		writeHunk(&buf, h)
+	}
+
+	return buf.Bytes()
+}
+
+// splitLines splits content into lines, dropping the trailing newline
+// Differences in the final newline are intentionally ignored
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	return strings.Split(text, "\n")
+}
+
+// myersDiff computes the shortest edit script between a and b using
+// Myers' O(ND) algorithm in its linear-space form: the middle snake of the
+// optimal path is found by searching from both ends, then the halves before
+// and after it are diffed recursively. Memory is O(N+M) regardless of how
+// far apart the versions are. Within each run of changes, deletions are
+// listed before insertions, as git does.
+func myersDiff(a, b []string) []edit {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+
+	maxD := (len(a)+len(b)+1)/2 + 1
+	d := &differ{
+		a:      a,
+		b:      b,
+		offset: maxD,
+		vf:     make([]int, 2*maxD+1),
+		vb:     make([]int, 2*maxD+1),
+	}
+	d.compare(0, len(a), 0, len(b))
+	return groupChanges(d.edits)
+}
+
+// differ holds the state of a linear-space Myers diff
+type differ struct {
+	a, b  []string
+	edits []edit
+
+	// vf and vb hold the furthest reaching x of the forward and backward
+	// searches per diagonal, indexed by diagonal + offset; they are shared
+	// by every recursive call
+	offset int
+	vf, vb []int
+}
+
+// compare appends the edit script turning a[aLo:aHi] into b[bLo:bHi]
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	// Common prefix and suffix need no search
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.edits = append(d.edits, edit{kind: editEqual, oldPos: aLo, newPos: bLo, text: d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-1-suffix] == d.b[bHi-1-suffix] {
+		suffix++
+	}
+	aEnd, bEnd := aHi-suffix, bHi-suffix
+
+	switch {
+	case aLo == aEnd:
+		for y := bLo; y < bEnd; y++ {
+			d.edits = append(d.edits, edit{kind: editInsert, oldPos: aLo, newPos: y, text: d.b[y]})
+		}
+	case bLo == bEnd:
+		for x := aLo; x < aEnd; x++ {
+			d.edits = append(d.edits, edit{kind: editDelete, oldPos: x, newPos: bLo, text: d.a[x]})
+		}
+	default:
+		// Both sides are non-empty and differ at both ends, so the edit
+		// distance is at least 2 and each half is strictly smaller
+		x, y, u, v := d.middleSnake(aLo, aEnd, bLo, bEnd)
+		d.compare(aLo, x, bLo, y)
+		for ; x < u; x, y = x+1, y+1 {
+			d.edits = append(d.edits, edit{kind: editEqual, oldPos: x, newPos: y, text: d.a[x]})
+		}
+		d.compare(u, aEnd, v, bEnd)
+	}
+
+	for i := range suffix {
+		x, y := aEnd+i, bEnd+i
+		d.edits = append(d.edits, edit{kind: editEqual, oldPos: x, newPos: y, text: d.a[x]})
+	}
+}
+
+// middleSnake returns the start (x, y) and end (u, v) of the middle snake of
+// an optimal path from (aLo, bLo) to (aHi, bHi)
+// The backward search runs on the reversed sequences: its x counts lines
+// from the end, and its diagonal delta-k mirrors the forward diagonal k.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	off := d.offset
+	d.vf[off+1] = 0
+	d.vb[off+1] = 0
+
+	for depth := 0; depth <= (n+m+1)/2; depth++ {
+		for k := -depth; k <= depth; k += 2 {
+			var x int
+			if k == -depth || (k != depth && d.vf[off+k-1] < d.vf[off+k+1]) {
+				x = d.vf[off+k+1]
+			} else {
+				x = d.vf[off+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			d.vf[off+k] = x
+
+			if kb := delta - k; odd && kb >= -(depth-1) && kb <= depth-1 && x+d.vb[off+kb] >= n {
+				return aLo + x0, bLo + y0, aLo + x, bLo + y
+			}
+		}
+
+		for kb := -depth; kb <= depth; kb += 2 {
+			var x int
+			if kb == -depth || (kb != depth && d.vb[off+kb-1] < d.vb[off+kb+1]) {
+				x = d.vb[off+kb+1]
+			} else {
+				x = d.vb[off+kb-1] + 1
+			}
+			y := x - kb
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			d.vb[off+kb] = x
+
+			if k := delta - kb; !odd && k >= -depth && k <= depth && x+d.vf[off+k] >= n {
+				return aHi - x, bHi - y, aHi - x0, bHi - y0
+			}
+		}
+	}
+
+	// Unreachable: the searches always meet within half the edit distance
+	panic("diff: middle snake not found")
+}
+
+// groupChanges reorders each run of deletions and insertions so that the
+// deletions come first, matching git's output
+func groupChanges(edits []edit) []edit {
+	grouped := make([]edit, 0, len(edits))
+	for i := 0; i < len(edits); {
+		if edits[i].kind == editEqual {
+			grouped = append(grouped, edits[i])
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(edits) && edits[j].kind != editEqual {
+			j++
+		}
+		oldStart, newStart := edits[i].oldPos, edits[i].newPos
+		oldPos, newPos := oldStart, newStart
+		for _, e := range edits[i:j] {
+			if e.kind == editDelete {
+				grouped = append(grouped, edit{kind: editDelete, oldPos: oldPos, newPos: newStart, text: e.text})
+				oldPos++
+			}
+		}
+		for _, e := range edits[i:j] {
+			if e.kind == editInsert {
+				grouped = append(grouped, edit{kind: editInsert, oldPos: oldPos, newPos: newPos, text: e.text})
+				newPos++
+			}
+		}
+		i = j
+	}
+	return grouped
+}
+
+// hunk is a contiguous slice of the edit script with surrounding context
+type hunk struct {
+	edits []edit
+}
+
+// groupHunks splits an edit script into hunks, keeping contextLines unchanged
+// lines around each change and merging hunks whose context would overlap
+func groupHunks(edits []edit, contextLines int) []hunk {
+	if contextLines < 0 {
+		contextLines = 0
+	}
+
+	var hunks []hunk
+	start, end := -1, -1
+
+	for i, e := range edits {
+		if e.kind == editEqual {
+			continue
+		}
+
+		lo := i - contextLines
+		if lo < 0 {
+			lo = 0
+		}
+		hi := i + contextLines + 1
+		if hi > len(edits) {
+			hi = len(edits)
+		}
+
+		if start == -1 {
+			start, end = lo, hi
+			continue
+		}
+
+		if lo <= end {
+			// Overlaps with the current hunk, extend it
+			end = hi
+			continue
+		}
+
+		hunks = append(hunks, hunk{edits: edits[start:end]})
+		start, end = lo, hi
+	}
+
+	if start != -1 {
+		hunks = append(hunks, hunk{edits: edits[start:end]})
+	}
+
+	return hunks
+}
+
+// writeHunk writes a single hunk (header and lines) in unified format
+func writeHunk(buf *bytes.Buffer, h hunk) {
+	first := h.edits[0]
+	oldCount, newCount := 0, 0
+	for _, e := range h.edits {
+		switch e.kind {
+		case editEqual:
+			oldCount++
+			newCount++
+		case editDelete:
+			oldCount++
+		case editInsert:
+			newCount++
+		}
+	}
+
+	// Following git's convention, an empty range starts at the line before it
+	oldStart := first.oldPos + 1
+	if oldCount == 0 {
+		oldStart = first.oldPos
+	}
+	newStart := first.newPos + 1
+	if newCount == 0 {
+		newStart = first.newPos
+	}
+
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+
+	for _, e := range h.edits {
+		switch e.kind {
+		case editEqual:
+			buf.WriteString(" ")
+		case editDelete:
+			buf.WriteString("-")
+		case editInsert:
+			buf.WriteString("+")
+		}
+		buf.WriteString(e.text)
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,227 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "identical content",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			name: "single addition at end",
+			old:  "a\nb\n",
+			new:  "a\nb\nc\n",
+			expected: "@@ -1,2 +1,3 @@\n" +
+				" a\n" +
+				" b\n" +
+				"+c\n",
+		},
+		{
+			name: "single deletion in middle",
+			old:  "a\nb\nc\n",
+			new:  "a\nc\n",
+			expected: "@@ -1,3 +1,2 @@\n" +
+				" a\n" +
+				"-b\n" +
+				" c\n",
+		},
+		{
+			name: "replacement",
+			old:  "a\nb\nc\n",
+			new:  "a\nx\nc\n",
+			expected: "@@ -1,3 +1,3 @@\n" +
+				" a\n" +
+				"-b\n" +
+				"+x\n" +
+				" c\n",
+		},
+		{
+			name: "distant changes produce separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			new:  "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			expected: "@@ -1,3 +1,4 @@\n" +
+				"+0\n" +
+				" 1\n" +
+				" 2\n" +
+				" 3\n" +
+				"@@ -7,4 +8,3 @@\n" +
+				" 7\n" +
+				" 8\n" +
+				" 9\n" +
+				"-10\n",
+		},
+		{
+			name: "new file",
+			old:  "",
+			new:  "a\n",
+			expected: "@@ -0,0 +1,1 @@\n" +
+				"+a\n",
+		},
+		{
+			name:     "trailing newline difference is ignored",
+			old:      "a\nb",
+			new:      "a\nb\n",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := string(UnifiedDiff("file.txt", []byte(tt.old), []byte(tt.new), DefaultContextLines))
+
+			if tt.expected == "" {
+				if out != "" {
+					t.Errorf("UnifiedDiff() = %q, want empty", out)
+				}
+				return
+			}
+
+			// Strip file headers, compare hunks only
+			idx := strings.Index(out, "@@")
+			if idx == -1 {
+				t.Fatalf("UnifiedDiff() produced no hunks: %q", out)
+			}
+			if got := out[idx:]; got != tt.expected {
+				t.Errorf("UnifiedDiff() hunks =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff_FileHeaders(t *testing.T) {
+	out := string(UnifiedDiff(".gitleaksignore", nil, []byte("a\n"), DefaultContextLines))
+	if !strings.Contains(out, "--- /dev/null\n+++ b/.gitleaksignore\n") {
+		t.Errorf("UnifiedDiff() for new file should use /dev/null as old side: %q", out)
+	}
+
+	out = string(UnifiedDiff(".gitleaksignore", []byte("a\n"), nil, DefaultContextLines))
+	if !strings.Contains(out, "--- a/.gitleaksignore\n+++ /dev/null\n") {
+		t.Errorf("UnifiedDiff() for deleted file should use /dev/null as new side: %q", out)
+	}
+}
+
+func TestUnifiedDiff_RoundTripThroughParser(t *testing.T) {
+	old := "# comment\nconfig/a.yml:12\ndatabase/*.env\nkeep.txt\n"
+	new := "# comment\nconfig/b.yml:3\nkeep.txt\nsecrets/new.key\n"
+
+	changes, err := parseDiffOutput(".gitleaksignore", UnifiedDiff(".gitleaksignore", []byte(old), []byte(new), DefaultContextLines))
+	if err != nil {
+		t.Fatalf("parseDiffOutput() unexpected error: %v", err)
+	}
+
+	want := []struct {
+		op      OperationType
+		content string
+		line    int
+	}{
+		{OperationDeletion, "config/a.yml:12", 0},
+		{OperationDeletion, "database/*.env", 0},
+		{OperationAddition, "config/b.yml:3", 2},
+		{OperationAddition, "secrets/new.key", 4},
+	}
+
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for i, w := range want {
+		if changes[i].Operation != w.op || changes[i].Content != w.content || changes[i].LineNumber != w.line {
+			t.Errorf("change %d = {%s %q line %d}, want {%s %q line %d}",
+				i, changes[i].Operation, changes[i].Content, changes[i].LineNumber, w.op, w.content, w.line)
+		}
+	}
+}
+
+// TestMyersDiff_Minimal tests that edit scripts reproduce both versions with
+// the fewest changes, against a quadratic LCS on random inputs
+func TestMyersDiff_Minimal(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	randomLines := func() []string {
+		lines := make([]string, rng.IntN(12))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.IntN(3)))
+		}
+		return lines
+	}
+
+	for range 2000 {
+		a, b := randomLines(), randomLines()
+		edits := myersDiff(a, b)
+
+		var gotA, gotB []string
+		changes := 0
+		for _, e := range edits {
+			switch e.kind {
+			case editEqual:
+				gotA, gotB = append(gotA, e.text), append(gotB, e.text)
+			case editDelete:
+				gotA = append(gotA, e.text)
+				changes++
+			case editInsert:
+				gotB = append(gotB, e.text)
+				changes++
+			}
+		}
+		if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+			t.Fatalf("myersDiff(%q, %q) = %+v does not reproduce both versions", a, b, edits)
+		}
+		if want := len(a) + len(b) - 2*lcsLength(a, b); changes != want {
+			t.Fatalf("myersDiff(%q, %q) has %d changes, want %d", a, b, changes, want)
+		}
+	}
+}
+
+// TestMyersDiff_LinearMemory tests that a rewritten file is diffed without
+// memory growing with the edit distance
+func TestMyersDiff_LinearMemory(t *testing.T) {
+	a := make([]string, 3000)
+	b := make([]string, 3000)
+	for i := range a {
+		a[i] = fmt.Sprintf("old line %d", i)
+		b[i] = fmt.Sprintf("new line %d", i)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	edits := myersDiff(a, b)
+	runtime.ReadMemStats(&after)
+
+	if len(edits) != 6000 {
+		t.Errorf("myersDiff() returned %d edits, want 6000", len(edits))
+	}
+	// A trace of the whole search would take 6000 copies of 6000 diagonals
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16<<20 {
+		t.Errorf("myersDiff() allocated %d bytes, want linear memory", allocated)
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
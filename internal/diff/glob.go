@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether name matches pattern
+// Patterns are matched against the full slash-separated path. Within a path
+// segment, *, ? and [...] behave as in path.Match; a "**" segment matches zero
+// or more whole segments, as in .gitignore.
+//
+// Examples:
+//
+//	.gitleaksignore              matches only the root file
+//	**/.gitleaksignore           matches the root file and any nested one
+//	services/*/.gitleaksignore   matches services/payments/.gitleaksignore
+func MatchGlob(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	name = strings.TrimPrefix(name, "/")
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// ValidateGlob returns an error if pattern is not a valid glob
+func ValidateGlob(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("empty glob pattern")
+	}
+	for _, segment := range strings.Split(strings.TrimPrefix(pattern, "/"), "/") {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// MatchAnyGlob reports whether name matches at least one of patterns
+func MatchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchEntryPattern reports whether name matches a wildcard .gitleaksignore entry
+// As in .gitignore, a pattern without a slash (e.g. *.env) matches at any depth;
+// otherwise it is anchored like MatchGlob.
+func MatchEntryPattern(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return MatchGlob(pattern, name)
+}
+
+// isWildcard reports whether pattern contains glob metacharacters
+func isWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[]")
+}
+
+// matchSegments matches path segments, expanding ** recursively
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive ** segments
+			rest := pattern[1:]
+			for len(rest) > 0 && rest[0] == "**" {
+				rest = rest[1:]
+			}
+			if len(rest) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		matched, err := path.Match(pattern[0], name[0])
+		if err != nil || !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
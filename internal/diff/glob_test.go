@@ -0,0 +1,64 @@
+package diff
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{".gitleaksignore", ".gitleaksignore", true},
+		{".gitleaksignore", "services/api/.gitleaksignore", false},
+		{"**/.gitleaksignore", ".gitleaksignore", true},
+		{"**/.gitleaksignore", "services/api/.gitleaksignore", true},
+		{"**/.gitleaksignore", "services/api/.gitleaksignore.bak", false},
+		{"services/*/.gitleaksignore", "services/payments/.gitleaksignore", true},
+		{"services/*/.gitleaksignore", "services/payments/v2/.gitleaksignore", false},
+		{"services/**/.gitleaksignore", "services/payments/v2/.gitleaksignore", true},
+		{"services/**", "services/payments/.gitleaksignore", true},
+		{"/.gitleaksignore", ".gitleaksignore", true},
+		{"**/.gitleaks*", "tools/.gitleaksignore", true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	for _, pattern := range []string{".gitleaksignore", "**/.gitleaksignore", "services/[a-z]*/.gitleaksignore"} {
+		if err := ValidateGlob(pattern); err != nil {
+			t.Errorf("ValidateGlob(%q) unexpected error: %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"", "  ", "services/[/.gitleaksignore"} {
+		if err := ValidateGlob(pattern); err == nil {
+			t.Errorf("ValidateGlob(%q) expected error", pattern)
+		}
+	}
+}
+
+func TestMatchEntryPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"database/*.env", "database/prod.env", true},
+		{"database/*.env", "database/old/prod.env", false},
+		{"database/*.env", "svc/database/prod.env", false},
+		{"*.env", "prod.env", true},
+		{"*.env", "svc/config/prod.env", true},
+		{"/*.env", "svc/prod.env", false},
+		{"config/**/*.pem", "config/certs/dev/key.pem", true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchEntryPattern(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchEntryPattern(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
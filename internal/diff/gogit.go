@@ -0,0 +1,157 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// GoGitReader reads objects directly from the .git directory without
+// shelling out, using the pure-Go go-git implementation
+type GoGitReader struct {
+	repo *git.Repository
+}
+
+// NewGoGitReader opens the repository containing dir
+func NewGoGitReader(dir string) (*GoGitReader, error) {
+	if dir == "" {
+		dir = "."
+	}
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", dir, err)
+	}
+	return &GoGitReader{repo: repo}, nil
+}
+
+// ResolveCommit resolves rev to a full commit SHA
+func (r *GoGitReader) ResolveCommit(ctx context.Context, rev string) (string, error) {
+	commit, err := r.commit(rev)
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String(), nil
+}
+
+// MergeBase returns the first merge base of a and b
+func (r *GoGitReader) MergeBase(ctx context.Context, a, b string) (string, error) {
+	ca, err := r.commit(a)
+	if err != nil {
+		return "", err
+	}
+	cb, err := r.commit(b)
+	if err != nil {
+		return "", err
+	}
+
+	bases, err := ca.MergeBase(cb)
+	if err != nil {
+		return "", fmt.Errorf("cannot find merge base of %s and %s: %w", a, b, err)
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("cannot find merge base of %s and %s: no common ancestor", a, b)
+	}
+	return bases[0].Hash.String(), nil
+}
+
+// ChangedFiles lists paths that differ between the trees of base and head
+func (r *GoGitReader) ChangedFiles(ctx context.Context, base, head string) ([]string, error) {
+	baseTree, err := r.tree(base)
+	if err != nil {
+		return nil, err
+	}
+	headTree, err := r.tree(head)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTreeContext(ctx, baseTree, headTree)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list files changed in %s..%s: %w", base, head, err)
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				files = append(files, name)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ListFiles lists all files in the tree at rev
+func (r *GoGitReader) ListFiles(ctx context.Context, rev string) ([]string, error) {
+	tree, err := r.tree(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = tree.Files().ForEach(func(f *object.File) error {
+		files = append(files, f.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list files at %s: %w", rev, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ReadBlob returns the contents of path at rev
+func (r *GoGitReader) ReadBlob(ctx context.Context, rev, path string) ([]byte, error) {
+	commit, err := r.commit(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := commit.File(path)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("cannot read %s:%s: %w", rev, path, err)
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s:%s: %w", rev, path, err)
+	}
+	return []byte(contents), nil
+}
+
+// tree loads the root tree of the commit at rev
+func (r *GoGitReader) tree(rev string) (*object.Tree, error) {
+	commit, err := r.commit(rev)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load tree for %s: %w", rev, err)
+	}
+	return tree, nil
+}
+
+// commit resolves rev and loads the commit object
+func (r *GoGitReader) commit(rev string) (*object.Commit, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve commit %q: %w", rev, err)
+	}
+	commit, err := r.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load commit %s: %w", hash, err)
+	}
+	return commit, nil
+}
@@ -0,0 +1,157 @@
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// InlineAllowMarker is the annotation gitleaks honors to skip a single line
+// gitleaks matches it anywhere on the line, case-sensitively.
+const InlineAllowMarker = "gitleaks:allow"
+
+// InlineAllow is an added source line carrying a gitleaks:allow annotation
+type InlineAllow struct {
+	// FilePath is the path of the annotated file
+	FilePath string `json:"file_path"`
+
+	// LineNumber is the line in the new version of the file
+	LineNumber int `json:"line_number"`
+
+	// Content is the added line, without the leading "+"
+	Content string `json:"content"`
+}
+
+// maxInlineDiffLines caps the combined line count of a file's two versions
+// that ParseInlineAllows diffs. Diffing takes time proportional to the file
+// size times the number of changed lines, so larger files are matched with
+// scanAddedLines instead.
+const maxInlineDiffLines = 20000
+
+// hunkHeaderRegex parses hunk headers: @@ -old_start,old_count +new_start,new_count @@
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+),?(\d*) \+(\d+),?(\d*) @@`)
+
+// ScanInlineAllows finds added lines containing gitleaks:allow in a unified diff patch for path
+// Unlike parseDiffOutput, every added line is considered, including comments.
+func ScanInlineAllows(path string, patch []byte) ([]InlineAllow, error) {
+	allows := []InlineAllow{}
+	if !bytes.Contains(patch, []byte(InlineAllowMarker)) {
+		return allows, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(patch))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	inHunk := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if matches := hunkHeaderRegex.FindStringSubmatch(line); matches != nil {
+			lineNum, _ = strconv.Atoi(matches[3])
+			inHunk = true
+			continue
+		}
+		if !inHunk || strings.HasPrefix(line, "\\") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+"):
+			content := line[1:]
+			if strings.Contains(content, InlineAllowMarker) {
+				allows = append(allows, InlineAllow{
+					FilePath:   path,
+					LineNumber: lineNum,
+					Content:    content,
+				})
+			}
+			lineNum++
+		case strings.HasPrefix(line, "-"):
+			// Deleted lines do not exist in the new file
+		case strings.HasPrefix(line, "diff --git"):
+			inHunk = false
+		default:
+			lineNum++
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error scanning diff for %s: %w", path, err)
+	}
+
+	return allows, nil
+}
+
+// ParseInlineAllows finds gitleaks:allow annotations added anywhere in the range
+// Binary files and files whose head version lacks the annotation are skipped
+// without being diffed. Files over maxInlineDiffLines are not diffed either:
+// their annotated lines are compared with the base version as a whole.
+func ParseInlineAllows(ctx context.Context, reader ObjectReader, rng Range) ([]InlineAllow, error) {
+	files, err := reader.ChangedFiles(ctx, rng.Base, rng.Head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files in range %s: %w", rng, err)
+	}
+
+	allows := []InlineAllow{}
+	for _, file := range files {
+		newContent, err := readOptionalBlob(ctx, reader, rng.Head, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at head of range %s: %w", file, rng, err)
+		}
+		if !bytes.Contains(newContent, []byte(InlineAllowMarker)) || bytes.IndexByte(newContent, 0) >= 0 {
+			continue
+		}
+
+		oldContent, err := readOptionalBlob(ctx, reader, rng.Base, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at base of range %s: %w", file, rng, err)
+		}
+
+		if bytes.Count(oldContent, []byte("\n"))+bytes.Count(newContent, []byte("\n")) > maxInlineDiffLines {
+			allows = append(allows, scanAddedLines(file, oldContent, newContent)...)
+			continue
+		}
+
+		fileAllows, err := ScanInlineAllows(file, UnifiedDiff(file, oldContent, newContent, 0))
+		if err != nil {
+			return nil, err
+		}
+		allows = append(allows, fileAllows...)
+	}
+
+	return allows, nil
+}
+
+// scanAddedLines finds annotated lines of newContent that oldContent has fewer copies of
+// It runs in linear time but, unlike a diff, does not notice an annotated
+// line that was moved, so it is only used for files too large to diff.
+func scanAddedLines(path string, oldContent, newContent []byte) []InlineAllow {
+	oldCounts := make(map[string]int)
+	for _, line := range splitLines(oldContent) {
+		if strings.Contains(line, InlineAllowMarker) {
+			oldCounts[line]++
+		}
+	}
+
+	allows := []InlineAllow{}
+	for i, line := range splitLines(newContent) {
+		if !strings.Contains(line, InlineAllowMarker) {
+			continue
+		}
+		if oldCounts[line] > 0 {
+			oldCounts[line]--
+			continue
+		}
+		allows = append(allows, InlineAllow{
+			FilePath:   path,
+			LineNumber: i + 1,
+			Content:    line,
+		})
+	}
+	return allows
+}
@@ -0,0 +1,121 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestScanInlineAllows(t *testing.T) {
+	patch := `diff --git a/app/config.py b/app/config.py
+--- a/app/config.py
++++ b/app/config.py
+@@ -1,4 +1,5 @@
+ import os
+-API_KEY = os.environ["API_KEY"]
++API_KEY = "sk-live-1234"  # gitleaks:allow
++# gitleaks:allow on a comment line
+ DEBUG = False
+@@ -10,2 +11,3 @@ def main():
+     run()
++    token = "abc"  # GITLEAKS:ALLOW is not honored by gitleaks
++    other = "xyz"  #gitleaks:allow
+\ No newline at end of file`
+
+	allows, err := ScanInlineAllows("app/config.py", []byte(patch))
+	if err != nil {
+		t.Fatalf("ScanInlineAllows() unexpected error: %v", err)
+	}
+
+	want := []int{2, 3, 13}
+	if len(allows) != len(want) {
+		t.Fatalf("expected %d annotations, got %d: %+v", len(want), len(allows), allows)
+	}
+	for i, line := range want {
+		if allows[i].LineNumber != line || allows[i].FilePath != "app/config.py" {
+			t.Errorf("allows[%d] = %+v, want app/config.py:%d", i, allows[i], line)
+		}
+	}
+	if allows[0].Content != `API_KEY = "sk-live-1234"  # gitleaks:allow` {
+		t.Errorf("allows[0].Content = %q", allows[0].Content)
+	}
+}
+
+func TestScanInlineAllows_RemovedAnnotation(t *testing.T) {
+	patch := "@@ -1,2 +1,2 @@\n-key = \"a\" # gitleaks:allow\n+key = \"a\"\n ok\n"
+
+	allows, err := ScanInlineAllows("main.go", []byte(patch))
+	if err != nil {
+		t.Fatalf("ScanInlineAllows() unexpected error: %v", err)
+	}
+	if len(allows) != 0 {
+		t.Errorf("removed annotations should not be reported, got %+v", allows)
+	}
+}
+
+func TestParseInlineAllows(t *testing.T) {
+	repo := newTestRepo(t)
+	base := repo.commit(map[string]string{
+		"main.go":   "package main\n\nvar old = \"x\" // gitleaks:allow\n",
+		"README.md": "hello\n",
+	})
+	head := repo.commit(map[string]string{
+		"main.go":       "package main\n\nvar old = \"x\" // gitleaks:allow\nvar key = \"y\" // gitleaks:allow\n",
+		"README.md":     "hello\nworld\n",
+		"svc/secret.py": "TOKEN = 'z'  # gitleaks:allow\n",
+	})
+
+	for _, backend := range []string{BackendGitCLI, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			reader, err := NewObjectReader(backend, repo.dir)
+			if err != nil {
+				t.Fatalf("NewObjectReader() unexpected error: %v", err)
+			}
+
+			allows, err := ParseInlineAllows(context.Background(), reader, Range{Base: base, Head: head})
+			if err != nil {
+				t.Fatalf("ParseInlineAllows() unexpected error: %v", err)
+			}
+
+			if len(allows) != 2 {
+				t.Fatalf("expected 2 annotations, got %d: %+v", len(allows), allows)
+			}
+			if allows[0].FilePath != "main.go" || allows[0].LineNumber != 4 {
+				t.Errorf("allows[0] = %+v, want main.go:4", allows[0])
+			}
+			if allows[1].FilePath != "svc/secret.py" || allows[1].LineNumber != 1 {
+				t.Errorf("allows[1] = %+v, want svc/secret.py:1", allows[1])
+			}
+		})
+	}
+}
+
+func TestParseInlineAllows_LargeFile(t *testing.T) {
+	var oldBuf, newBuf strings.Builder
+	for i := range maxInlineDiffLines / 2 {
+		fmt.Fprintf(&oldBuf, "old line %d\n", i)
+		fmt.Fprintf(&newBuf, "new line %d\n", i)
+	}
+	oldBuf.WriteString("kept = 'x'  # gitleaks:allow\n")
+	newBuf.WriteString("added = 'y'  # gitleaks:allow\nkept = 'x'  # gitleaks:allow\n")
+
+	repo := newTestRepo(t)
+	base := repo.commit(map[string]string{"big.py": oldBuf.String()})
+	head := repo.commit(map[string]string{"big.py": newBuf.String()})
+
+	reader, err := NewObjectReader(BackendGitCLI, repo.dir)
+	if err != nil {
+		t.Fatalf("NewObjectReader() unexpected error: %v", err)
+	}
+
+	allows, err := ParseInlineAllows(context.Background(), reader, Range{Base: base, Head: head})
+	if err != nil {
+		t.Fatalf("ParseInlineAllows() unexpected error: %v", err)
+	}
+
+	want := InlineAllow{FilePath: "big.py", LineNumber: maxInlineDiffLines/2 + 1, Content: "added = 'y'  # gitleaks:allow"}
+	if len(allows) != 1 || allows[0] != want {
+		t.Errorf("ParseInlineAllows() = %+v, want [%+v]", allows, want)
+	}
+}
@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"strings"
+	"time"
+)
+
+// ExpiryLayout is the date format of the expires metadata field
+const ExpiryLayout = "2006-01-02"
+
+// EntryMetadata is the justification recorded in the comment block directly
+// above an entry, e.g.
+//
+//	# reason: test fixture; ticket: SEC-123; expires: 2027-01-31
+//	tests/fixtures/key.pem:private-key:1
+//
+// Fields may also be given one per line. Unknown keys and free text are ignored.
+type EntryMetadata struct {
+	Reason string `json:"reason,omitempty"`
+	Ticket string `json:"ticket,omitempty"`
+
+	// Expires is the expiry date as written; see ExpiryDate
+	Expires string `json:"expires,omitempty"`
+}
+
+// ParseEntryMetadata parses the comment lines above an entry, with or without
+// their "#" prefix. It returns nil if no field is set.
+func ParseEntryMetadata(lines []string) *EntryMetadata {
+	meta := &EntryMetadata{}
+	for _, line := range lines {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+		for _, field := range strings.Split(line, ";") {
+			key, value, ok := strings.Cut(field, ":")
+			if !ok {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "reason":
+				meta.Reason = value
+			case "ticket":
+				meta.Ticket = value
+			case "expires":
+				meta.Expires = value
+			}
+		}
+	}
+
+	if *meta == (EntryMetadata{}) {
+		return nil
+	}
+	return meta
+}
+
+// AttachMetadata sets the metadata of the added and modified entries in
+// changes from the comment block directly above their line in content, the
+// ignore file at the head commit. A diff only carries a few lines of context,
+// so a justification further above a modified entry is not in it. Changes
+// whose line does not hold their entry in content keep their metadata.
+func AttachMetadata(changes []DiffChange, content []byte) {
+	lines := splitLines(content)
+	for i := range changes {
+		change := &changes[i]
+		if change.IsDeletion() || change.LineNumber <= 0 || change.LineNumber > len(lines) {
+			continue
+		}
+		if strings.TrimSpace(lines[change.LineNumber-1]) != change.Content {
+			continue
+		}
+		change.Metadata = ParseEntryMetadata(commentsAbove(lines, change.LineNumber))
+	}
+}
+
+// commentsAbove returns the comment lines directly above the 1-indexed line
+func commentsAbove(lines []string, line int) []string {
+	start := line - 1
+	for start > 0 && strings.HasPrefix(strings.TrimSpace(lines[start-1]), "#") {
+		start--
+	}
+	return lines[start : line-1]
+}
+
+// ExpiryDate parses Expires as YYYY-MM-DD
+func (m *EntryMetadata) ExpiryDate() (time.Time, error) {
+	return time.Parse(ExpiryLayout, m.Expires)
+}
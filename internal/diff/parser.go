@@ -3,146 +3,125 @@ package diff
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
-// ParseGitleaksDiff parses git diff output for .gitleaksignore
-func ParseGitleaksDiff(baseBranch, headRef string) ([]DiffChange, error) {
-	// Check if .gitleaksignore file exists in either HEAD or working directory
-	// This handles cases where the file is newly added
-	checkCmd := exec.Command("git", "ls-files", ".gitleaksignore")
-	checkOutput, _ := checkCmd.Output()
-
-	// Also check working directory
-	workingDirCmd := exec.Command("ls", "-la", ".gitleaksignore")
-	workingDirOutput, _ := workingDirCmd.Output()
-
-	fmt.Printf("DEBUG: git ls-files .gitleaksignore: %q\n", string(checkOutput))
-	fmt.Printf("DEBUG: ls -la .gitleaksignore: %q\n", string(workingDirOutput))
-
-	// If file doesn't exist in git and not in working dir, skip
-	if len(checkOutput) == 0 && len(workingDirOutput) == 0 {
-		fmt.Printf("DEBUG: .gitleaksignore not found in git or working directory\n")
-		// Don't return early - the file might exist in the diff even if not in current HEAD
-		// This happens when a file is added in a PR
-	} else {
-		fmt.Printf("DEBUG: .gitleaksignore detected (git tracked: %v, in working dir: %v)\n",
-			len(checkOutput) > 0, len(workingDirOutput) > 0)
-	}
-
-	// Build list of diff strategies to try
-	var strategies [][]string
-
-	// Try to use merge-base to find common ancestor
-	if baseBranch != "" {
-		// Try finding merge-base with origin/base
-		mergeBaseCmd := exec.Command("git", "merge-base", "origin/"+baseBranch, "HEAD")
-		if mergeBase, err := mergeBaseCmd.Output(); err == nil && len(mergeBase) > 0 {
-			baseCommit := strings.TrimSpace(string(mergeBase))
-			strategies = append(strategies, []string{"diff", baseCommit + "..HEAD", "--", ".gitleaksignore"})
-		}
-
-		// Standard PR strategies
-		strategies = append(strategies, []string{"diff", "origin/" + baseBranch + "..HEAD", "--", ".gitleaksignore"})
-		strategies = append(strategies, []string{"diff", "origin/" + baseBranch + "...HEAD", "--", ".gitleaksignore"})
-	}
+// GitleaksIgnorePath is the path of the gitleaks ignore file in the repository
+const GitleaksIgnorePath = ".gitleaksignore"
 
-	// Try with FETCH_HEAD (GitHub Actions sets this)
-	strategies = append(strategies, []string{"diff", "FETCH_HEAD..HEAD", "--", ".gitleaksignore"})
+// Range identifies the two commits being compared
+type Range struct {
+	// Base is the commit the pull request is compared against
+	Base string
 
-	// Try refs/remotes/origin/main pattern
-	if baseBranch != "" {
-		strategies = append(strategies, []string{"diff", "refs/remotes/origin/" + baseBranch + "..HEAD", "--", ".gitleaksignore"})
-	}
+	// Head is the pull request head commit
+	Head string
+}
 
-	// Single commit strategies
-	strategies = append(strategies, []string{"diff", "HEAD~1..HEAD", "--", ".gitleaksignore"})
-	strategies = append(strategies, []string{"diff", "HEAD~1", "HEAD", "--", ".gitleaksignore"})
+// String returns the range in git notation (base..head)
+func (r Range) String() string {
+	return r.Base + ".." + r.Head
+}
 
-	// Use git log -p as a fallback (shows full history with diffs)
-	strategies = append(strategies, []string{"log", "-p", "-1", "--", ".gitleaksignore"})
+// ResolveRange resolves the base and head commits to full SHAs
+// The range starts at the merge base of the base revision and head, as in
+// GitHub's pull request diff, so commits the base branch gained after the
+// branch point are not reported as changes. The base revision is baseSHA, or
+// origin/{baseRef} if baseSHA is empty. The returned error always names the
+// range that was attempted.
+func ResolveRange(ctx context.Context, reader ObjectReader, baseSHA, baseRef, headSHA string) (Range, error) {
+	if headSHA == "" {
+		return Range{}, errors.New("head commit SHA is required")
+	}
 
-	var lastErr error
-	var lastOutput []byte
-	var successCount int
+	head, err := reader.ResolveCommit(ctx, headSHA)
+	if err != nil {
+		return Range{}, fmt.Errorf("failed to resolve head of range %s..%s: %w", baseSHA, headSHA, err)
+	}
 
-	fmt.Printf("DEBUG: Trying %d strategies for base=%s, head=%s\n", len(strategies), baseBranch, headRef)
+	baseRev := baseSHA
+	if baseRev == "" {
+		if baseRef == "" {
+			return Range{}, fmt.Errorf("base commit SHA or base branch is required to diff against %s", head)
+		}
+		baseRev = "origin/" + baseRef
+	}
 
-	for i, args := range strategies {
-		cmd := exec.Command("git", args...)
-		output, err := cmd.CombinedOutput()
+	base, err := reader.MergeBase(ctx, baseRev, head)
+	if err != nil {
+		return Range{}, fmt.Errorf("failed to resolve base of range %s...%s: %w", baseRev, head, err)
+	}
+	return Range{Base: base, Head: head}, nil
+}
 
-		fmt.Printf("DEBUG: Strategy %d: git %v\n", i+1, args)
-		fmt.Printf("DEBUG: Output length: %d bytes, Error: %v\n", len(output), err)
+// ParseGitleaksDiff computes the diff of every ignore file matching patterns
+// for exactly the given range. Both versions of each file are read through
+// reader and diffed in-process. Changes are returned grouped by file, in path order.
+func ParseGitleaksDiff(ctx context.Context, reader ObjectReader, rng Range, patterns []string) ([]DiffChange, error) {
+	if len(patterns) == 0 {
+		patterns = []string{GitleaksIgnorePath}
+	}
 
-		if err == nil {
-			successCount++
-			fmt.Printf("DEBUG: Strategy %d succeeded, parsing output...\n", i+1)
-			if len(output) > 0 {
-				previewLen := 200
-				if len(output) < previewLen {
-					previewLen = len(output)
-				}
-				fmt.Printf("DEBUG: Output preview (first %d chars): %s\n", previewLen, string(output[:previewLen]))
-			}
+	files, err := reader.ChangedFiles(ctx, rng.Base, rng.Head)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files in range %s: %w", rng, err)
+	}
 
-			// Success! Parse the output
-			result, parseErr := parseDiffOutput(output)
-			if parseErr == nil && len(result) > 0 {
-				fmt.Printf("DEBUG: Found %d changes!\n", len(result))
-				return result, nil
-			}
-			// If parsing succeeded but no results, continue trying other strategies
-			if parseErr != nil {
-				fmt.Printf("DEBUG: Parse error: %v\n", parseErr)
-				lastErr = fmt.Errorf("strategy %d (%v) parse failed: %w", i+1, args, parseErr)
-			} else {
-				fmt.Printf("DEBUG: Parse succeeded but 0 results\n")
-			}
+	changes := []DiffChange{}
+	for _, file := range files {
+		if !MatchAnyGlob(patterns, file) {
 			continue
 		}
 
-		// Save error for later
-		lastOutput = output
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			lastErr = fmt.Errorf("strategy %d (%v) failed (exit %d): %s", i+1, args, exitErr.ExitCode(), string(output))
-		} else {
-			lastErr = fmt.Errorf("strategy %d (%v) failed: %w", i+1, args, err)
+		fileChanges, err := parseFileDiff(ctx, reader, rng, file)
+		if err != nil {
+			return nil, err
 		}
+		changes = append(changes, fileChanges...)
 	}
 
-	// If at least one strategy succeeded (command ran without error)
-	// but no changes were found, this could mean:
-	// 1. The file truly hasn't changed (legitimate case)
-	// 2. All strategies returned empty output (potential issue)
+	return changes, nil
+}
 
-	fmt.Printf("DEBUG: Completed all strategies. Success count: %d, last error: %v\n", successCount, lastErr)
+// parseFileDiff diffs a single ignore file between rng.Base and rng.Head
+func parseFileDiff(ctx context.Context, reader ObjectReader, rng Range, path string) ([]DiffChange, error) {
+	oldContent, err := readOptionalBlob(ctx, reader, rng.Base, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at base of range %s: %w", path, rng, err)
+	}
 
-	// If at least one strategy succeeded without error, treat as "no changes"
-	if successCount > 0 {
-		fmt.Printf("DEBUG: Returning empty result (no changes detected)\n")
-		return []DiffChange{}, nil
+	newContent, err := readOptionalBlob(ctx, reader, rng.Head, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at head of range %s: %w", path, rng, err)
 	}
 
-	// All strategies failed with errors
-	if lastErr != nil {
-		if len(lastOutput) > 0 {
-			return nil, fmt.Errorf("all %d git diff strategies failed, last error: %w (output: %s)", len(strategies), lastErr, string(lastOutput))
-		}
-		return nil, fmt.Errorf("all %d git diff strategies failed, last error: %w", len(strategies), lastErr)
+	patch := UnifiedDiff(path, oldContent, newContent, DefaultContextLines)
+
+	changes, err := parseDiffOutput(path, patch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s diff for range %s: %w", path, rng, err)
 	}
+	AttachMetadata(changes, newContent)
 
-	// No strategies were attempted (shouldn't happen)
-	fmt.Printf("DEBUG: No strategies attempted, returning empty\n")
-	return []DiffChange{}, nil
+	return changes, nil
+}
+
+// readOptionalBlob reads a file, returning nil content (not an error) if it does not exist
+func readOptionalBlob(ctx context.Context, reader BlobReader, rev, path string) ([]byte, error) {
+	content, err := reader.ReadBlob(ctx, rev, path)
+	if errors.Is(err, ErrObjectNotFound) {
+		return nil, nil
+	}
+	return content, err
 }
 
-// parseDiffOutput parses the git diff output
-func parseDiffOutput(output []byte) ([]DiffChange, error) {
+// parseDiffOutput parses the git diff output for the ignore file at path
+func parseDiffOutput(path string, output []byte) ([]DiffChange, error) {
 
 	// If output is empty, no changes to .gitleaksignore
 	if len(output) == 0 {
@@ -150,8 +129,11 @@ func parseDiffOutput(output []byte) ([]DiffChange, error) {
 	}
 
 	var changes []DiffChange
+	var block []DiffChange // consecutive -/+ lines, flushed at context lines and hunk boundaries
+	var comments []string  // comment lines directly above the current line of the new file
 	scanner := bufio.NewScanner(bytes.NewReader(output))
 	lineNum := 0
+	oldLineNum := 0
 	position := 0
 
 	// Regex to parse hunk headers: @@ -old_start,old_count +new_start,new_count @@
@@ -163,7 +145,13 @@ func parseDiffOutput(output []byte) ([]DiffChange, error) {
 
 		// Check for hunk header
 		if matches := hunkRegex.FindStringSubmatch(line); matches != nil {
+			changes = append(changes, pairModifications(block)...)
+			block = nil
+			comments = nil // Lines above the hunk are not in the patch
+
+			// matches[1] is the old file starting line number,
 			// matches[3] is the new file starting line number
+			oldLineNum, _ = strconv.Atoi(matches[1])
 			lineNum, _ = strconv.Atoi(matches[3])
 			continue
 		}
@@ -181,16 +169,18 @@ func parseDiffOutput(output []byte) ([]DiffChange, error) {
 			content := strings.TrimPrefix(line, "+")
 			content = strings.TrimSpace(content)
 
-			// Skip empty lines and comments
+			// Skip empty lines and comments, keeping comments as metadata for the next entry
 			if content != "" && !strings.HasPrefix(content, "#") {
-				changes = append(changes, DiffChange{
-					FilePath:   ".gitleaksignore",
+				block = append(block, DiffChange{
+					FilePath:   path,
 					Operation:  OperationAddition,
 					LineNumber: lineNum,
 					Content:    content,
 					Position:   position,
+					Metadata:   ParseEntryMetadata(comments),
 				})
 			}
+			comments = trackComments(comments, content)
 			lineNum++
 		} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
 			// Handle deletions
@@ -199,16 +189,22 @@ func parseDiffOutput(output []byte) ([]DiffChange, error) {
 
 			// Skip empty lines and comments
 			if content != "" && !strings.HasPrefix(content, "#") {
-				changes = append(changes, DiffChange{
-					FilePath:  ".gitleaksignore",
-					Operation: OperationDeletion,
-					Content:   content,
-					Position:  position,
+				block = append(block, DiffChange{
+					FilePath:      path,
+					Operation:     OperationDeletion,
+					OldLineNumber: oldLineNum,
+					Content:       content,
+					Position:      position,
 				})
 			}
+			oldLineNum++
 		} else if !strings.HasPrefix(line, "\\") {
-			// Context lines (no change)
+			// Context lines (no change) end the current change block
+			changes = append(changes, pairModifications(block)...)
+			block = nil
+			comments = trackComments(comments, strings.TrimSpace(strings.TrimPrefix(line, " ")))
 			lineNum++
+			oldLineNum++
 		}
 	}
 
@@ -216,5 +212,78 @@ func parseDiffOutput(output []byte) ([]DiffChange, error) {
 		return nil, fmt.Errorf("error scanning diff output: %w", err)
 	}
 
+	changes = append(changes, pairModifications(block)...)
+
 	return changes, nil
 }
+
+// trackComments returns the comment block directly above the line after
+// content: comments are collected, while entries and blank lines end the block
+func trackComments(comments []string, content string) []string {
+	if strings.HasPrefix(content, "#") {
+		return append(comments, content)
+	}
+	return nil
+}
+
+// pairModifications turns deletion/addition pairs within a single change block
+// into modifications when both lines refer to the same file pattern.
+// Each deletion is paired with the first unpaired addition for the same pattern.
+// Unpaired deletions are returned first, followed by additions and modifications
+// in new-file order.
+func pairModifications(block []DiffChange) []DiffChange {
+	if len(block) == 0 {
+		return nil
+	}
+
+	var deletions, additions []DiffChange
+	for _, change := range block {
+		if change.IsDeletion() {
+			deletions = append(deletions, change)
+		} else {
+			additions = append(additions, change)
+		}
+	}
+
+	pairedDeletion := make([]bool, len(deletions))
+	pairedWith := make([]int, len(additions))
+	for i := range pairedWith {
+		pairedWith[i] = -1
+	}
+
+	for ai, add := range additions {
+		addEntry, err := ParseGitleaksEntry(add.Content)
+		if err != nil {
+			continue
+		}
+		for di, del := range deletions {
+			if pairedDeletion[di] {
+				continue
+			}
+			delEntry, err := ParseGitleaksEntry(del.Content)
+			if err != nil || delEntry.FilePattern != addEntry.FilePattern {
+				continue
+			}
+			pairedDeletion[di] = true
+			pairedWith[ai] = di
+			break
+		}
+	}
+
+	var result []DiffChange
+	for di, del := range deletions {
+		if !pairedDeletion[di] {
+			result = append(result, del)
+		}
+	}
+	for ai, add := range additions {
+		if di := pairedWith[ai]; di >= 0 {
+			add.Operation = OperationModification
+			add.OldLineNumber = deletions[di].OldLineNumber
+			add.PreviousContent = deletions[di].Content
+		}
+		result = append(result, add)
+	}
+
+	return result
+}
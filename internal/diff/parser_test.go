@@ -0,0 +1,579 @@
+package diff
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo is a throwaway git repository for exercising object readers
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+// newTestRepo initializes an empty git repository in a temp directory
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init", "-q", "-b", "main")
+	r.git("config", "user.email", "test@example.com")
+	r.git("config", "user.name", "test")
+	r.git("config", "commit.gpgsign", "false")
+	return r
+}
+
+// commit writes files (empty content deletes the file) and returns the new commit SHA
+func (r *testRepo) commit(files map[string]string) string {
+	r.t.Helper()
+	for name, content := range files {
+		path := filepath.Join(r.dir, name)
+		if content == "" {
+			if err := os.Remove(path); err != nil {
+				r.t.Fatalf("remove %s: %v", name, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			r.t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			r.t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	r.git("add", "-A")
+	r.git("commit", "-q", "--allow-empty", "-m", "test")
+	return strings.TrimSpace(r.git("rev-parse", "HEAD"))
+}
+
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v (%s)", args, err, out)
+	}
+	return string(out)
+}
+
+func TestParseGitleaksDiff_Backends(t *testing.T) {
+	repo := newTestRepo(t)
+	base := repo.commit(map[string]string{
+		".gitleaksignore": "old.txt:1\nkeep.txt:2\n",
+	})
+	// An unrelated commit in between must not affect the result
+	repo.commit(map[string]string{"README.md": "hello\n"})
+	head := repo.commit(map[string]string{
+		".gitleaksignore": "keep.txt:2\nnew.txt:3\n",
+	})
+
+	for _, backend := range []string{BackendGitCLI, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			ctx := context.Background()
+			reader, err := NewObjectReader(backend, repo.dir)
+			if err != nil {
+				t.Fatalf("NewObjectReader() unexpected error: %v", err)
+			}
+
+			rng, err := ResolveRange(ctx, reader, base[:12], "", head)
+			if err != nil {
+				t.Fatalf("ResolveRange() unexpected error: %v", err)
+			}
+			if rng.Base != base || rng.Head != head {
+				t.Errorf("ResolveRange() = %s, want %s..%s", rng, base, head)
+			}
+
+			changes, err := ParseGitleaksDiff(ctx, reader, rng, nil)
+			if err != nil {
+				t.Fatalf("ParseGitleaksDiff() unexpected error: %v", err)
+			}
+
+			if len(changes) != 2 {
+				t.Fatalf("expected 2 changes, got %d: %+v", len(changes), changes)
+			}
+			if !changes[0].IsDeletion() || changes[0].Content != "old.txt:1" {
+				t.Errorf("changes[0] = %+v, want deletion of old.txt:1", changes[0])
+			}
+			if !changes[1].IsAddition() || changes[1].Content != "new.txt:3" || changes[1].LineNumber != 2 {
+				t.Errorf("changes[1] = %+v, want addition of new.txt:3 at line 2", changes[1])
+			}
+		})
+	}
+}
+
+func TestParseGitleaksDiff_FileAddedInHead(t *testing.T) {
+	repo := newTestRepo(t)
+	base := repo.commit(map[string]string{"README.md": "hello\n"})
+	head := repo.commit(map[string]string{".gitleaksignore": "# header\nsecrets.env\n"})
+
+	reader := NewGitCLIReader(repo.dir)
+	changes, err := ParseGitleaksDiff(context.Background(), reader, Range{Base: base, Head: head}, nil)
+	if err != nil {
+		t.Fatalf("ParseGitleaksDiff() unexpected error: %v", err)
+	}
+
+	if len(changes) != 1 || changes[0].Content != "secrets.env" || changes[0].LineNumber != 2 {
+		t.Errorf("expected single addition of secrets.env at line 2, got %+v", changes)
+	}
+}
+
+func TestParseGitleaksDiff_ErrorNamesRange(t *testing.T) {
+	repo := newTestRepo(t)
+	head := repo.commit(map[string]string{".gitleaksignore": "a.txt\n"})
+	missing := "0123456789abcdef0123456789abcdef01234567"
+
+	for _, backend := range []string{BackendGitCLI, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			reader, err := NewObjectReader(backend, repo.dir)
+			if err != nil {
+				t.Fatalf("NewObjectReader() unexpected error: %v", err)
+			}
+
+			_, err = ParseGitleaksDiff(context.Background(), reader, Range{Base: missing, Head: head}, nil)
+			if err == nil {
+				t.Fatal("ParseGitleaksDiff() expected error for unknown base commit")
+			}
+			if !strings.Contains(err.Error(), missing+".."+head) {
+				t.Errorf("error should name the range %s..%s, got: %v", missing, head, err)
+			}
+		})
+	}
+}
+
+func TestParseGitleaksDiff_NestedIgnoreFiles(t *testing.T) {
+	repo := newTestRepo(t)
+	base := repo.commit(map[string]string{
+		".gitleaksignore":                   "root.txt\n",
+		"services/payments/.gitleaksignore": "config.yml:3\n",
+	})
+	head := repo.commit(map[string]string{
+		".gitleaksignore":                   "root.txt\nroot2.txt\n",
+		"services/payments/.gitleaksignore": "",
+		"services/search/.gitleaksignore":   "index.json:7\n",
+		"docs/.gitleaksignore.md":           "not an ignore file\n",
+	})
+
+	for _, backend := range []string{BackendGitCLI, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			reader, err := NewObjectReader(backend, repo.dir)
+			if err != nil {
+				t.Fatalf("NewObjectReader() unexpected error: %v", err)
+			}
+
+			patterns := []string{".gitleaksignore", "services/**/.gitleaksignore"}
+			changes, err := ParseGitleaksDiff(context.Background(), reader, Range{Base: base, Head: head}, patterns)
+			if err != nil {
+				t.Fatalf("ParseGitleaksDiff() unexpected error: %v", err)
+			}
+
+			want := []struct {
+				path      string
+				operation OperationType
+				content   string
+			}{
+				{".gitleaksignore", OperationAddition, "root2.txt"},
+				{"services/payments/.gitleaksignore", OperationDeletion, "config.yml:3"},
+				{"services/search/.gitleaksignore", OperationAddition, "index.json:7"},
+			}
+			if len(changes) != len(want) {
+				t.Fatalf("expected %d changes, got %d: %+v", len(want), len(changes), changes)
+			}
+			for i, w := range want {
+				if changes[i].FilePath != w.path || changes[i].Operation != w.operation || changes[i].Content != w.content {
+					t.Errorf("changes[%d] = %+v, want %s %s in %s", i, changes[i], w.operation, w.content, w.path)
+				}
+			}
+		})
+	}
+}
+
+func TestObjectReader_ListFiles(t *testing.T) {
+	repo := newTestRepo(t)
+	head := repo.commit(map[string]string{
+		"b.txt":           "b\n",
+		"a/nested.env":    "x\n",
+		".gitleaksignore": "a/*.env\n",
+	})
+
+	for _, backend := range []string{BackendGitCLI, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			reader, err := NewObjectReader(backend, repo.dir)
+			if err != nil {
+				t.Fatalf("NewObjectReader() unexpected error: %v", err)
+			}
+			files, err := reader.ListFiles(context.Background(), head)
+			if err != nil {
+				t.Fatalf("ListFiles() unexpected error: %v", err)
+			}
+			if got := strings.Join(files, ","); got != ".gitleaksignore,a/nested.env,b.txt" {
+				t.Errorf("ListFiles() = %s", got)
+			}
+		})
+	}
+}
+
+func TestResolveRange_MergeBaseFallback(t *testing.T) {
+	repo := newTestRepo(t)
+	base := repo.commit(map[string]string{".gitleaksignore": "a.txt\n"})
+	repo.git("update-ref", "refs/remotes/origin/main", base)
+	head := repo.commit(map[string]string{".gitleaksignore": "a.txt\nb.txt\n"})
+
+	reader := NewGitCLIReader(repo.dir)
+	rng, err := ResolveRange(context.Background(), reader, "", "main", head)
+	if err != nil {
+		t.Fatalf("ResolveRange() unexpected error: %v", err)
+	}
+	if rng.Base != base {
+		t.Errorf("ResolveRange() base = %s, want merge base %s", rng.Base, base)
+	}
+
+	if _, err := ResolveRange(context.Background(), reader, "", "", head); err == nil {
+		t.Error("ResolveRange() expected error when neither base SHA nor base ref is set")
+	}
+}
+
+// TestResolveRange_AdvancedBase tests that commits the base branch gained
+// after the branch point are not part of the range
+func TestResolveRange_AdvancedBase(t *testing.T) {
+	repo := newTestRepo(t)
+	mergeBase := repo.commit(map[string]string{".gitleaksignore": "a.txt:1\n"})
+	repo.git("checkout", "-q", "-b", "feature")
+	head := repo.commit(map[string]string{".gitleaksignore": "a.txt:1\nfeature.txt:2\n"})
+	repo.git("checkout", "-q", "main")
+	baseTip := repo.commit(map[string]string{".gitleaksignore": "a.txt:1\nmain.txt:3\n"})
+	repo.git("update-ref", "refs/remotes/origin/main", baseTip)
+
+	for _, backend := range []string{BackendGitCLI, BackendGoGit} {
+		t.Run(backend, func(t *testing.T) {
+			ctx := context.Background()
+			reader, err := NewObjectReader(backend, repo.dir)
+			if err != nil {
+				t.Fatalf("NewObjectReader() unexpected error: %v", err)
+			}
+
+			for _, baseSHA := range []string{baseTip, ""} {
+				rng, err := ResolveRange(ctx, reader, baseSHA, "main", head)
+				if err != nil {
+					t.Fatalf("ResolveRange(%q) unexpected error: %v", baseSHA, err)
+				}
+				if rng.Base != mergeBase || rng.Head != head {
+					t.Errorf("ResolveRange(%q) = %s, want %s..%s", baseSHA, rng, mergeBase, head)
+				}
+			}
+
+			rng, err := ResolveRange(ctx, reader, baseTip, "", head)
+			if err != nil {
+				t.Fatalf("ResolveRange() unexpected error: %v", err)
+			}
+			changes, err := ParseGitleaksDiff(ctx, reader, rng, nil)
+			if err != nil {
+				t.Fatalf("ParseGitleaksDiff() unexpected error: %v", err)
+			}
+			if len(changes) != 1 || changes[0].Operation != OperationAddition || changes[0].Content != "feature.txt:2" {
+				t.Errorf("ParseGitleaksDiff() = %+v, want only the addition of feature.txt:2", changes)
+			}
+		})
+	}
+}
+
+// TestParseGitleaksDiff_MetadataOutsideContext tests that a justification
+// further above a modified entry than the diff's context is still found
+func TestParseGitleaksDiff_MetadataOutsideContext(t *testing.T) {
+	header := "# reason: fixtures for the payment service tests\n" +
+		"# ticket: SEC-123\n" +
+		"# The keys below were generated for the test suite\n" +
+		"# and never deployed anywhere.\n" +
+		"#\n"
+	repo := newTestRepo(t)
+	base := repo.commit(map[string]string{
+		".gitleaksignore": header + "tests/fixtures/key.pem:private-key:1\n",
+	})
+	head := repo.commit(map[string]string{
+		".gitleaksignore": header + "tests/fixtures/key.pem:private-key:2\n",
+	})
+
+	reader := NewGitCLIReader(repo.dir)
+	changes, err := ParseGitleaksDiff(context.Background(), reader, Range{Base: base, Head: head}, nil)
+	if err != nil {
+		t.Fatalf("ParseGitleaksDiff() unexpected error: %v", err)
+	}
+
+	if len(changes) != 1 || changes[0].Operation != OperationModification {
+		t.Fatalf("ParseGitleaksDiff() = %+v, want one modification", changes)
+	}
+	if meta := changes[0].Metadata; meta == nil || meta.Reason != "fixtures for the payment service tests" || meta.Ticket != "SEC-123" {
+		t.Errorf("Metadata = %+v, want the reason and ticket 5 lines above the entry", meta)
+	}
+}
+
+func TestParseDiffOutput_LineNumbers(t *testing.T) {
+	type want struct {
+		op      OperationType
+		content string
+		newLine int
+		oldLine int
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+		want  []want
+	}{
+		{
+			name: "single hunk with deletion and addition",
+			patch: "@@ -3,4 +3,4 @@\n" +
+				" keep-a.txt\n" +
+				"-removed.txt:10\n" +
+				"+added.txt:20\n" +
+				" keep-b.txt\n",
+			want: []want{
+				{OperationDeletion, "removed.txt:10", 0, 4},
+				{OperationAddition, "added.txt:20", 4, 0},
+			},
+		},
+		{
+			name: "multiple hunks with shifted offsets",
+			patch: "diff --git a/.gitleaksignore b/.gitleaksignore\n" +
+				"index 1111111..2222222 100644\n" +
+				"--- a/.gitleaksignore\n" +
+				"+++ b/.gitleaksignore\n" +
+				"@@ -1,3 +1,4 @@\n" +
+				" # header\n" +
+				"+first-new.txt\n" +
+				" a.txt\n" +
+				" b.txt\n" +
+				"@@ -20,5 +21,3 @@\n" +
+				" c.txt\n" +
+				"-gone-1.txt\n" +
+				"-gone-2.txt\n" +
+				" d.txt\n" +
+				" e.txt\n",
+			want: []want{
+				{OperationAddition, "first-new.txt", 2, 0},
+				{OperationDeletion, "gone-1.txt", 0, 21},
+				{OperationDeletion, "gone-2.txt", 0, 22},
+			},
+		},
+		{
+			name: "deleted comment lines still advance old counter",
+			patch: "@@ -5,3 +5,1 @@\n" +
+				"-# reason: fixture\n" +
+				"-fixture.json\n" +
+				" other.txt\n",
+			want: []want{
+				{OperationDeletion, "fixture.json", 0, 6},
+			},
+		},
+		{
+			name: "hunk header without counts",
+			patch: "@@ -7 +7 @@\n" +
+				"-old.txt\n" +
+				"+new.txt\n",
+			want: []want{
+				{OperationDeletion, "old.txt", 0, 7},
+				{OperationAddition, "new.txt", 7, 0},
+			},
+		},
+		{
+			name: "no newline marker is ignored",
+			patch: "@@ -1,2 +1,2 @@\n" +
+				" a.txt\n" +
+				"-b.txt\n" +
+				"\\ No newline at end of file\n" +
+				"+c.txt\n",
+			want: []want{
+				{OperationDeletion, "b.txt", 0, 2},
+				{OperationAddition, "c.txt", 2, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := parseDiffOutput(GitleaksIgnorePath, []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("parseDiffOutput() unexpected error: %v", err)
+			}
+
+			if len(changes) != len(tt.want) {
+				t.Fatalf("got %d changes, want %d: %+v", len(changes), len(tt.want), changes)
+			}
+
+			for i, w := range tt.want {
+				got := changes[i]
+				if got.Operation != w.op || got.Content != w.content {
+					t.Errorf("change %d = {%s %q}, want {%s %q}", i, got.Operation, got.Content, w.op, w.content)
+				}
+				if got.LineNumber != w.newLine {
+					t.Errorf("change %d LineNumber = %d, want %d", i, got.LineNumber, w.newLine)
+				}
+				if got.OldLineNumber != w.oldLine {
+					t.Errorf("change %d OldLineNumber = %d, want %d", i, got.OldLineNumber, w.oldLine)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDiffOutput_Modifications(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		wantOps []OperationType
+	}{
+		{
+			name: "line number changed for same file",
+			patch: "@@ -1,3 +1,3 @@\n" +
+				" a.txt\n" +
+				"-config/a.yml:12\n" +
+				"+config/a.yml:14\n" +
+				" b.txt\n",
+			wantOps: []OperationType{OperationModification},
+		},
+		{
+			name: "rule changed for same file",
+			patch: "@@ -1 +1 @@\n" +
+				"-DUMMY.txt:generic-api-key:1\n" +
+				"+DUMMY.txt:base64-encoded-secrets:1\n",
+			wantOps: []OperationType{OperationModification},
+		},
+		{
+			name: "different files are not paired",
+			patch: "@@ -1 +1 @@\n" +
+				"-config/a.yml:12\n" +
+				"+config/b.yml:12\n",
+			wantOps: []OperationType{OperationDeletion, OperationAddition},
+		},
+		{
+			name: "context line separates blocks",
+			patch: "@@ -1,3 +1,3 @@\n" +
+				"-config/a.yml:12\n" +
+				" other.txt\n" +
+				"+config/a.yml:14\n",
+			wantOps: []OperationType{OperationDeletion, OperationAddition},
+		},
+		{
+			name: "partial pairing in a larger block",
+			patch: "@@ -1,2 +1,3 @@\n" +
+				"-config/a.yml:12\n" +
+				"-gone.txt\n" +
+				"+new.txt\n" +
+				"+config/a.yml:14\n" +
+				"+another.txt\n",
+			wantOps: []OperationType{OperationDeletion, OperationAddition, OperationModification, OperationAddition},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := parseDiffOutput(GitleaksIgnorePath, []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("parseDiffOutput() unexpected error: %v", err)
+			}
+
+			if len(changes) != len(tt.wantOps) {
+				t.Fatalf("got %d changes, want %d: %+v", len(changes), len(tt.wantOps), changes)
+			}
+			for i, op := range tt.wantOps {
+				if changes[i].Operation != op {
+					t.Errorf("change %d operation = %s, want %s", i, changes[i].Operation, op)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDiffOutput_ModificationFields(t *testing.T) {
+	patch := "@@ -4,3 +4,3 @@\n" +
+		" a.txt\n" +
+		"-config/a.yml:12\n" +
+		"+config/a.yml:14\n" +
+		" b.txt\n"
+
+	changes, err := parseDiffOutput(GitleaksIgnorePath, []byte(patch))
+	if err != nil {
+		t.Fatalf("parseDiffOutput() unexpected error: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(changes))
+	}
+
+	got := changes[0]
+	if !got.IsModification() {
+		t.Errorf("Operation = %s, want modification", got.Operation)
+	}
+	if got.Content != "config/a.yml:14" || got.PreviousContent != "config/a.yml:12" {
+		t.Errorf("Content = %q, PreviousContent = %q", got.Content, got.PreviousContent)
+	}
+	if got.LineNumber != 5 || got.OldLineNumber != 5 {
+		t.Errorf("LineNumber = %d, OldLineNumber = %d, want 5 and 5", got.LineNumber, got.OldLineNumber)
+	}
+}
+
+func TestParseDiffOutput_Metadata(t *testing.T) {
+	patch := "@@ -1,4 +1,9 @@\n" +
+		" # reason: legacy fixtures\n" +
+		" a.txt:1\n" +
+		"+# reason: test fixture; ticket: SEC-123\n" +
+		"+# expires: 2027-01-31\n" +
+		"+b.txt:2\n" +
+		"+c.txt:3\n" +
+		" \n" +
+		" # ticket: SEC-7\n" +
+		"-d.txt:4\n" +
+		"+d.txt:5\n"
+
+	changes, err := parseDiffOutput(GitleaksIgnorePath, []byte(patch))
+	if err != nil {
+		t.Fatalf("parseDiffOutput() unexpected error: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %d: %+v", len(changes), changes)
+	}
+
+	want := []*EntryMetadata{
+		{Reason: "test fixture", Ticket: "SEC-123", Expires: "2027-01-31"},
+		nil, // Only the entry directly below the comment block gets it
+		{Ticket: "SEC-7"},
+	}
+	for i, change := range changes {
+		got := change.Metadata
+		if (got == nil) != (want[i] == nil) || (got != nil && *got != *want[i]) {
+			t.Errorf("change %d (%s) Metadata = %+v, want %+v", i, change.Content, got, want[i])
+		}
+	}
+
+	entry, err := changes[0].Entry()
+	if err != nil || entry.Metadata != changes[0].Metadata {
+		t.Errorf("Entry() should carry the change's metadata, got %+v (err %v)", entry, err)
+	}
+}
+
+func TestParseEntryMetadata(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  *EntryMetadata
+	}{
+		{"single line", []string{"# reason: fixture; ticket: SEC-1; expires: 2027-01-31"}, &EntryMetadata{Reason: "fixture", Ticket: "SEC-1", Expires: "2027-01-31"}},
+		{"one per line", []string{"# Reason: fixture", "#Ticket:  https://jira.example.com/SEC-1 "}, &EntryMetadata{Reason: "fixture", Ticket: "https://jira.example.com/SEC-1"}},
+		{"free text", []string{"# test keys", "# ---"}, nil},
+		{"no comment", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseEntryMetadata(tt.lines)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ParseEntryMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Policy rule IDs
+const (
+	// PolicyNoWildcards forbids wildcard entries
+	PolicyNoWildcards = "no-wildcards"
+
+	// PolicyRequireLineNumber forbids entries that exclude a whole file
+	PolicyRequireLineNumber = "require-line-number"
+
+	// PolicyForbiddenPaths forbids entries excluding files under given globs
+	PolicyForbiddenPaths = "forbidden-paths"
+
+	// PolicyMaxNewEntries caps the number of entries a pull request may add
+	PolicyMaxNewEntries = "max-new-entries"
+
+	// PolicyRequireReason forbids entries without a reason in their comment block
+	PolicyRequireReason = "require-reason"
+
+	// PolicyRequireTicket forbids entries without a ticket in their comment block
+	PolicyRequireTicket = "require-ticket"
+
+	// PolicyInvalidExpiry forbids expiry dates that are not YYYY-MM-DD
+	PolicyInvalidExpiry = "invalid-expiry"
+
+	// PolicyMaxExpiry forbids entries that never expire or expire too far ahead
+	PolicyMaxExpiry = "max-expiry"
+)
+
+// PolicyRules lists every policy rule ID
+func PolicyRules() []string {
+	return []string{
+		PolicyNoWildcards, PolicyRequireLineNumber, PolicyForbiddenPaths, PolicyMaxNewEntries,
+		PolicyRequireReason, PolicyRequireTicket, PolicyInvalidExpiry, PolicyMaxExpiry,
+	}
+}
+
+// now returns the current time; replaced in tests
+var now = time.Now
+
+// Policy is a set of rules new and modified entries must follow
+// The zero value allows everything.
+type Policy struct {
+	ForbidWildcards   bool
+	RequireLineNumber bool
+
+	// ForbiddenPaths are globs of files that must not be excluded
+	// A trailing slash means everything under a directory, e.g. "src/".
+	ForbiddenPaths []string
+
+	// MaxNewEntries is the number of entries a pull request may add (0 = no limit)
+	MaxNewEntries int
+
+	// RequireReason and RequireTicket require the fields in each entry's metadata
+	RequireReason bool
+	RequireTicket bool
+
+	// MaxExpiryDays is how many days ahead an entry may expire (0 = no limit)
+	// Entries without an expiry date break the rule when it is set.
+	MaxExpiryDays int
+
+	// WarnOnly lists rules whose violations are reported without failing the run
+	WarnOnly []string
+}
+
+// PolicyViolation is a broken policy rule
+type PolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+
+	// Blocking is true unless the rule is listed in Policy.WarnOnly
+	Blocking bool `json:"blocking"`
+}
+
+// PolicyResult holds the violations of a pull request as a whole; violations
+// of single entries are set on their DiffChange
+type PolicyResult struct {
+	// Violations are rules broken by the pull request rather than an entry
+	Violations []PolicyViolation `json:"violations,omitempty"`
+
+	// Blocking counts blocking violations, including those on entries
+	Blocking int `json:"blocking"`
+
+	// Warnings counts non-blocking violations, including those on entries
+	Warnings int `json:"warnings"`
+}
+
+// Failed returns true if any blocking rule was violated
+func (r *PolicyResult) Failed() bool {
+	return r.Blocking > 0
+}
+
+// Enabled returns true if the policy has at least one rule
+func (p *Policy) Enabled() bool {
+	return p.ForbidWildcards || p.RequireLineNumber || len(p.ForbiddenPaths) > 0 || p.MaxNewEntries > 0 ||
+		p.requiresMetadata()
+}
+
+// EnabledRules returns the IDs of the rules the policy checks
+func (p *Policy) EnabledRules() []string {
+	enabled := map[string]bool{
+		PolicyNoWildcards:       p.ForbidWildcards,
+		PolicyRequireLineNumber: p.RequireLineNumber,
+		PolicyForbiddenPaths:    len(p.ForbiddenPaths) > 0,
+		PolicyMaxNewEntries:     p.MaxNewEntries > 0,
+		PolicyRequireReason:     p.RequireReason,
+		PolicyRequireTicket:     p.RequireTicket,
+		PolicyInvalidExpiry:     p.requiresMetadata(),
+		PolicyMaxExpiry:         p.MaxExpiryDays > 0,
+	}
+
+	var rules []string
+	for _, rule := range PolicyRules() {
+		if enabled[rule] {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// requiresMetadata returns true if any rule checks entry metadata
+func (p *Policy) requiresMetadata() bool {
+	return p.RequireReason || p.RequireTicket || p.MaxExpiryDays > 0
+}
+
+// Validate checks the forbidden path globs and warn-only rule names
+func (p *Policy) Validate() error {
+	for _, pattern := range p.ForbiddenPaths {
+		if err := ValidateGlob(forbiddenGlob(pattern)); err != nil {
+			return err
+		}
+	}
+	if p.MaxNewEntries < 0 {
+		return fmt.Errorf("max new entries must not be negative, got %d", p.MaxNewEntries)
+	}
+	if p.MaxExpiryDays < 0 {
+		return fmt.Errorf("max expiry days must not be negative, got %d", p.MaxExpiryDays)
+	}
+	for _, rule := range p.WarnOnly {
+		if !slices.Contains(PolicyRules(), rule) {
+			return fmt.Errorf("unknown policy rule %q (available: %s)", rule, strings.Join(PolicyRules(), ", "))
+		}
+	}
+	return nil
+}
+
+// EvaluatePolicy checks every addition and modification in changes against
+// the policy, setting Violations on the changes that break a rule
+// Wildcard entries are checked against forbidden paths both as written and,
+// if the change was validated, through the files they match.
+func EvaluatePolicy(policy *Policy, changes []DiffChange) *PolicyResult {
+	result := &PolicyResult{}
+	added := 0
+
+	for i := range changes {
+		change := &changes[i]
+		change.Violations = nil
+		if change.IsDeletion() {
+			continue
+		}
+		if change.IsAddition() {
+			added++
+		}
+
+		entry, err := change.Entry()
+		if err != nil {
+			continue // Reported when the comment is generated
+		}
+		for _, v := range policy.checkEntry(entry, change.Validation) {
+			change.Violations = append(change.Violations, v)
+			result.count(v)
+		}
+	}
+
+	if policy.MaxNewEntries > 0 && added > policy.MaxNewEntries {
+		v := policy.violation(PolicyMaxNewEntries, fmt.Sprintf(
+			"This pull request adds %d entries; at most %d are allowed per pull request.", added, policy.MaxNewEntries))
+		result.Violations = append(result.Violations, v)
+		result.count(v)
+	}
+	return result
+}
+
+// checkEntry returns the rules entry violates
+func (p *Policy) checkEntry(entry *GitleaksEntry, validation *EntryValidation) []PolicyViolation {
+	var violations []PolicyViolation
+
+	if p.ForbidWildcards && entry.IsPattern {
+		violations = append(violations, p.violation(PolicyNoWildcards,
+			fmt.Sprintf("`%s` is a wildcard pattern; list each file that needs an exclusion instead.", entry.FilePattern)))
+	}
+
+	if p.RequireLineNumber && !entry.IsPattern && !entry.HasLineNumber() {
+		violations = append(violations, p.violation(PolicyRequireLineNumber,
+			fmt.Sprintf("`%s` excludes the whole file; add the line number of the finding.", entry.FilePattern)))
+	}
+
+	if pattern, file := p.forbiddenMatch(entry, validation); pattern != "" {
+		message := fmt.Sprintf("`%s` is under `%s`, which must not be excluded from secret scanning.", entry.FilePattern, pattern)
+		if file != entry.FilePattern {
+			message = fmt.Sprintf("`%s` matches `%s`, which is under `%s` and must not be excluded from secret scanning.", entry.FilePattern, file, pattern)
+		}
+		violations = append(violations, p.violation(PolicyForbiddenPaths, message))
+	}
+
+	if p.requiresMetadata() {
+		violations = append(violations, p.checkMetadata(entry)...)
+	}
+
+	return violations
+}
+
+// checkMetadata returns the rules the justification of entry violates
+// A malformed expiry date is reported whenever any metadata rule is enabled.
+func (p *Policy) checkMetadata(entry *GitleaksEntry) []PolicyViolation {
+	var violations []PolicyViolation
+	meta := entry.Metadata
+	if meta == nil {
+		meta = &EntryMetadata{}
+	}
+
+	if p.RequireReason && meta.Reason == "" {
+		violations = append(violations, p.violation(PolicyRequireReason,
+			fmt.Sprintf("`%s` has no reason; add a comment above it such as `# reason: test fixture`.", entry.FilePattern)))
+	}
+
+	if p.RequireTicket && meta.Ticket == "" {
+		violations = append(violations, p.violation(PolicyRequireTicket,
+			fmt.Sprintf("`%s` has no ticket; add a comment above it such as `# ticket: SEC-123`.", entry.FilePattern)))
+	}
+
+	if meta.Expires == "" {
+		if p.MaxExpiryDays > 0 {
+			violations = append(violations, p.violation(PolicyMaxExpiry,
+				fmt.Sprintf("`%s` has no expiry date; add a comment above it such as `# expires: %s`, at most %d days ahead.",
+					entry.FilePattern, p.expiryLimit().Format(ExpiryLayout), p.MaxExpiryDays)))
+		}
+		return violations
+	}
+
+	expires, err := meta.ExpiryDate()
+	if err != nil {
+		return append(violations, p.violation(PolicyInvalidExpiry,
+			fmt.Sprintf("`%s` expires on `%s`, which is not a date in YYYY-MM-DD format.", entry.FilePattern, meta.Expires)))
+	}
+
+	if p.MaxExpiryDays > 0 {
+		if limit := p.expiryLimit(); expires.After(limit) {
+			violations = append(violations, p.violation(PolicyMaxExpiry,
+				fmt.Sprintf("`%s` expires on %s; exclusions may expire at most %d days ahead (by %s).",
+					entry.FilePattern, meta.Expires, p.MaxExpiryDays, limit.Format(ExpiryLayout))))
+		}
+	}
+
+	return violations
+}
+
+// forbiddenMatch returns the first forbidden path covering the entry and the
+// file it was matched with, or empty strings
+func (p *Policy) forbiddenMatch(entry *GitleaksEntry, validation *EntryValidation) (string, string) {
+	candidates := []string{entry.FilePattern}
+	if entry.IsPattern && validation != nil {
+		candidates = append(candidates, validation.Matches...)
+	}
+
+	for _, pattern := range p.ForbiddenPaths {
+		for _, file := range candidates {
+			if MatchGlob(forbiddenGlob(pattern), file) {
+				return pattern, file
+			}
+		}
+	}
+	return "", ""
+}
+
+// violation creates a violation of rule, blocking unless the rule is warn-only
+func (p *Policy) violation(rule, message string) PolicyViolation {
+	return PolicyViolation{
+		Rule:     rule,
+		Message:  message,
+		Blocking: !slices.Contains(p.WarnOnly, rule),
+	}
+}
+
+// expiryLimit returns the latest expiry date MaxExpiryDays allows
+func (p *Policy) expiryLimit() time.Time {
+	today := now()
+	return time.Date(today.Year(), today.Month(), today.Day()+p.MaxExpiryDays, 0, 0, 0, 0, time.UTC)
+}
+
+func (r *PolicyResult) count(v PolicyViolation) {
+	if v.Blocking {
+		r.Blocking++
+	} else {
+		r.Warnings++
+	}
+}
+
+// forbiddenGlob turns a directory ("src/") into a glob for everything under it
+func forbiddenGlob(pattern string) string {
+	if strings.HasSuffix(pattern, "/") {
+		return pattern + "**"
+	}
+	return pattern
+}
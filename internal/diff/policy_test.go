@@ -0,0 +1,147 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluatePolicy(t *testing.T) {
+	policy := &Policy{
+		ForbidWildcards:   true,
+		RequireLineNumber: true,
+		ForbiddenPaths:    []string{"src/", "config/prod/*.yml"},
+		MaxNewEntries:     3,
+		WarnOnly:          []string{PolicyRequireLineNumber},
+	}
+
+	changes := []DiffChange{
+		{Operation: OperationAddition, Content: "docs/example.env:generic-api-key:4"},
+		{Operation: OperationAddition, Content: "docs/example.env"},
+		{Operation: OperationAddition, Content: "*.pem", Validation: &EntryValidation{Matches: []string{"certs/a.pem", "src/test.pem"}}},
+		{Operation: OperationModification, Content: "config/prod/db.yml:12", PreviousContent: "config/prod/db.yml:10"},
+		{Operation: OperationDeletion, Content: "src/*.env"},
+		{FilePath: "src/.gitleaksignore", Operation: OperationAddition, Content: "keys.json:3"},
+	}
+
+	result := EvaluatePolicy(policy, changes)
+
+	rules := func(change DiffChange) []string {
+		var ids []string
+		for _, v := range change.Violations {
+			ids = append(ids, v.Rule)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		index int
+		want  []string
+	}{
+		{0, nil},
+		{1, []string{PolicyRequireLineNumber}},
+		{2, []string{PolicyNoWildcards, PolicyForbiddenPaths}},
+		{3, []string{PolicyForbiddenPaths}},
+		{4, nil},
+		{5, []string{PolicyForbiddenPaths}},
+	}
+	for _, tt := range tests {
+		if got := rules(changes[tt.index]); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("change %d (%s) violations = %v, want %v", tt.index, changes[tt.index].Content, got, tt.want)
+		}
+	}
+
+	if changes[1].Violations[0].Blocking {
+		t.Error("warn-only rule should not be blocking")
+	}
+	if msg := changes[2].Violations[1].Message; !strings.Contains(msg, "matches `src/test.pem`") {
+		t.Errorf("forbidden path message = %q, want the matched file", msg)
+	}
+
+	if len(result.Violations) != 1 || result.Violations[0].Rule != PolicyMaxNewEntries {
+		t.Fatalf("PR violations = %+v, want max-new-entries", result.Violations)
+	}
+	if !strings.Contains(result.Violations[0].Message, "adds 4 entries; at most 3") {
+		t.Errorf("max-new-entries message = %q", result.Violations[0].Message)
+	}
+	if result.Blocking != 5 || result.Warnings != 1 || !result.Failed() {
+		t.Errorf("Blocking = %d, Warnings = %d, want 5 and 1", result.Blocking, result.Warnings)
+	}
+}
+
+func TestEvaluatePolicy_Empty(t *testing.T) {
+	changes := []DiffChange{{Operation: OperationAddition, Content: "*.env"}}
+	result := EvaluatePolicy(&Policy{}, changes)
+	if result.Failed() || changes[0].Violations != nil {
+		t.Errorf("an empty policy should allow everything: %+v", result)
+	}
+}
+
+func TestEvaluatePolicy_Metadata(t *testing.T) {
+	now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	policy := &Policy{RequireReason: true, RequireTicket: true, MaxExpiryDays: 90}
+
+	tests := []struct {
+		name     string
+		metadata *EntryMetadata
+		want     []string
+	}{
+		{"complete", &EntryMetadata{Reason: "fixture", Ticket: "SEC-1", Expires: "2027-01-14"}, nil},
+		{"no comment", nil, []string{PolicyRequireReason, PolicyRequireTicket, PolicyMaxExpiry}},
+		{"missing ticket", &EntryMetadata{Reason: "fixture", Expires: "2026-12-01"}, []string{PolicyRequireTicket}},
+		{"malformed date", &EntryMetadata{Reason: "fixture", Ticket: "SEC-1", Expires: "31/01/2027"}, []string{PolicyInvalidExpiry}},
+		{"too far ahead", &EntryMetadata{Reason: "fixture", Ticket: "SEC-1", Expires: "2027-01-15"}, []string{PolicyMaxExpiry}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := []DiffChange{{Operation: OperationAddition, Content: "test/key.pem:1", Metadata: tt.metadata}}
+			EvaluatePolicy(policy, changes)
+
+			var got []string
+			for _, v := range changes[0].Violations {
+				got = append(got, v.Rule)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	changes := []DiffChange{{Operation: OperationAddition, Content: "test/key.pem:1", Metadata: &EntryMetadata{Expires: "2028-01-01"}}}
+	EvaluatePolicy(&Policy{MaxExpiryDays: 90}, changes)
+	if msg := changes[0].Violations[0].Message; !strings.Contains(msg, "at most 90 days ahead (by 2027-01-14)") {
+		t.Errorf("max-expiry message = %q", msg)
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    Policy
+		wantError string
+	}{
+		{"valid", Policy{ForbiddenPaths: []string{"src/", "**/*.key"}, WarnOnly: []string{PolicyNoWildcards}}, ""},
+		{"bad glob", Policy{ForbiddenPaths: []string{"src/[a"}}, "invalid glob pattern"},
+		{"unknown rule", Policy{WarnOnly: []string{"no-secrets"}}, `unknown policy rule "no-secrets"`},
+		{"negative cap", Policy{MaxNewEntries: -1}, "must not be negative"},
+		{"negative expiry", Policy{MaxExpiryDays: -30}, "max expiry days must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrObjectNotFound is returned when a file does not exist at a revision
+var ErrObjectNotFound = errors.New("object not found")
+
+// Supported object reader backends
+const (
+	BackendGitCLI = "cli"
+	BackendGoGit  = "go-git"
+)
+
+// BlobReader reads file contents at a given revision
+type BlobReader interface {
+	// ReadBlob returns the contents of path at rev
+	// Returns ErrObjectNotFound if the file does not exist at that revision
+	ReadBlob(ctx context.Context, rev, path string) ([]byte, error)
+}
+
+// ObjectReader provides the repository access needed to compute diffs
+type ObjectReader interface {
+	BlobReader
+
+	// ResolveCommit resolves a revision (SHA, branch, ref) to a full commit SHA
+	ResolveCommit(ctx context.Context, rev string) (string, error)
+
+	// MergeBase returns the best common ancestor of two commits
+	MergeBase(ctx context.Context, a, b string) (string, error)
+
+	// ChangedFiles returns the paths of files that differ between two commits
+	ChangedFiles(ctx context.Context, base, head string) ([]string, error)
+
+	TreeReader
+}
+
+// TreeReader lists the files in a commit's tree
+type TreeReader interface {
+	// ListFiles returns the paths of all files at rev, in path order
+	ListFiles(ctx context.Context, rev string) ([]string, error)
+}
+
+// NewObjectReader creates an ObjectReader for the repository at dir
+// backend must be BackendGitCLI or BackendGoGit
+func NewObjectReader(backend, dir string) (ObjectReader, error) {
+	switch backend {
+	case "", BackendGitCLI:
+		return NewGitCLIReader(dir), nil
+	case BackendGoGit:
+		return NewGoGitReader(dir)
+	default:
+		return nil, fmt.Errorf("unknown git backend: %s", backend)
+	}
+}
+
+// GitCLIReader reads objects by invoking the git command line
+type GitCLIReader struct {
+	// Dir is the repository directory (empty = current directory)
+	Dir string
+}
+
+// NewGitCLIReader creates a reader backed by the git CLI
+func NewGitCLIReader(dir string) *GitCLIReader {
+	return &GitCLIReader{Dir: dir}
+}
+
+// ResolveCommit resolves rev to a full commit SHA using git rev-parse
+func (r *GitCLIReader) ResolveCommit(ctx context.Context, rev string) (string, error) {
+	out, err := r.git(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve commit %q: %w", rev, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// MergeBase returns the merge base of a and b using git merge-base
+func (r *GitCLIReader) MergeBase(ctx context.Context, a, b string) (string, error) {
+	out, err := r.git(ctx, "merge-base", a, b)
+	if err != nil {
+		return "", fmt.Errorf("cannot find merge base of %s and %s: %w", a, b, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ChangedFiles lists paths that differ between base and head using git diff --name-only
+// Renames are reported as a deletion of the old path and an addition of the new one.
+func (r *GitCLIReader) ChangedFiles(ctx context.Context, base, head string) ([]string, error) {
+	out, err := r.git(ctx, "diff", "--name-only", "--no-renames", "-z", base, head, "--")
+	if err != nil {
+		return nil, fmt.Errorf("cannot list files changed in %s..%s: %w", base, head, err)
+	}
+
+	return splitNull(out), nil
+}
+
+// ListFiles lists all files at rev using git ls-tree
+func (r *GitCLIReader) ListFiles(ctx context.Context, rev string) ([]string, error) {
+	out, err := r.git(ctx, "ls-tree", "-r", "--name-only", "-z", rev)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list files at %s: %w", rev, err)
+	}
+	return splitNull(out), nil
+}
+
+// ReadBlob returns the contents of path at rev using git cat-file
+func (r *GitCLIReader) ReadBlob(ctx context.Context, rev, path string) ([]byte, error) {
+	object := rev + ":" + path
+
+	// Check existence first so a missing file is distinguishable from a git failure
+	if _, err := r.git(ctx, "cat-file", "-e", object); err != nil {
+		if _, commitErr := r.git(ctx, "cat-file", "-e", rev+"^{commit}"); commitErr != nil {
+			return nil, fmt.Errorf("cannot read commit %s: %w", rev, commitErr)
+		}
+		return nil, ErrObjectNotFound
+	}
+
+	out, err := r.git(ctx, "cat-file", "blob", object)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", object, err)
+	}
+	return out, nil
+}
+
+// splitNull splits NUL-terminated git output into its non-empty fields
+func splitNull(out []byte) []string {
+	var fields []string
+	for _, field := range strings.Split(string(out), "\x00") {
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// git runs a git command in the reader's directory and returns stdout
+func (r *GitCLIReader) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.Dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w (%s)", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
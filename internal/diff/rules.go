@@ -0,0 +1,93 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// RuleDefinition is a detection rule declared in a gitleaks config
+type RuleDefinition struct {
+	// ID is the rule's id, e.g. "aws-access-key"
+	ID string `json:"id"`
+
+	// Regex is the rule's detection regular expression (Go syntax)
+	Regex string `json:"regex,omitempty"`
+
+	// SecretGroup is the capture group holding the secret (0 = whole match)
+	SecretGroup int `json:"secret_group,omitempty"`
+}
+
+// ScanRules extracts the [[rules]] declared in a gitleaks config
+// Rules without a regex (path-only rules) are included with an empty Regex.
+func ScanRules(content []byte) ([]RuleDefinition, error) {
+	s := newTOMLScanner(content)
+
+	var (
+		rules  []RuleDefinition
+		inRule bool
+	)
+
+	onHeader := func(name string, isArray bool) {
+		inRule = name == "rules" && isArray
+		if inRule {
+			rules = append(rules, RuleDefinition{})
+		}
+	}
+
+	onKey := func(line int, key string, value tomlValue) error {
+		if !inRule {
+			return nil
+		}
+		rule := &rules[len(rules)-1]
+		switch {
+		case key == "id" && len(value.Strings) == 1:
+			rule.ID = value.Strings[0].Value
+		case key == "regex" && len(value.Strings) == 1:
+			rule.Regex = value.Strings[0].Value
+		case key == "secretGroup" && value.Raw != "":
+			group, err := strconv.Atoi(value.Raw)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid secretGroup %q", line, value.Raw)
+			}
+			rule.SecretGroup = group
+		}
+		return nil
+	}
+
+	if err := s.walk(onHeader, onKey); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// LoadRules reads the rules declared in the gitleaks configs at paths at rev
+// Missing files are skipped. Wildcard paths are ignored.
+func LoadRules(ctx context.Context, reader BlobReader, rev string, paths []string) ([]RuleDefinition, error) {
+	var rules []RuleDefinition
+	var errs []error
+	for _, p := range paths {
+		if isWildcard(p) {
+			continue
+		}
+
+		content, err := readOptionalBlob(ctx, reader, rev, p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read %s at %s: %w", p, rev, err))
+			continue
+		}
+		if content == nil {
+			continue
+		}
+
+		fileRules, err := ScanRules(content)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse rules in %s: %w", p, err))
+			continue
+		}
+		rules = append(rules, fileRules...)
+	}
+	return rules, errors.Join(errs...)
+}
@@ -0,0 +1,60 @@
+package diff
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestScanRules(t *testing.T) {
+	content := testGitleaksConfig + `
+[[rules]]
+id = "internal-token"
+regex = '''itk_([a-z]{8})'''
+secretGroup = 1
+`
+
+	rules, err := ScanRules([]byte(content))
+	if err != nil {
+		t.Fatalf("ScanRules() unexpected error: %v", err)
+	}
+
+	want := []RuleDefinition{
+		{ID: "aws-access-key", Regex: `(A3T[A-Z0-9]|AKIA)[A-Z0-9]{16}`},
+		{ID: "generic-api-key"},
+		{ID: "internal-token", Regex: `itk_([a-z]{8})`, SecretGroup: 1},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("ScanRules() returned %d rules, want %d: %+v", len(rules), len(want), rules)
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rules[%d] = %+v, want %+v", i, rules[i], want[i])
+		}
+	}
+}
+
+func TestScanRules_InvalidSecretGroup(t *testing.T) {
+	_, err := ScanRules([]byte("[[rules]]\nid = \"x\"\nsecretGroup = one\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("ScanRules() error = %v, want invalid secretGroup on line 3", err)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	reader := &fakeBlobReader{files: map[string]string{
+		"head:.gitleaks.toml": "[[rules]]\nid = \"a\"\nregex = \"a+\"\n",
+		"head:bad.toml":       "[[rules]\n",
+	}}
+
+	rules, err := LoadRules(context.Background(), reader, "head", []string{".gitleaks.toml", "missing.toml", "configs/*.toml", "bad.toml"})
+	if err == nil || !strings.Contains(err.Error(), "bad.toml") {
+		t.Errorf("LoadRules() error = %v, want parse failure for bad.toml", err)
+	}
+	if len(rules) != 1 || rules[0].ID != "a" {
+		t.Errorf("LoadRules() = %+v, want the rule from .gitleaks.toml", rules)
+	}
+	if reader.reads != 3 {
+		t.Errorf("expected wildcard paths not to be read, got %d reads", reader.reads)
+	}
+}
@@ -0,0 +1,142 @@
+package diff
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Finding is a secret reported by gitleaks
+// The secret itself and the matched text are deliberately not retained.
+type Finding struct {
+	RuleID      string `json:"RuleID"`
+	Description string `json:"Description,omitempty"`
+	File        string `json:"File"`
+	StartLine   int    `json:"StartLine"`
+	EndLine     int    `json:"EndLine,omitempty"`
+
+	// Commit is the commit the finding was introduced in (git scans only)
+	Commit string `json:"Commit,omitempty"`
+
+	// Fingerprint is the value gitleaks accepts in .gitleaksignore
+	Fingerprint string `json:"Fingerprint"`
+}
+
+// Scanner finds secrets in the pull request head
+type Scanner interface {
+	// Scan returns the findings in the working tree, plus those introduced in
+	// each of commits (needed to match git scan fingerprints)
+	// .gitleaksignore files must not be applied: the point is to see what the
+	// entries suppress.
+	Scan(ctx context.Context, commits []string) ([]Finding, error)
+}
+
+// GitleaksScanner runs the gitleaks binary and parses its JSON report
+type GitleaksScanner struct {
+	// Binary is the gitleaks executable (default "gitleaks" from PATH)
+	Binary string
+
+	// Dir is the checkout to scan (empty = current directory)
+	Dir string
+}
+
+// NewGitleaksScanner creates a scanner running binary over the checkout in dir
+func NewGitleaksScanner(binary, dir string) *GitleaksScanner {
+	return &GitleaksScanner{Binary: binary, Dir: dir}
+}
+
+// Scan runs a no-git scan of the working tree, and a git scan of commits if any
+func (s *GitleaksScanner) Scan(ctx context.Context, commits []string) ([]Finding, error) {
+	findings, err := s.run(ctx, "--no-git")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commits) > 0 {
+		logOpts := "--no-walk " + strings.Join(commits, " ")
+		commitFindings, err := s.run(ctx, "--log-opts="+logOpts)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, commitFindings...)
+	}
+	return findings, nil
+}
+
+// run executes gitleaks detect with extra arguments and parses the report
+func (s *GitleaksScanner) run(ctx context.Context, args ...string) ([]Finding, error) {
+	tmp, err := os.MkdirTemp("", "gitleaks-diff-comment-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	binary := s.Binary
+	if binary == "" {
+		binary = "gitleaks"
+	}
+	source := s.Dir
+	if source == "" {
+		source = "."
+	}
+	reportPath := filepath.Join(tmp, "report.json")
+
+	cmdArgs := append([]string{
+		"detect",
+		"--source", source,
+		"--report-format", "json",
+		"--report-path", reportPath,
+		// Findings are always reported; a leak is not a scan failure here
+		"--exit-code", "0",
+		// Point at an empty directory so no .gitleaksignore is applied
+		"--gitleaks-ignore-path", tmp,
+		"--redact",
+		"--no-banner",
+	}, args...)
+
+	cmd := exec.CommandContext(ctx, binary, cmdArgs...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s detect: %w (%s)", binary, err, msg)
+		}
+		return nil, fmt.Errorf("%s detect: %w", binary, err)
+	}
+
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gitleaks report: %w", err)
+	}
+	findings, err := ParseGitleaksReport(report)
+	if err != nil {
+		return nil, err
+	}
+
+	// Normalize reported paths to be relative to the repository root
+	for i := range findings {
+		findings[i].File = strings.TrimPrefix(normalizePath(findings[i].File), "./")
+		if source != "." {
+			findings[i].File = strings.TrimPrefix(findings[i].File, normalizePath(source)+"/")
+		}
+	}
+	return findings, nil
+}
+
+// ParseGitleaksReport parses a gitleaks JSON report
+func ParseGitleaksReport(report []byte) ([]Finding, error) {
+	if len(bytes.TrimSpace(report)) == 0 {
+		return nil, nil
+	}
+
+	var findings []Finding
+	if err := json.Unmarshal(report, &findings); err != nil {
+		return nil, fmt.Errorf("failed to parse gitleaks report: %w", err)
+	}
+	return findings, nil
+}
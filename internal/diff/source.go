@@ -0,0 +1,61 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+)
+
+// Supported diff sources
+const (
+	SourceAuto = "auto"
+	SourceGit  = "git"
+	SourceAPI  = "api"
+)
+
+// Source produces the ignore file changes for a pull request
+type Source interface {
+	// Changes returns the changes to all matching ignore files
+	Changes(ctx context.Context) ([]DiffChange, error)
+
+	// InlineAllows returns gitleaks:allow annotations added anywhere in the pull request
+	InlineAllows(ctx context.Context) ([]InlineAllow, error)
+
+	// Name returns a human-readable description of the source for logging
+	Name() string
+}
+
+// GitSource computes changes from a local repository through an ObjectReader
+type GitSource struct {
+	Reader ObjectReader
+	Range  Range
+
+	// Patterns are the globs selecting which ignore files to diff
+	Patterns []string
+}
+
+// NewGitSource creates a Source that diffs the ignore files matching patterns in rng
+func NewGitSource(reader ObjectReader, rng Range, patterns []string) *GitSource {
+	return &GitSource{Reader: reader, Range: rng, Patterns: patterns}
+}
+
+// Changes computes the ignore file diffs for the source's range
+func (s *GitSource) Changes(ctx context.Context) ([]DiffChange, error) {
+	return ParseGitleaksDiff(ctx, s.Reader, s.Range, s.Patterns)
+}
+
+// InlineAllows scans every file changed in the source's range
+func (s *GitSource) InlineAllows(ctx context.Context) ([]InlineAllow, error) {
+	return ParseInlineAllows(ctx, s.Reader, s.Range)
+}
+
+// Name returns a description including the range being diffed
+func (s *GitSource) Name() string {
+	return fmt.Sprintf("git (%s)", s.Range)
+}
+
+// ParsePatch parses a unified diff patch for the ignore file at path
+// The patch may be a full `git diff` output or just the hunks, as returned
+// in the "patch" field of the GitHub pull request files API.
+func ParsePatch(path string, patch []byte) ([]DiffChange, error) {
+	return parseDiffOutput(path, patch)
+}
@@ -0,0 +1,370 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tomlScanner is a small line-tracking scanner for gitleaks configuration files
+// It understands just enough TOML (tables, arrays of tables, arrays, strings
+// and comments) to locate allowlist array elements and their line numbers.
+// Values it does not care about are skipped without being interpreted.
+type tomlScanner struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+// tomlString is a string value together with the line it starts on
+type tomlString struct {
+	Value string
+	Line  int
+}
+
+// tomlValue is a parsed value: either a single string, an array of strings,
+// or something else (Skipped) that the scanner does not interpret
+type tomlValue struct {
+	Strings []tomlString
+	IsArray bool
+	Skipped bool
+
+	// Raw is the literal text of a number, boolean or date
+	Raw string
+}
+
+func newTOMLScanner(content []byte) *tomlScanner {
+	return &tomlScanner{src: []rune(string(content)), line: 1}
+}
+
+func (s *tomlScanner) eof() bool {
+	return s.pos >= len(s.src)
+}
+
+func (s *tomlScanner) peek() rune {
+	if s.eof() {
+		return 0
+	}
+	return s.src[s.pos]
+}
+
+func (s *tomlScanner) hasPrefix(prefix string) bool {
+	r := []rune(prefix)
+	if s.pos+len(r) > len(s.src) {
+		return false
+	}
+	for i := range r {
+		if s.src[s.pos+i] != r[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *tomlScanner) next() rune {
+	c := s.src[s.pos]
+	s.pos++
+	if c == '\n' {
+		s.line++
+	}
+	return c
+}
+
+// skipSpace skips spaces and tabs, and newlines and comments if multiline is set
+func (s *tomlScanner) skipSpace(multiline bool) {
+	for !s.eof() {
+		switch c := s.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			s.next()
+		case c == '#':
+			for !s.eof() && s.peek() != '\n' {
+				s.next()
+			}
+		case c == '\n' && multiline:
+			s.next()
+		default:
+			return
+		}
+	}
+}
+
+// skipLine skips to the start of the next line
+func (s *tomlScanner) skipLine() {
+	for !s.eof() && s.next() != '\n' {
+	}
+}
+
+// walk scans the whole document, calling onHeader for each table header and
+// onKey for each key/value pair, in document order
+func (s *tomlScanner) walk(onHeader func(name string, isArray bool), onKey func(line int, key string, value tomlValue) error) error {
+	for {
+		s.skipSpace(true)
+		if s.eof() {
+			return nil
+		}
+
+		if s.peek() == '[' {
+			name, isArray, err := s.header()
+			if err != nil {
+				return err
+			}
+			onHeader(name, isArray)
+			s.skipLine()
+			continue
+		}
+
+		line := s.line
+		key, err := s.key()
+		if err != nil {
+			return err
+		}
+		value, err := s.value()
+		if err != nil {
+			return err
+		}
+		if err := onKey(line, key, value); err != nil {
+			return err
+		}
+
+		s.skipSpace(false)
+		if !s.eof() && s.peek() != '\n' {
+			return fmt.Errorf("line %d: unexpected content after value of %q", line, key)
+		}
+	}
+}
+
+// header reads a [table] or [[array.table]] header
+// The opening bracket must be the current character.
+func (s *tomlScanner) header() (name string, isArray bool, err error) {
+	line := s.line
+	s.next()
+	if s.peek() == '[' {
+		s.next()
+		isArray = true
+	}
+
+	var b strings.Builder
+	for !s.eof() && s.peek() != ']' && s.peek() != '\n' {
+		b.WriteRune(s.next())
+	}
+	if s.eof() || s.peek() != ']' {
+		return "", false, fmt.Errorf("line %d: unterminated table header", line)
+	}
+	s.next()
+	if isArray {
+		if s.peek() != ']' {
+			return "", false, fmt.Errorf("line %d: unterminated array table header", line)
+		}
+		s.next()
+	}
+	return normalizeKey(b.String()), isArray, nil
+}
+
+// key reads a (possibly dotted or quoted) key up to and including the '='
+func (s *tomlScanner) key() (string, error) {
+	line := s.line
+	var b strings.Builder
+	for !s.eof() && s.peek() != '=' {
+		c := s.peek()
+		if c == '\n' || c == '#' {
+			return "", fmt.Errorf("line %d: expected '=' after key %q", line, strings.TrimSpace(b.String()))
+		}
+		if c == '"' || c == '\'' {
+			str, err := s.basicOrLiteral()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(str)
+			continue
+		}
+		b.WriteRune(s.next())
+	}
+	if s.eof() {
+		return "", fmt.Errorf("line %d: expected '=' after key %q", line, strings.TrimSpace(b.String()))
+	}
+	s.next()
+	return normalizeKey(b.String()), nil
+}
+
+// value reads the value following a key
+func (s *tomlScanner) value() (tomlValue, error) {
+	s.skipSpace(false)
+	switch c := s.peek(); c {
+	case '[':
+		return s.array()
+	case '"', '\'':
+		line := s.line
+		str, err := s.str()
+		if err != nil {
+			return tomlValue{}, err
+		}
+		return tomlValue{Strings: []tomlString{{Value: str, Line: line}}}, nil
+	case '{':
+		return tomlValue{Skipped: true}, s.skipInlineTable()
+	default:
+		// Numbers, booleans and dates end at a delimiter
+		var b strings.Builder
+		for !s.eof() && !strings.ContainsRune(",]}#\n", s.peek()) {
+			b.WriteRune(s.next())
+		}
+		return tomlValue{Skipped: true, Raw: strings.TrimSpace(b.String())}, nil
+	}
+}
+
+// array reads an array, collecting its string elements
+// Nested arrays and inline tables are skipped.
+func (s *tomlScanner) array() (tomlValue, error) {
+	line := s.line
+	s.next()
+	result := tomlValue{IsArray: true}
+
+	for {
+		s.skipSpace(true)
+		if s.eof() {
+			return result, fmt.Errorf("line %d: unterminated array", line)
+		}
+		if s.peek() == ']' {
+			s.next()
+			return result, nil
+		}
+
+		elem, err := s.value()
+		if err != nil {
+			return result, err
+		}
+		if elem.IsArray || elem.Skipped {
+			result.Skipped = true
+		} else {
+			result.Strings = append(result.Strings, elem.Strings...)
+		}
+
+		s.skipSpace(true)
+		if s.peek() == ',' {
+			s.next()
+		}
+	}
+}
+
+// skipInlineTable skips over an inline table, including nested values
+func (s *tomlScanner) skipInlineTable() error {
+	line := s.line
+	s.next()
+	for {
+		s.skipSpace(true)
+		if s.eof() {
+			return fmt.Errorf("line %d: unterminated inline table", line)
+		}
+		switch s.peek() {
+		case '}':
+			s.next()
+			return nil
+		case ',':
+			s.next()
+		default:
+			if _, err := s.key(); err != nil {
+				return err
+			}
+			if _, err := s.value(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// str reads any of the four TOML string forms
+func (s *tomlScanner) str() (string, error) {
+	switch {
+	case s.hasPrefix(`"""`):
+		return s.multiline(`"""`, true)
+	case s.hasPrefix(`'''`):
+		return s.multiline(`'''`, false)
+	default:
+		return s.basicOrLiteral()
+	}
+}
+
+// basicOrLiteral reads a single-line "basic" or 'literal' string
+func (s *tomlScanner) basicOrLiteral() (string, error) {
+	line := s.line
+	quote := s.next()
+
+	var b strings.Builder
+	for {
+		if s.eof() || s.peek() == '\n' {
+			return "", fmt.Errorf("line %d: unterminated string", line)
+		}
+		c := s.next()
+		if c == quote {
+			break
+		}
+		b.WriteRune(c)
+		if c == '\\' && quote == '"' && !s.eof() && s.peek() != '\n' {
+			b.WriteRune(s.next())
+		}
+	}
+
+	if quote == '\'' {
+		return b.String(), nil
+	}
+	return unescapeBasic(b.String()), nil
+}
+
+// multiline reads a multi-line basic or literal string, which may span several lines
+func (s *tomlScanner) multiline(delim string, basic bool) (string, error) {
+	line := s.line
+	s.pos += len(delim)
+
+	// A newline immediately after the opening delimiter is trimmed
+	if s.hasPrefix("\r\n") {
+		s.next()
+	}
+	if s.peek() == '\n' {
+		s.next()
+	}
+
+	var b strings.Builder
+	for {
+		if s.eof() {
+			return "", fmt.Errorf("line %d: unterminated multi-line string", line)
+		}
+		if s.hasPrefix(delim) {
+			s.pos += len(delim)
+			// Up to two quotes may directly precede the closing delimiter
+			for i := 0; i < 2 && s.hasPrefix(delim[:1]); i++ {
+				b.WriteRune(s.next())
+			}
+			break
+		}
+		c := s.next()
+		b.WriteRune(c)
+		if c == '\\' && basic && !s.eof() {
+			b.WriteRune(s.next())
+		}
+	}
+
+	if !basic {
+		return b.String(), nil
+	}
+	return unescapeBasic(b.String()), nil
+}
+
+// unescapeBasic decodes escape sequences in a basic string
+// Invalid escapes are left as written rather than rejected.
+func unescapeBasic(raw string) string {
+	if !strings.Contains(raw, `\`) {
+		return raw
+	}
+	if unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(raw, "\n", `\n`) + `"`); err == nil {
+		return unquoted
+	}
+	return raw
+}
+
+// normalizeKey trims whitespace around each part of a dotted key
+func normalizeKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return strings.Join(parts, ".")
+}
@@ -2,27 +2,47 @@ package diff
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
 // DiffChange represents a single line change in .gitleaksignore
 type DiffChange struct {
-	// File path (always ".gitleaksignore" for this feature)
+	// Path of the ignore file the line belongs to
 	FilePath string `json:"file_path"`
 
-	// Operation type: "addition" or "deletion"
+	// Operation type: "addition", "deletion" or "modification"
 	Operation OperationType `json:"operation"`
 
 	// Line number in the new version (0 if deletion)
 	LineNumber int `json:"line_number"`
 
+	// Line number in the old version (0 if addition)
+	OldLineNumber int `json:"old_line_number,omitempty"`
+
 	// Raw line content (the gitleaks pattern/file path)
 	Content string `json:"content"`
 
+	// Raw line content before the change (modifications only)
+	PreviousContent string `json:"previous_content,omitempty"`
+
 	// Position in the diff for PR comment placement (1-indexed)
 	Position int `json:"position"`
+
+	// Validation of the referenced file and line (nil if not checked)
+	Validation *EntryValidation `json:"validation,omitempty"`
+
+	// Verification against a gitleaks scan of the head (nil if not run)
+	Verification *EntryVerification `json:"verification,omitempty"`
+
+	// Justification from the comment block above the entry (nil if none)
+	Metadata *EntryMetadata `json:"metadata,omitempty"`
+
+	// Policy rules the entry breaks (nil if none, or no policy is configured)
+	Violations []PolicyViolation `json:"violations,omitempty"`
 }
 
 // OperationType represents the type of change
@@ -31,6 +51,10 @@ type OperationType string
 const (
 	OperationAddition OperationType = "addition"
 	OperationDeletion OperationType = "deletion"
+
+	// OperationModification is an entry edited in place, e.g. a changed line
+	// number or rule ID for the same file
+	OperationModification OperationType = "modification"
 )
 
 // IsAddition returns true if this is an addition
@@ -43,11 +67,48 @@ func (d *DiffChange) IsDeletion() bool {
 	return d.Operation == OperationDeletion
 }
 
+// IsModification returns true if this is a modification of an existing entry
+func (d *DiffChange) IsModification() bool {
+	return d.Operation == OperationModification
+}
+
+// Entry parses Content, resolving its path relative to the ignore file
+func (d *DiffChange) Entry() (*GitleaksEntry, error) {
+	entry, err := d.parseEntry(d.Content)
+	if err != nil {
+		return nil, err
+	}
+	entry.Metadata = d.Metadata
+	return entry, nil
+}
+
+// PreviousEntry parses PreviousContent, resolving its path relative to the ignore file
+func (d *DiffChange) PreviousEntry() (*GitleaksEntry, error) {
+	return d.parseEntry(d.PreviousContent)
+}
+
+func (d *DiffChange) parseEntry(content string) (*GitleaksEntry, error) {
+	entry, err := ParseGitleaksEntry(content)
+	if err != nil {
+		return nil, err
+	}
+	if d.FilePath != "" {
+		entry.ResolveRelativeTo(d.FilePath)
+	}
+	return entry, nil
+}
+
 // GitleaksEntry represents a parsed entry from .gitleaksignore
 type GitleaksEntry struct {
+	// Commit SHA the finding was reported in (git scan fingerprints only)
+	Commit string `json:"commit,omitempty"`
+
 	// File path or pattern being ignored
 	FilePattern string `json:"file_pattern"`
 
+	// Gitleaks rule ID (e.g. "generic-api-key"), empty if not specified
+	RuleID string `json:"rule_id,omitempty"`
+
 	// Optional line number in the file (0 if not specified)
 	LineNumber int `json:"line_number,omitempty"`
 
@@ -56,9 +117,31 @@ type GitleaksEntry struct {
 
 	// Original line from .gitleaksignore
 	OriginalLine string `json:"original_line"`
+
+	// Justification from the comment block above the entry (nil if none)
+	Metadata *EntryMetadata `json:"metadata,omitempty"`
 }
 
+var (
+	// commitRegex matches a full SHA-1 or SHA-256 commit hash
+	commitRegex = regexp.MustCompile(`^(?:[0-9a-f]{40}|[0-9a-f]{64})$`)
+
+	// ruleIDRegex matches gitleaks rule IDs (e.g. "aws-access-token", "generic-api-key")
+	// Dots and path separators are excluded so file names are not mistaken for rules
+	ruleIDRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+)
+
 // ParseGitleaksEntry parses a line from .gitleaksignore into a GitleaksEntry
+//
+// Supported formats:
+//
+//	commit:file:rule-id:line  (gitleaks detect, git scan fingerprint)
+//	file:rule-id:line         (gitleaks detect --no-git / dir scan fingerprint)
+//	file:line
+//	file or pattern           (e.g. *.env)
+//
+// File paths may contain colons and Windows separators; backslashes are
+// normalized to forward slashes.
 func ParseGitleaksEntry(line string) (*GitleaksEntry, error) {
 	line = strings.TrimSpace(line)
 	if line == "" || strings.HasPrefix(line, "#") {
@@ -67,45 +150,67 @@ func ParseGitleaksEntry(line string) (*GitleaksEntry, error) {
 
 	entry := &GitleaksEntry{
 		OriginalLine: line,
-		IsPattern:    strings.ContainsAny(line, "*?[]"),
 	}
 
-	// Parse gitleaks format: file:rule:line or file:line
-	// Examples:
-	//   DUMMY.txt:base64-encoded-secrets:1 -> file=DUMMY.txt, line=1
-	//   config/secrets.yml:23 -> file=config/secrets.yml, line=23
-	//   *.env -> file=*.env, line=0
 	parts := strings.Split(line, ":")
 
+	// Fingerprints always end with a line number
 	if len(parts) >= 2 {
-		// Check if last part is a line number
-		lastPart := parts[len(parts)-1]
-		if lineNum, err := strconv.Atoi(lastPart); err == nil {
-			// Last part is a line number
-			// Everything before the last colon is the file path
-			filePath := strings.Join(parts[:len(parts)-1], ":")
-
-			// If there are 3+ parts, extract just the file name (first part)
-			if len(parts) >= 3 {
-				// Format: file:rule:line -> use first part only
-				entry.FilePattern = parts[0]
-			} else {
-				// Format: file:line -> use everything before last colon
-				entry.FilePattern = filePath
-			}
+		if lineNum, err := strconv.Atoi(parts[len(parts)-1]); err == nil && lineNum >= 0 {
 			entry.LineNumber = lineNum
-			return entry, nil
+			parts = parts[:len(parts)-1]
+
+			// commit:file:rule (git scan) - needs at least file and rule after the commit
+			if len(parts) >= 3 && commitRegex.MatchString(parts[0]) {
+				entry.Commit = parts[0]
+				parts = parts[1:]
+			}
+
+			// file:rule - the rule never contains dots or path separators
+			if len(parts) >= 2 && ruleIDRegex.MatchString(parts[len(parts)-1]) {
+				entry.RuleID = parts[len(parts)-1]
+				parts = parts[:len(parts)-1]
+			}
 		}
 	}
 
-	// No line number found, use the whole line as pattern
-	entry.FilePattern = line
+	// Whatever remains is the file path, which may itself contain colons
+	entry.FilePattern = normalizePath(strings.Join(parts, ":"))
+	entry.IsPattern = isWildcard(entry.FilePattern)
+
 	return entry, nil
 }
 
+// normalizePath converts Windows-style separators to forward slashes
+func normalizePath(path string) string {
+	return strings.ReplaceAll(path, "\\", "/")
+}
+
+// ResolveRelativeTo rewrites FilePattern to be relative to the repository root
+// Entries in a nested ignore file (e.g. services/api/.gitleaksignore) refer to
+// paths relative to that file's directory. A leading "/" anchors the entry to
+// the ignore file's directory as well. Entries in the root file are unchanged.
+func (e *GitleaksEntry) ResolveRelativeTo(ignoreFilePath string) {
+	dir := path.Dir(normalizePath(ignoreFilePath))
+	if dir == "." || dir == "/" || e.FilePattern == "" {
+		return
+	}
+	e.FilePattern = path.Join(dir, strings.TrimPrefix(e.FilePattern, "/"))
+}
+
+// ShortCommit returns the abbreviated commit SHA, or empty if none
+func (e *GitleaksEntry) ShortCommit() string {
+	if len(e.Commit) > 7 {
+		return e.Commit[:7]
+	}
+	return e.Commit
+}
+
 // FileLink generates a GitHub file link for this entry
 // ghHost should be the GitHub Enterprise Server hostname (e.g., "github.company.com")
 // or empty string for GitHub.com
+// If the entry came from a git scan fingerprint, the link points at the commit
+// the finding was reported in rather than commitSHA.
 func (e *GitleaksEntry) FileLink(repo, commitSHA, ghHost string) string {
 	// Determine base URL based on ghHost
 	baseURL := "https://github.com"
@@ -113,6 +218,10 @@ func (e *GitleaksEntry) FileLink(repo, commitSHA, ghHost string) string {
 		baseURL = "https://" + ghHost
 	}
 
+	if e.Commit != "" {
+		commitSHA = e.Commit
+	}
+
 	// For patterns with wildcards, link to parent directory
 	path := e.FilePattern
 	if e.IsPattern {
@@ -94,6 +94,103 @@ func TestParseGitleaksEntry_WithLineNumber(t *testing.T) {
 	}
 }
 
+func TestParseGitleaksEntry_Fingerprints(t *testing.T) {
+	const sha = "cd5226711335c68be1e720b318b7bc3135a30eb2"
+
+	tests := []struct {
+		name        string
+		input       string
+		wantCommit  string
+		wantPattern string
+		wantRule    string
+		wantLineNum int
+	}{
+		{
+			name:        "git scan fingerprint",
+			input:       sha + ":cmd/generate/config/rules/sidekiq.go:sidekiq-secret:23",
+			wantCommit:  sha,
+			wantPattern: "cmd/generate/config/rules/sidekiq.go",
+			wantRule:    "sidekiq-secret",
+			wantLineNum: 23,
+		},
+		{
+			name:        "dir scan fingerprint",
+			input:       "DUMMY.txt:base64-encoded-secrets:1",
+			wantPattern: "DUMMY.txt",
+			wantRule:    "base64-encoded-secrets",
+			wantLineNum: 1,
+		},
+		{
+			name:        "file with line only",
+			input:       "config/secrets.yml:42",
+			wantPattern: "config/secrets.yml",
+			wantLineNum: 42,
+		},
+		{
+			name:        "windows path in dir scan fingerprint",
+			input:       `C:\Users\dev\repo\config\app.env:generic-api-key:7`,
+			wantPattern: "C:/Users/dev/repo/config/app.env",
+			wantRule:    "generic-api-key",
+			wantLineNum: 7,
+		},
+		{
+			name:        "windows relative path in git scan fingerprint",
+			input:       sha + `:config\app.env:generic-api-key:7`,
+			wantCommit:  sha,
+			wantPattern: "config/app.env",
+			wantRule:    "generic-api-key",
+			wantLineNum: 7,
+		},
+		{
+			name:        "path containing colons with rule",
+			input:       "docs/time:12:00.txt:generic-api-key:3",
+			wantPattern: "docs/time:12:00.txt",
+			wantRule:    "generic-api-key",
+			wantLineNum: 3,
+		},
+		{
+			name:        "git scan fingerprint with path containing colons",
+			input:       sha + ":data/a:b.json:aws-access-token:9",
+			wantCommit:  sha,
+			wantPattern: "data/a:b.json",
+			wantRule:    "aws-access-token",
+			wantLineNum: 9,
+		},
+		{
+			name:        "short hex file name is not a commit",
+			input:       "deadbeef:generic-api-key:2",
+			wantPattern: "deadbeef",
+			wantRule:    "generic-api-key",
+			wantLineNum: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := ParseGitleaksEntry(tt.input)
+			if err != nil {
+				t.Fatalf("ParseGitleaksEntry() unexpected error: %v", err)
+			}
+
+			if entry.Commit != tt.wantCommit {
+				t.Errorf("Commit = %q, want %q", entry.Commit, tt.wantCommit)
+			}
+			if entry.FilePattern != tt.wantPattern {
+				t.Errorf("FilePattern = %q, want %q", entry.FilePattern, tt.wantPattern)
+			}
+			if entry.RuleID != tt.wantRule {
+				t.Errorf("RuleID = %q, want %q", entry.RuleID, tt.wantRule)
+			}
+			if entry.LineNumber != tt.wantLineNum {
+				t.Errorf("LineNumber = %d, want %d", entry.LineNumber, tt.wantLineNum)
+			}
+			if entry.OriginalLine != tt.input {
+				t.Errorf("OriginalLine = %q, want %q", entry.OriginalLine, tt.input)
+			}
+		})
+	}
+}
+
 func TestGitleaksEntry_HasLineNumber(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -122,6 +219,34 @@ func TestGitleaksEntry_HasLineNumber(t *testing.T) {
 	}
 }
 
+func TestGitleaksEntry_ResolveRelativeTo(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		ignoreFile string
+		expected   string
+	}{
+		{"root ignore file", "config/app.yml:3", ".gitleaksignore", "config/app.yml"},
+		{"nested ignore file", "config/app.yml:3", "services/payments/.gitleaksignore", "services/payments/config/app.yml"},
+		{"anchored entry", "/config/app.yml", "services/payments/.gitleaksignore", "services/payments/config/app.yml"},
+		{"wildcard entry", "**/*.env", "services/payments/.gitleaksignore", "services/payments/**/*.env"},
+		{"fingerprint", "cd5226711335c68be1e720b318b7bc3135a30eb2:app.yml:generic-api-key:12", "svc/.gitleaksignore", "svc/app.yml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := ParseGitleaksEntry(tt.line)
+			if err != nil {
+				t.Fatalf("ParseGitleaksEntry() unexpected error: %v", err)
+			}
+			entry.ResolveRelativeTo(tt.ignoreFile)
+			if entry.FilePattern != tt.expected {
+				t.Errorf("FilePattern = %q, want %q", entry.FilePattern, tt.expected)
+			}
+		})
+	}
+}
+
 func TestGitleaksEntry_FileLink(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -186,6 +311,19 @@ func TestGitleaksEntry_FileLink(t *testing.T) {
 			ghHost:    "github.company.com:8443",
 			expected:  "https://github.company.com:8443/owner/repo/blob/def456/secrets",
 		},
+		{
+			name: "git scan fingerprint links to finding commit",
+			entry: GitleaksEntry{
+				Commit:      "cd5226711335c68be1e720b318b7bc3135a30eb2",
+				FilePattern: "config/app.yml",
+				RuleID:      "generic-api-key",
+				LineNumber:  12,
+			},
+			repo:      "owner/repo",
+			commitSHA: "abc123",
+			ghHost:    "",
+			expected:  "https://github.com/owner/repo/blob/cd5226711335c68be1e720b318b7bc3135a30eb2/config/app.yml#L12",
+		},
 		{
 			name: "file with line number - Enterprise Server",
 			entry: GitleaksEntry{
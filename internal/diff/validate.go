@@ -0,0 +1,241 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// EntryValidation records whether the file and line an entry refers to exist
+type EntryValidation struct {
+	// Revision is the commit the file was checked at
+	Revision string `json:"revision"`
+
+	// FileExists is true if the file exists at Revision
+	FileExists bool `json:"file_exists"`
+
+	// LineCount is the number of lines in the file (0 if missing)
+	LineCount int `json:"line_count"`
+
+	// LineExists is true if the entry's line is within the file
+	// Always true for entries without a line number.
+	LineExists bool `json:"line_exists"`
+
+	// Matches are the files a wildcard entry matches at Revision, in path order
+	// For wildcard entries FileExists is true if there is at least one match.
+	Matches []string `json:"matches,omitempty"`
+
+	// MatchThreshold is the match count above which a pattern is considered too broad
+	MatchThreshold int `json:"match_threshold,omitempty"`
+
+	// Snippet is the redacted entry line with surrounding context
+	// Empty unless the validator has a Redactor and the line exists.
+	Snippet []SnippetLine `json:"snippet,omitempty"`
+}
+
+// SnippetLine is one line of a redacted snippet
+type SnippetLine struct {
+	// Number is the 1-indexed line number in the file
+	Number int `json:"number"`
+
+	// Text is the line with secrets masked
+	Text string `json:"text"`
+
+	// Target is true for the line the entry refers to
+	Target bool `json:"target,omitempty"`
+}
+
+// LineRedactor masks secrets in a line of source before it is displayed
+type LineRedactor interface {
+	// RedactLine returns line with any secret replaced by a placeholder
+	// ruleID is the gitleaks rule the finding was reported for, or empty.
+	RedactLine(line, ruleID string) string
+
+	// RedactFinding returns the line an entry refers to, which is known to
+	// hold a secret, masked entirely unless the secret could be located
+	RedactFinding(line, ruleID string) string
+}
+
+// DefaultSnippetContext is the number of lines shown before and after the entry line
+const DefaultSnippetContext = 2
+
+// maxSnippetLineLength is the number of characters kept from each snippet line
+const maxSnippetLineLength = 160
+
+// DefaultMatchThreshold is the default number of matches above which a
+// wildcard entry is flagged as overly broad
+const DefaultMatchThreshold = 25
+
+// IsBroad returns true if a wildcard entry matches more files than the threshold
+func (v *EntryValidation) IsBroad() bool {
+	return v.MatchThreshold > 0 && len(v.Matches) > v.MatchThreshold
+}
+
+// EntryValidator checks gitleaks entries against file contents at a commit
+// File contents are read once per path and revision, and tree listings once
+// per revision.
+type EntryValidator struct {
+	reader   BlobReader
+	revision string
+
+	// MatchThreshold is copied into each wildcard validation (default DefaultMatchThreshold)
+	MatchThreshold int
+
+	// Redactor masks snippet lines; snippets are omitted if nil
+	Redactor LineRedactor
+
+	// SnippetContext is the number of lines shown around the entry line (default DefaultSnippetContext)
+	SnippetContext int
+
+	// files caches the lines per revision:path (nil if missing)
+	files map[string][]string
+
+	// trees caches the file listing per revision
+	trees map[string][]string
+}
+
+// NewEntryValidator creates a validator reading files at revision through reader
+// Wildcard entries are expanded only if reader also implements TreeReader.
+func NewEntryValidator(reader BlobReader, revision string) *EntryValidator {
+	return &EntryValidator{
+		reader:         reader,
+		revision:       revision,
+		MatchThreshold: DefaultMatchThreshold,
+		SnippetContext: DefaultSnippetContext,
+		files:          make(map[string][]string),
+		trees:          make(map[string][]string),
+	}
+}
+
+// Validate checks that the file and line referenced by entry exist
+// Git scan fingerprints are checked at the commit the finding was reported
+// in, matching the link in the comment; other entries at the validator's
+// revision. Wildcard patterns are expanded against the tree at that revision,
+// or return nil if the reader cannot list trees.
+func (v *EntryValidator) Validate(ctx context.Context, entry *GitleaksEntry) (*EntryValidation, error) {
+	if entry.FilePattern == "" {
+		return nil, nil
+	}
+
+	revision := v.revision
+	if entry.Commit != "" {
+		revision = entry.Commit
+	}
+
+	if entry.IsPattern {
+		return v.expand(ctx, revision, entry.FilePattern)
+	}
+
+	key := revision + ":" + entry.FilePattern
+	lines, ok := v.files[key]
+	if !ok {
+		content, err := v.reader.ReadBlob(ctx, revision, entry.FilePattern)
+		switch {
+		case errors.Is(err, ErrObjectNotFound):
+			lines = nil
+		case err != nil:
+			return nil, fmt.Errorf("failed to check %s at %s: %w", entry.FilePattern, revision, err)
+		default:
+			lines = splitLines(content)
+			if lines == nil {
+				lines = []string{}
+			}
+		}
+		v.files[key] = lines
+	}
+
+	if lines == nil {
+		return &EntryValidation{Revision: revision}, nil
+	}
+	validation := &EntryValidation{
+		Revision:   revision,
+		FileExists: true,
+		LineCount:  len(lines),
+		LineExists: !entry.HasLineNumber() || entry.LineNumber <= len(lines),
+	}
+	if entry.HasLineNumber() && validation.LineExists {
+		validation.Snippet = v.snippet(lines, entry.LineNumber, entry.RuleID)
+	}
+	return validation, nil
+}
+
+// snippet returns the redacted lines around line number target
+func (v *EntryValidator) snippet(lines []string, target int, ruleID string) []SnippetLine {
+	if v.Redactor == nil {
+		return nil
+	}
+
+	span := max(v.SnippetContext, 0)
+	start := max(target-span, 1)
+	end := min(target+span, len(lines))
+
+	snippet := make([]SnippetLine, 0, end-start+1)
+	for n := start; n <= end; n++ {
+		// Every line is redacted: context lines may hold secrets of their own
+		text := v.Redactor.RedactLine(lines[n-1], ruleID)
+		if n == target {
+			text = v.Redactor.RedactFinding(lines[n-1], ruleID)
+		}
+		if runes := []rune(text); len(runes) > maxSnippetLineLength {
+			text = string(runes[:maxSnippetLineLength]) + "…"
+		}
+		snippet = append(snippet, SnippetLine{Number: n, Text: text, Target: n == target})
+	}
+	return snippet
+}
+
+// expand lists the files at revision matching pattern
+func (v *EntryValidator) expand(ctx context.Context, revision, pattern string) (*EntryValidation, error) {
+	lister, ok := v.reader.(TreeReader)
+	if !ok {
+		return nil, nil
+	}
+
+	files, ok := v.trees[revision]
+	if !ok {
+		var err error
+		files, err = lister.ListFiles(ctx, revision)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand %s at %s: %w", pattern, revision, err)
+		}
+		v.trees[revision] = files
+	}
+
+	var matches []string
+	for _, file := range files {
+		if MatchEntryPattern(pattern, file) {
+			matches = append(matches, file)
+		}
+	}
+
+	return &EntryValidation{
+		Revision:       revision,
+		FileExists:     len(matches) > 0,
+		LineExists:     len(matches) > 0,
+		Matches:        matches,
+		MatchThreshold: v.MatchThreshold,
+	}, nil
+}
+
+// ValidateChanges sets Validation on every change
+// Deletions are looked up too so their comments can show the line that will
+// be scanned again. Lookup failures leave the change unvalidated and are
+// returned together after all changes have been processed.
+func (v *EntryValidator) ValidateChanges(ctx context.Context, changes []DiffChange) error {
+	var errs []error
+	for i := range changes {
+		change := &changes[i]
+		entry, err := change.Entry()
+		if err != nil {
+			continue // Reported when the comment is generated
+		}
+
+		validation, err := v.Validate(ctx, entry)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		change.Validation = validation
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,293 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeBlobReader serves file contents from a map keyed by "rev:path"
+type fakeBlobReader struct {
+	files map[string]string
+	reads int
+	err   error
+}
+
+func (r *fakeBlobReader) ReadBlob(ctx context.Context, rev, path string) ([]byte, error) {
+	r.reads++
+	if r.err != nil {
+		return nil, r.err
+	}
+	content, ok := r.files[rev+":"+path]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return []byte(content), nil
+}
+
+func TestEntryValidator_Validate(t *testing.T) {
+	commit := "cd5226711335c68be1e720b318b7bc3135a30eb2"
+	reader := &fakeBlobReader{files: map[string]string{
+		"head:config/app.yml":   "a\nb\nc\n",
+		commit + ":old/key.pem": "x\n",
+	}}
+	validator := NewEntryValidator(reader, "head")
+
+	tests := []struct {
+		line       string
+		wantNil    bool
+		fileExists bool
+		lineExists bool
+		revision   string
+	}{
+		{line: "config/app.yml:3", fileExists: true, lineExists: true, revision: "head"},
+		{line: "config/app.yml:4", fileExists: true, lineExists: false, revision: "head"},
+		{line: "config/app.yml", fileExists: true, lineExists: true, revision: "head"},
+		{line: "config/missing.yml:1", fileExists: false, lineExists: false, revision: "head"},
+		{line: commit + ":old/key.pem:private-key:1", fileExists: true, lineExists: true, revision: commit},
+		{line: "config/*.yml", wantNil: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			entry, err := ParseGitleaksEntry(tt.line)
+			if err != nil {
+				t.Fatalf("ParseGitleaksEntry() unexpected error: %v", err)
+			}
+
+			got, err := validator.Validate(context.Background(), entry)
+			if err != nil {
+				t.Fatalf("Validate() unexpected error: %v", err)
+			}
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("Validate() = %+v, want nil for wildcard pattern", got)
+				}
+				return
+			}
+			if got == nil || got.FileExists != tt.fileExists || got.LineExists != tt.lineExists || got.Revision != tt.revision {
+				t.Errorf("Validate() = %+v, want file=%v line=%v at %s", got, tt.fileExists, tt.lineExists, tt.revision)
+			}
+		})
+	}
+
+	// config/app.yml, config/missing.yml and old/key.pem are each read once
+	if reader.reads != 3 {
+		t.Errorf("expected 3 reads with caching, got %d", reader.reads)
+	}
+}
+
+// fakeTreeReader adds a fixed tree listing to fakeBlobReader
+type fakeTreeReader struct {
+	fakeBlobReader
+	tree     []string
+	listings int
+}
+
+func (r *fakeTreeReader) ListFiles(ctx context.Context, rev string) ([]string, error) {
+	r.listings++
+	return r.tree, nil
+}
+
+func TestEntryValidator_ExpandsWildcards(t *testing.T) {
+	reader := &fakeTreeReader{tree: []string{
+		"database/dev.env",
+		"database/prod.env",
+		"database/seeds/test.env",
+		"svc/database/other.env",
+	}}
+	validator := NewEntryValidator(reader, "head")
+	validator.MatchThreshold = 1
+
+	tests := []struct {
+		pattern string
+		matches []string
+		broad   bool
+	}{
+		{"database/*.env", []string{"database/dev.env", "database/prod.env"}, true},
+		{"database/**/test.env", []string{"database/seeds/test.env"}, false},
+		{"other/*.env", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			entry, err := ParseGitleaksEntry(tt.pattern)
+			if err != nil {
+				t.Fatalf("ParseGitleaksEntry() unexpected error: %v", err)
+			}
+			got, err := validator.Validate(context.Background(), entry)
+			if err != nil {
+				t.Fatalf("Validate() unexpected error: %v", err)
+			}
+			if strings.Join(got.Matches, ",") != strings.Join(tt.matches, ",") {
+				t.Errorf("Matches = %v, want %v", got.Matches, tt.matches)
+			}
+			if got.FileExists != (len(tt.matches) > 0) {
+				t.Errorf("FileExists = %v with %d matches", got.FileExists, len(tt.matches))
+			}
+			if got.IsBroad() != tt.broad {
+				t.Errorf("IsBroad() = %v, want %v", got.IsBroad(), tt.broad)
+			}
+		})
+	}
+
+	if reader.listings != 1 {
+		t.Errorf("expected the tree to be listed once, got %d", reader.listings)
+	}
+}
+
+func TestEntryValidator_ValidateChanges(t *testing.T) {
+	reader := &fakeBlobReader{files: map[string]string{
+		"head:svc/config.yml": "a\n",
+	}}
+	changes := []DiffChange{
+		{FilePath: "svc/.gitleaksignore", Operation: OperationAddition, Content: "config.yml:1"},
+		{FilePath: "svc/.gitleaksignore", Operation: OperationDeletion, Content: "gone.yml:1"},
+		{FilePath: "svc/.gitleaksignore", Operation: OperationModification, Content: "config.yml:9", PreviousContent: "config.yml:1"},
+	}
+
+	if err := NewEntryValidator(reader, "head").ValidateChanges(context.Background(), changes); err != nil {
+		t.Fatalf("ValidateChanges() unexpected error: %v", err)
+	}
+
+	if v := changes[0].Validation; v == nil || !v.FileExists || !v.LineExists {
+		t.Errorf("changes[0].Validation = %+v, want existing file and line (resolved to svc/config.yml)", v)
+	}
+	if v := changes[1].Validation; v == nil || v.FileExists {
+		t.Errorf("changes[1].Validation = %+v, want missing file for the deleted entry", v)
+	}
+	if v := changes[2].Validation; v == nil || !v.FileExists || v.LineExists || v.LineCount != 1 {
+		t.Errorf("changes[2].Validation = %+v, want existing file with missing line 9", v)
+	}
+}
+
+func TestEntryValidator_ValidateChanges_ReadError(t *testing.T) {
+	reader := &fakeBlobReader{err: errors.New("rate limited")}
+	changes := []DiffChange{
+		{Operation: OperationAddition, Content: "a.yml:1"},
+	}
+
+	err := NewEntryValidator(reader, "head").ValidateChanges(context.Background(), changes)
+	if err == nil {
+		t.Fatal("ValidateChanges() expected error when the reader fails")
+	}
+	if changes[0].Validation != nil {
+		t.Errorf("change should be left unvalidated on error, got %+v", changes[0].Validation)
+	}
+}
+
+// upperRedactor marks redacted lines by upper-casing them
+type upperRedactor struct {
+	ruleIDs []string
+}
+
+func (r *upperRedactor) RedactLine(line, ruleID string) string {
+	r.ruleIDs = append(r.ruleIDs, ruleID)
+	return strings.ToUpper(line)
+}
+
+// RedactFinding brackets the line, so tests can tell the target was masked as a finding
+func (r *upperRedactor) RedactFinding(line, ruleID string) string {
+	r.ruleIDs = append(r.ruleIDs, ruleID)
+	return "[" + strings.ToUpper(line) + "]"
+}
+
+func TestEntryValidator_Snippet(t *testing.T) {
+	reader := &fakeBlobReader{files: map[string]string{
+		"head:app.env": "one\ntwo\nthree\nfour\nfive\nsix\n" + strings.Repeat("x", 200) + "\n",
+	}}
+
+	tests := []struct {
+		name   string
+		entry  string
+		redact bool
+		want   []SnippetLine
+	}{
+		{
+			name:   "context around the line",
+			entry:  "app.env:generic-api-key:4",
+			redact: true,
+			want: []SnippetLine{
+				{Number: 2, Text: "TWO"},
+				{Number: 3, Text: "THREE"},
+				{Number: 4, Text: "[FOUR]", Target: true},
+				{Number: 5, Text: "FIVE"},
+				{Number: 6, Text: "SIX"},
+			},
+		},
+		{
+			name:   "clipped at the start of the file",
+			entry:  "app.env:1",
+			redact: true,
+			want: []SnippetLine{
+				{Number: 1, Text: "[ONE]", Target: true},
+				{Number: 2, Text: "TWO"},
+				{Number: 3, Text: "THREE"},
+			},
+		},
+		{
+			name:   "no line number",
+			entry:  "app.env",
+			redact: true,
+		},
+		{
+			name:   "missing line",
+			entry:  "app.env:99",
+			redact: true,
+		},
+		{
+			name:  "no redactor",
+			entry: "app.env:4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewEntryValidator(reader, "head")
+			redactor := &upperRedactor{}
+			if tt.redact {
+				validator.Redactor = redactor
+			}
+
+			entry, err := ParseGitleaksEntry(tt.entry)
+			if err != nil {
+				t.Fatalf("ParseGitleaksEntry() unexpected error: %v", err)
+			}
+			got, err := validator.Validate(context.Background(), entry)
+			if err != nil {
+				t.Fatalf("Validate() unexpected error: %v", err)
+			}
+
+			if len(got.Snippet) != len(tt.want) {
+				t.Fatalf("Snippet = %+v, want %+v", got.Snippet, tt.want)
+			}
+			for i := range tt.want {
+				if got.Snippet[i] != tt.want[i] {
+					t.Errorf("Snippet[%d] = %+v, want %+v", i, got.Snippet[i], tt.want[i])
+				}
+			}
+			for _, ruleID := range redactor.ruleIDs {
+				if ruleID != entry.RuleID {
+					t.Errorf("RedactLine() called with rule %q, want %q", ruleID, entry.RuleID)
+				}
+			}
+		})
+	}
+}
+
+func TestEntryValidator_SnippetTruncatesLongLines(t *testing.T) {
+	reader := &fakeBlobReader{files: map[string]string{
+		"head:min.js": strings.Repeat("x", 500) + "\n",
+	}}
+	validator := NewEntryValidator(reader, "head")
+	validator.Redactor = &upperRedactor{}
+
+	got, err := validator.Validate(context.Background(), &GitleaksEntry{FilePattern: "min.js", LineNumber: 1})
+	if err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+	if len(got.Snippet) != 1 || got.Snippet[0].Text != "["+strings.Repeat("X", maxSnippetLineLength-1)+"…" {
+		t.Errorf("Snippet = %+v, want a single line truncated to %d characters", got.Snippet, maxSnippetLineLength)
+	}
+}
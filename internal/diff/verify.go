@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// EntryVerification records which current findings an entry suppresses
+type EntryVerification struct {
+	// Findings are the findings matched by the entry, in report order
+	Findings []Finding `json:"findings"`
+}
+
+// Suppresses returns true if the entry matches at least one finding
+func (v *EntryVerification) Suppresses() bool {
+	return len(v.Findings) > 0
+}
+
+// RuleIDs returns the distinct rules of the matched findings, sorted
+func (v *EntryVerification) RuleIDs() []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, f := range v.Findings {
+		if f.RuleID != "" && !seen[f.RuleID] {
+			seen[f.RuleID] = true
+			ids = append(ids, f.RuleID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// MatchesFinding reports whether the entry would suppress finding
+// Every part the entry specifies must agree with the finding: the commit for
+// git scan fingerprints, the file (or wildcard pattern), the rule and the line.
+func (e *GitleaksEntry) MatchesFinding(f Finding) bool {
+	if e.Commit != "" && e.Commit != f.Commit {
+		return false
+	}
+	if e.Commit == "" && f.Commit != "" {
+		// Only git scans report commits; entries without one refer to the working tree
+		return false
+	}
+
+	file := normalizePath(f.File)
+	if e.IsPattern {
+		if !MatchEntryPattern(e.FilePattern, file) {
+			return false
+		}
+	} else if e.FilePattern != file {
+		return false
+	}
+
+	if e.RuleID != "" && e.RuleID != f.RuleID {
+		return false
+	}
+	if e.HasLineNumber() && e.LineNumber != f.StartLine {
+		return false
+	}
+	return true
+}
+
+// VerifyChanges runs scanner over the pull request head and sets Verification
+// on every addition and modification in changes
+// Deletions are not verified since the entry no longer suppresses anything.
+func VerifyChanges(ctx context.Context, scanner Scanner, changes []DiffChange) error {
+	type target struct {
+		change *DiffChange
+		entry  *GitleaksEntry
+	}
+
+	var targets []target
+	var commits []string
+	seen := make(map[string]bool)
+	for i := range changes {
+		change := &changes[i]
+		if change.IsDeletion() {
+			continue
+		}
+		entry, err := change.Entry()
+		if err != nil {
+			continue // Reported when the comment is generated
+		}
+		targets = append(targets, target{change: change, entry: entry})
+
+		if entry.Commit != "" && !seen[entry.Commit] {
+			seen[entry.Commit] = true
+			commits = append(commits, entry.Commit)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	findings, err := scanner.Scan(ctx, commits)
+	if err != nil {
+		return fmt.Errorf("failed to scan for findings: %w", err)
+	}
+
+	for _, t := range targets {
+		verification := &EntryVerification{}
+		for _, f := range findings {
+			if t.entry.MatchesFinding(f) {
+				verification.Findings = append(verification.Findings, f)
+			}
+		}
+		t.change.Verification = verification
+	}
+	return nil
+}
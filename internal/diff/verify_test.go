@@ -0,0 +1,253 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testGitleaksReport = `[
+ {
+  "Description": "AWS Access Key",
+  "StartLine": 3,
+  "EndLine": 3,
+  "StartColumn": 10,
+  "EndColumn": 29,
+  "Match": "REDACTED",
+  "Secret": "REDACTED",
+  "File": "config/aws.yml",
+  "Commit": "",
+  "Entropy": 3.6,
+  "RuleID": "aws-access-token",
+  "Fingerprint": "config/aws.yml:aws-access-token:3"
+ },
+ {
+  "Description": "Generic API Key",
+  "StartLine": 7,
+  "File": "config/aws.yml",
+  "RuleID": "generic-api-key",
+  "Fingerprint": "config/aws.yml:generic-api-key:7"
+ },
+ {
+  "Description": "Generic API Key",
+  "StartLine": 1,
+  "File": "services/api/.env",
+  "RuleID": "generic-api-key",
+  "Fingerprint": "services/api/.env:generic-api-key:1"
+ },
+ {
+  "Description": "Slack token",
+  "StartLine": 12,
+  "File": "scripts/notify.sh",
+  "Commit": "cd5226711335c68be1e720b318b7bc3135a30eb2",
+  "RuleID": "slack-bot-token",
+  "Fingerprint": "cd5226711335c68be1e720b318b7bc3135a30eb2:scripts/notify.sh:slack-bot-token:12"
+ }
+]`
+
+// fakeScanner returns findings parsed from a canned gitleaks report
+type fakeScanner struct {
+	report  string
+	err     error
+	commits []string
+	scans   int
+}
+
+func (s *fakeScanner) Scan(ctx context.Context, commits []string) ([]Finding, error) {
+	s.scans++
+	s.commits = commits
+	if s.err != nil {
+		return nil, s.err
+	}
+	return ParseGitleaksReport([]byte(s.report))
+}
+
+func TestParseGitleaksReport(t *testing.T) {
+	findings, err := ParseGitleaksReport([]byte(testGitleaksReport))
+	if err != nil {
+		t.Fatalf("ParseGitleaksReport() unexpected error: %v", err)
+	}
+	if len(findings) != 4 {
+		t.Fatalf("ParseGitleaksReport() returned %d findings, want 4", len(findings))
+	}
+	want := Finding{
+		RuleID:      "aws-access-token",
+		Description: "AWS Access Key",
+		File:        "config/aws.yml",
+		StartLine:   3,
+		EndLine:     3,
+		Fingerprint: "config/aws.yml:aws-access-token:3",
+	}
+	if findings[0] != want {
+		t.Errorf("findings[0] = %+v, want %+v", findings[0], want)
+	}
+
+	if findings, err := ParseGitleaksReport(nil); err != nil || findings != nil {
+		t.Errorf("ParseGitleaksReport(empty) = %v, %v, want no findings", findings, err)
+	}
+	if _, err := ParseGitleaksReport([]byte("{")); err == nil {
+		t.Error("ParseGitleaksReport() expected error for malformed report")
+	}
+}
+
+func TestGitleaksEntry_MatchesFinding(t *testing.T) {
+	finding := Finding{File: "config/aws.yml", RuleID: "aws-access-token", StartLine: 3}
+	commitFinding := Finding{File: "config/aws.yml", RuleID: "aws-access-token", StartLine: 3, Commit: "cd5226711335c68be1e720b318b7bc3135a30eb2"}
+
+	tests := []struct {
+		entry   string
+		finding Finding
+		want    bool
+	}{
+		{"config/aws.yml:aws-access-token:3", finding, true},
+		{"config/aws.yml:generic-api-key:3", finding, false},
+		{"config/aws.yml:aws-access-token:4", finding, false},
+		{"config/aws.yml:3", finding, true},
+		{"config/aws.yml", finding, true},
+		{"config/*.yml", finding, true},
+		{"*.yml", finding, true},
+		{"other.yml", finding, false},
+		{"cd5226711335c68be1e720b318b7bc3135a30eb2:config/aws.yml:aws-access-token:3", commitFinding, true},
+		{"cd5226711335c68be1e720b318b7bc3135a30eb2:config/aws.yml:aws-access-token:3", finding, false},
+		{"config/aws.yml:aws-access-token:3", commitFinding, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			entry, err := ParseGitleaksEntry(tt.entry)
+			if err != nil {
+				t.Fatalf("ParseGitleaksEntry() unexpected error: %v", err)
+			}
+			if got := entry.MatchesFinding(tt.finding); got != tt.want {
+				t.Errorf("MatchesFinding(%+v) = %v, want %v", tt.finding, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyChanges(t *testing.T) {
+	scanner := &fakeScanner{report: testGitleaksReport}
+	changes := []DiffChange{
+		{Operation: OperationAddition, Content: "config/aws.yml:aws-access-token:3"},
+		{Operation: OperationAddition, Content: "config/aws.yml"},
+		{FilePath: "services/api/.gitleaksignore", Operation: OperationAddition, Content: ".env:generic-api-key:1"},
+		{Operation: OperationAddition, Content: "config/gone.yml:generic-api-key:1"},
+		{Operation: OperationModification, Content: "cd5226711335c68be1e720b318b7bc3135a30eb2:scripts/notify.sh:slack-bot-token:12", PreviousContent: "scripts/notify.sh:11"},
+		{Operation: OperationDeletion, Content: "config/aws.yml"},
+	}
+
+	if err := VerifyChanges(context.Background(), scanner, changes); err != nil {
+		t.Fatalf("VerifyChanges() unexpected error: %v", err)
+	}
+
+	if len(scanner.commits) != 1 || scanner.commits[0] != "cd5226711335c68be1e720b318b7bc3135a30eb2" {
+		t.Errorf("Scan() commits = %v, want the git scan fingerprint's commit", scanner.commits)
+	}
+
+	wantCounts := []int{1, 2, 1, 0, 1}
+	for i, want := range wantCounts {
+		v := changes[i].Verification
+		if v == nil {
+			t.Errorf("changes[%d].Verification = nil, want %d findings", i, want)
+			continue
+		}
+		if len(v.Findings) != want {
+			t.Errorf("changes[%d] matched %d findings, want %d", i, len(v.Findings), want)
+		}
+	}
+	if got := changes[1].Verification.RuleIDs(); strings.Join(got, ",") != "aws-access-token,generic-api-key" {
+		t.Errorf("RuleIDs() = %v, want both rules sorted", got)
+	}
+	if changes[3].Verification.Suppresses() {
+		t.Error("an entry matching no finding should not be reported as suppressing one")
+	}
+	if changes[5].Verification != nil {
+		t.Errorf("deletions should not be verified, got %+v", changes[5].Verification)
+	}
+}
+
+func TestVerifyChanges_NoEntries(t *testing.T) {
+	scanner := &fakeScanner{}
+	changes := []DiffChange{{Operation: OperationDeletion, Content: "a.yml"}}
+
+	if err := VerifyChanges(context.Background(), scanner, changes); err != nil {
+		t.Fatalf("VerifyChanges() unexpected error: %v", err)
+	}
+	if scanner.scans != 0 {
+		t.Errorf("expected no scan without additions, got %d", scanner.scans)
+	}
+}
+
+func TestVerifyChanges_ScanError(t *testing.T) {
+	scanner := &fakeScanner{err: errors.New("gitleaks: not found")}
+	changes := []DiffChange{{Operation: OperationAddition, Content: "a.yml"}}
+
+	err := VerifyChanges(context.Background(), scanner, changes)
+	if err == nil || !strings.Contains(err.Error(), "gitleaks: not found") {
+		t.Errorf("VerifyChanges() error = %v, want scan failure", err)
+	}
+	if changes[0].Verification != nil {
+		t.Error("a failed scan should leave changes unverified")
+	}
+}
+
+func TestGitleaksScanner_Scan(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake gitleaks binary is a shell script")
+	}
+
+	// The fake binary records its arguments and writes the canned report
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	reportFile := filepath.Join(dir, "canned.json")
+	if err := os.WriteFile(reportFile, []byte(testGitleaksReport), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	script := `#!/bin/sh
+echo "$@" >> "` + argsFile + `"
+while [ $# -gt 0 ]; do
+  if [ "$1" = "--report-path" ]; then cp "` + reportFile + `" "$2"; fi
+  shift
+done
+`
+	binary := filepath.Join(dir, "gitleaks")
+	if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := NewGitleaksScanner(binary, "").Scan(context.Background(), []string{"cd5226711335c68be1e720b318b7bc3135a30eb2"})
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	if len(findings) != 8 {
+		t.Errorf("Scan() returned %d findings, want 8 (working tree and commit scans)", len(findings))
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs := strings.Split(strings.TrimSpace(string(args)), "\n")
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 gitleaks runs, got %d: %q", len(runs), runs)
+	}
+	for _, want := range []string{"detect", "--gitleaks-ignore-path", "--redact", "--exit-code 0", "--no-git"} {
+		if !strings.Contains(runs[0], want) {
+			t.Errorf("working tree scan args %q missing %q", runs[0], want)
+		}
+	}
+	if !strings.Contains(runs[1], "--log-opts=--no-walk cd5226711335c68be1e720b318b7bc3135a30eb2") {
+		t.Errorf("commit scan args %q should limit the log to the fingerprint commits", runs[1])
+	}
+}
+
+func TestGitleaksScanner_ScanFailure(t *testing.T) {
+	_, err := NewGitleaksScanner(filepath.Join(t.TempDir(), "missing"), "").Scan(context.Background(), nil)
+	if err == nil {
+		t.Error("Scan() expected error for a missing binary")
+	}
+}
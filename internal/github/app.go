@@ -0,0 +1,179 @@
+package github
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/go-github/v57/github"
+	"golang.org/x/oauth2"
+)
+
+// DefaultBotLogin is the login comments are posted as with the workflow's GITHUB_TOKEN
+const DefaultBotLogin = "github-actions[bot]"
+
+const (
+	// appJWTLifetime is how long an app JWT is valid; GitHub allows at most 10 minutes
+	appJWTLifetime = 9 * time.Minute
+
+	// appJWTClockSkew backdates the JWT's issue time, for clocks running ahead of GitHub's
+	appJWTClockSkew = time.Minute
+
+	// installationTokenRefresh is how long before expiry an installation token
+	// is replaced, so a request never starts with a token about to expire
+	installationTokenRefresh = 5 * time.Minute
+
+	// installationTokenTimeout bounds a token refresh, which has no caller context
+	installationTokenTimeout = 30 * time.Second
+)
+
+// App is a GitHub App, authenticated with JWTs signed by its private key
+// It mints installation tokens, which clients use to act as the app's bot.
+type App struct {
+	// ID is the app ID shown in the app's settings
+	ID int64
+
+	// Slug is the app's URL name; its bot posts as "<slug>[bot]"
+	Slug string
+
+	// client calls the API as the app itself (JWT authentication)
+	client *github.Client
+}
+
+// NewApp authenticates as the GitHub App with the given ID and PEM-encoded
+// private key, on GitHub.com or the Enterprise Server at ghHost, and looks up
+// the app's slug
+func NewApp(ctx context.Context, appID int64, privateKeyPEM []byte, ghHost string) (*App, error) {
+	app, err := newApp(appID, privateKeyPEM, ghHost)
+	if err != nil {
+		return nil, err
+	}
+	if err := app.lookupSlug(ctx); err != nil {
+		return nil, enhanceAppError(err, appID, ghHost)
+	}
+	return app, nil
+}
+
+// newApp creates the app's JWT-authenticated client without calling the API
+func newApp(appID int64, privateKeyPEM []byte, ghHost string) (*App, error) {
+	if appID <= 0 {
+		return nil, errors.New("GitHub App ID must be positive")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w\n"+
+			"  → Action: Pass the PEM private key generated in the app's settings, including the BEGIN and END lines\n"+
+			"  → Example: app-private-key: ${{ secrets.APP_PRIVATE_KEY }}", err)
+	}
+
+	jwtSource := oauth2.ReuseTokenSourceWithExpiry(nil, &appJWTSource{appID: appID, key: key}, appJWTClockSkew)
+	client, err := newGitHubClient(oauth2.NewClient(context.Background(), jwtSource), ghHost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &App{ID: appID, client: client}, nil
+}
+
+// lookupSlug fetches the authenticated app, which also checks the credentials
+func (a *App) lookupSlug(ctx context.Context) error {
+	app, _, err := a.client.Apps.Get(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to authenticate as GitHub App %d: %w", a.ID, err)
+	}
+	a.Slug = app.GetSlug()
+	return nil
+}
+
+// BotLogin returns the login the app's installation posts comments as
+func (a *App) BotLogin() string {
+	return a.Slug + "[bot]"
+}
+
+// InstallationID returns the ID of the app's installation on owner/repo
+func (a *App) InstallationID(ctx context.Context, owner, repo string) (int64, error) {
+	installation, resp, err := a.client.Apps.FindRepositoryInstallation(ctx, owner, repo)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return 0, fmt.Errorf("GitHub App %q is not installed on %s/%s\n"+
+				"  → Action: Install the app on the repository from the app's settings page\n"+
+				"  → Check: The installation has access to %s/%s if it is limited to selected repositories",
+				a.Slug, owner, repo, owner, repo)
+		}
+		return 0, fmt.Errorf("failed to find the installation of GitHub App %q on %s/%s: %w", a.Slug, owner, repo, err)
+	}
+	return installation.GetID(), nil
+}
+
+// InstallationTokenSource returns installation tokens for installationID,
+// minted on first use and replaced shortly before they expire (after an hour)
+func (a *App) InstallationTokenSource(installationID int64) oauth2.TokenSource {
+	return oauth2.ReuseTokenSourceWithExpiry(nil, &installationTokenSource{app: a, installationID: installationID},
+		installationTokenRefresh)
+}
+
+// appJWTSource signs the JWTs that authenticate as the app itself
+type appJWTSource struct {
+	appID int64
+	key   *rsa.PrivateKey
+}
+
+func (s *appJWTSource) Token() (*oauth2.Token, error) {
+	now := time.Now()
+	expiry := now.Add(appJWTLifetime)
+	claims := jwt.RegisteredClaims{
+		Issuer:    strconv.FormatInt(s.appID, 10),
+		IssuedAt:  jwt.NewNumericDate(now.Add(-appJWTClockSkew)),
+		ExpiresAt: jwt.NewNumericDate(expiry),
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(s.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return &oauth2.Token{AccessToken: signed, TokenType: "Bearer", Expiry: expiry}, nil
+}
+
+// installationTokenSource mints installation access tokens
+type installationTokenSource struct {
+	app            *App
+	installationID int64
+}
+
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	// Tokens are refreshed in the middle of other requests, whose context
+	// oauth2 does not pass on
+	ctx, cancel := context.WithTimeout(context.Background(), installationTokenTimeout)
+	defer cancel()
+
+	token, _, err := s.app.client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create an installation token for GitHub App %q (installation %d): %w",
+			s.app.Slug, s.installationID, err)
+	}
+	return &oauth2.Token{
+		AccessToken: token.GetToken(),
+		TokenType:   "Bearer",
+		Expiry:      token.GetExpiresAt().Time,
+	}, nil
+}
+
+// enhanceAppError adds guidance to errors authenticating as the app
+func enhanceAppError(err error, appID int64, ghHost string) error {
+	if !isAuthError(err) {
+		return enhanceError(err, ghHost)
+	}
+	host := "GitHub.com"
+	if ghHost != "" {
+		host = ghHost
+	}
+	return fmt.Errorf("authentication as GitHub App %d failed on %s\n"+
+		"  → Action: Check that app-id matches the app the private key was generated for\n"+
+		"  → Check: The key has not been revoked, and the runner's clock is correct\n"+
+		"  → Original error: %w", appID, host, err)
+}
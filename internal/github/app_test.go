@@ -0,0 +1,175 @@
+package github
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/go-github/v57/github"
+)
+
+// newTestAppKey returns an RSA private key and its PEM encoding
+func newTestAppKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// newTestApp returns an App whose API calls go to server
+func newTestApp(t *testing.T, server *httptest.Server, keyPEM []byte) *App {
+	t.Helper()
+	app, err := newApp(12345, keyPEM, "")
+	if err != nil {
+		t.Fatalf("newApp() unexpected error: %v", err)
+	}
+	baseURL, _ := url.Parse(server.URL + "/")
+	app.client.BaseURL = baseURL
+	return app
+}
+
+// TestApp tests authenticating as the app and minting installation tokens
+func TestApp(t *testing.T) {
+	key, keyPEM := newTestAppKey(t)
+	var minted atomic.Int32
+	expiresIn := time.Hour
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every app endpoint is called with a JWT signed by the app's key
+		signed := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		token, err := jwt.ParseWithClaims(signed, &jwt.RegisteredClaims{}, func(*jwt.Token) (any, error) {
+			return &key.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"RS256"}))
+		if err != nil {
+			t.Errorf("%s %s: invalid JWT: %v", r.Method, r.URL.Path, err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if issuer, _ := token.Claims.GetIssuer(); issuer != "12345" {
+			t.Errorf("JWT issuer = %q, want the app ID 12345", issuer)
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/app":
+			fmt.Fprint(w, `{"id": 12345, "slug": "exclusion-reviewer"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/installation":
+			fmt.Fprint(w, `{"id": 678}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/other/installation":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/app/installations/678/access_tokens":
+			n := minted.Add(1)
+			fmt.Fprintf(w, `{"token": "ghs_token%d", "expires_at": %q}`, n,
+				time.Now().Add(expiresIn).UTC().Format(time.RFC3339))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	app := newTestApp(t, server, keyPEM)
+	if err := app.lookupSlug(ctx); err != nil {
+		t.Fatalf("lookupSlug() unexpected error: %v", err)
+	}
+	if app.BotLogin() != "exclusion-reviewer[bot]" {
+		t.Errorf("BotLogin() = %q, want exclusion-reviewer[bot]", app.BotLogin())
+	}
+
+	installationID, err := app.InstallationID(ctx, "owner", "repo")
+	if err != nil || installationID != 678 {
+		t.Fatalf("InstallationID() = %d, %v, want 678", installationID, err)
+	}
+	if _, err := app.InstallationID(ctx, "owner", "other"); err == nil ||
+		!strings.Contains(err.Error(), `GitHub App "exclusion-reviewer" is not installed on owner/other`) {
+		t.Errorf("InstallationID() for a repository without the app error = %v", err)
+	}
+
+	// Tokens are reused until shortly before they expire
+	source := app.InstallationTokenSource(installationID)
+	for i := 0; i < 3; i++ {
+		token, err := source.Token()
+		if err != nil {
+			t.Fatalf("Token() unexpected error: %v", err)
+		}
+		if token.AccessToken != "ghs_token1" {
+			t.Errorf("Token() = %q, want the first token reused", token.AccessToken)
+		}
+	}
+
+	// A token close to expiry is replaced
+	expiresIn = 2 * time.Minute
+	source = app.InstallationTokenSource(installationID)
+	first, _ := source.Token()
+	second, err := source.Token()
+	if err != nil {
+		t.Fatalf("Token() unexpected error: %v", err)
+	}
+	if first.AccessToken == second.AccessToken {
+		t.Errorf("Token() reused %q, which expires within the refresh window", first.AccessToken)
+	}
+}
+
+// TestNewApp_Errors tests rejecting invalid credentials before calling the API
+func TestNewApp_Errors(t *testing.T) {
+	_, keyPEM := newTestAppKey(t)
+
+	if _, err := newApp(0, keyPEM, ""); err == nil {
+		t.Error("newApp() with app ID 0 expected error, got nil")
+	}
+	if _, err := newApp(1, []byte("not a key"), ""); err == nil ||
+		!strings.Contains(err.Error(), "failed to parse GitHub App private key") {
+		t.Errorf("newApp() with invalid key error = %v", err)
+	}
+}
+
+// TestNewApp_Enterprise tests that the app calls the Enterprise Server API
+func TestNewApp_Enterprise(t *testing.T) {
+	_, keyPEM := newTestAppKey(t)
+	app, err := newApp(1, keyPEM, "github.company.com")
+	if err != nil {
+		t.Fatalf("newApp() unexpected error: %v", err)
+	}
+	if got := app.client.BaseURL.String(); got != "https://github.company.com/api/v3/" {
+		t.Errorf("BaseURL = %q, want https://github.company.com/api/v3/", got)
+	}
+}
+
+// TestIsBotReviewComment_AppLogin tests recognizing the app's bot by its login
+func TestIsBotReviewComment_AppLogin(t *testing.T) {
+	appComment := &github.PullRequestComment{
+		Body: github.String("Posted before markers were added"),
+		User: &github.User{Login: github.String("exclusion-reviewer[bot]")},
+	}
+	actionsComment := &github.PullRequestComment{
+		Body: github.String("Posted with GITHUB_TOKEN"),
+		User: &github.User{Login: github.String(DefaultBotLogin)},
+	}
+
+	if IsBotReviewComment(appComment) {
+		t.Error("IsBotReviewComment() recognized an unknown app's comment")
+	}
+	if !IsBotReviewComment(appComment, "exclusion-reviewer[bot]") {
+		t.Error("IsBotReviewComment() did not recognize the app's comment")
+	}
+	if !IsBotReviewComment(actionsComment, "exclusion-reviewer[bot]") {
+		t.Error("IsBotReviewComment() should still recognize github-actions[bot]")
+	}
+	if IsBotReviewComment(&github.PullRequestComment{Body: github.String("hi")}, "") {
+		t.Error("IsBotReviewComment() recognized a comment without an author")
+	}
+}
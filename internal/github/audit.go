@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
+	"github.com/google/go-github/v57/github"
+)
+
+// PublishAuditIssue keeps the audit tracking issue in step with the latest report
+// The issue is found among the bot's open issues by comment.AuditMarker, so
+// there is at most one and issues others copied the marker into are left
+// alone. Only bot accounts are matched: with a personal access token a new
+// issue is opened on every run with problems. While the report has problems
+// (open is true) the issue is updated, or opened if there is none. Once the
+// report is clean the issue is updated a last time and closed. Returns nil if
+// nothing was done.
+func PublishAuditIssue(ctx context.Context, client Client, title, body string, open, debug bool) (*IssueResult, error) {
+	var issues []*github.Issue
+	var err error
+	botLogin := client.BotLogin()
+	if IsBotAccount(botLogin) {
+		issues, err = client.ListOpenIssues(ctx, botLogin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list open issues: %w", err)
+		}
+	} else {
+		log.Printf("Warning: %s is not a bot account, so its earlier audit issue cannot be told apart from its own issues; "+
+			"use the workflow token or a GitHub App to keep a single tracking issue", botLogin)
+	}
+
+	for _, existing := range issues {
+		if !isOwnMarked(existing.GetBody(), existing.GetUser().GetLogin(), comment.AuditMarker, botLogin) {
+			continue
+		}
+
+		if open && normalizeWhitespace(existing.GetBody()) == normalizeWhitespace(body) {
+			if debug {
+				log.Printf("Audit issue is up to date: %s", existing.GetHTMLURL())
+			}
+			return &IssueResult{Status: "unchanged", Number: existing.GetNumber(), URL: existing.GetHTMLURL()}, nil
+		}
+
+		req := &IssueRequest{Body: body}
+		status := "updated"
+		if !open {
+			req.State = "closed"
+			status = "closed"
+		}
+
+		var resp *IssueResponse
+		_, err := RetryWithBackoff(func() error {
+			resp, err = client.UpdateIssue(ctx, existing.GetNumber(), req)
+			return err
+		}, 3)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update audit issue #%d: %w", existing.GetNumber(), err)
+		}
+		return &IssueResult{Status: status, Number: resp.Number, URL: resp.HTMLURL}, nil
+	}
+
+	if !open {
+		return nil, nil
+	}
+
+	var resp *IssueResponse
+	_, err = RetryWithBackoff(func() error {
+		resp, err = client.CreateIssue(ctx, &IssueRequest{Title: title, Body: body})
+		return err
+	}, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit issue: %w", err)
+	}
+	return &IssueResult{Status: "created", Number: resp.Number, URL: resp.HTMLURL}, nil
+}
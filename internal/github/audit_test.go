@@ -0,0 +1,134 @@
+package github
+
+import (
+	"context"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
+	"github.com/google/go-github/v57/github"
+)
+
+func TestPublishAuditIssue(t *testing.T) {
+	body := comment.AuditMarker + "\n## Audit\n\n| a | b |"
+	bot := &github.User{Login: github.String(DefaultBotLogin)}
+	tracking := &github.Issue{Number: github.Int(7), Body: github.String(comment.AuditMarker + "\nold report"), User: bot}
+	impostor := &github.Issue{
+		Number: github.Int(9),
+		Body:   github.String(comment.AuditMarker + "\nfake report"),
+		User:   &github.User{Login: github.String("mallory")},
+	}
+
+	tests := []struct {
+		name       string
+		login      string
+		existing   []*github.Issue
+		open       bool
+		wantStatus string
+		wantState  string
+		wantCreate bool
+	}{
+		{
+			name:       "opens the first issue",
+			existing:   []*github.Issue{{Number: github.Int(1), Body: github.String("Bug report")}},
+			open:       true,
+			wantStatus: "created",
+			wantCreate: true,
+		},
+		{
+			name:       "updates the tracking issue",
+			existing:   []*github.Issue{tracking},
+			open:       true,
+			wantStatus: "updated",
+		},
+		{
+			name:       "leaves an identical report alone",
+			existing:   []*github.Issue{{Number: github.Int(7), Body: github.String(body + "\n"), User: bot}},
+			open:       true,
+			wantStatus: "unchanged",
+		},
+		{
+			name:       "closes the issue once the report is clean",
+			existing:   []*github.Issue{tracking},
+			open:       false,
+			wantStatus: "closed",
+			wantState:  "closed",
+		},
+		{
+			name: "opens nothing for a clean report",
+			open: false,
+		},
+		{
+			name:       "opens its own issue beside one with a copied marker",
+			existing:   []*github.Issue{impostor},
+			open:       true,
+			wantStatus: "created",
+			wantCreate: true,
+		},
+		{
+			name:     "does not close an issue with a copied marker",
+			existing: []*github.Issue{impostor},
+			open:     false,
+		},
+		{
+			name:  "does not edit a marked issue by a personal access token's user",
+			login: "octocat",
+			existing: []*github.Issue{{
+				Number: github.Int(9),
+				Body:   github.String(comment.AuditMarker + "\nnotes"),
+				User:   &github.User{Login: github.String("octocat")},
+			}},
+			open:       true,
+			wantStatus: "created",
+			wantCreate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var update *IssueRequest
+			created := false
+			mockClient := &MockClient{
+				Login: tt.login,
+				ListOpenIssuesFunc: func(ctx context.Context, creator string) ([]*github.Issue, error) {
+					if creator != DefaultBotLogin {
+						t.Errorf("ListOpenIssues(%q), want only the bot's issues", creator)
+					}
+					return tt.existing, nil
+				},
+				UpdateIssueFunc: func(ctx context.Context, number int, req *IssueRequest) (*IssueResponse, error) {
+					if number != 7 || req.Body != body {
+						t.Errorf("UpdateIssue(%d, %+v), want issue 7 with the new report", number, req)
+					}
+					update = req
+					return &IssueResponse{Number: number}, nil
+				},
+				CreateIssueFunc: func(ctx context.Context, req *IssueRequest) (*IssueResponse, error) {
+					if req.Title != "Gitleaks exclusion audit" || req.Body != body {
+						t.Errorf("CreateIssue(%+v), want the title and report", req)
+					}
+					created = true
+					return &IssueResponse{Number: 8}, nil
+				},
+			}
+
+			result, err := PublishAuditIssue(context.Background(), mockClient, "Gitleaks exclusion audit", body, tt.open, false)
+			if err != nil {
+				t.Fatalf("PublishAuditIssue() unexpected error: %v", err)
+			}
+
+			status := ""
+			if result != nil {
+				status = result.Status
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+			if update != nil && update.State != tt.wantState {
+				t.Errorf("updated state = %q, want %q", update.State, tt.wantState)
+			}
+			if created != tt.wantCreate {
+				t.Errorf("created = %v, want %v", created, tt.wantCreate)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+// Supported output modes
+const (
+	// OutputComments posts review comments (see DeliveryComments and DeliveryReview)
+	OutputComments = "comments"
+
+	// OutputCheck publishes a check run with one annotation per comment
+	OutputCheck = "check"
+
+	// OutputBoth does both
+	OutputBoth = "both"
+)
+
+// CheckRunName is the name the check run is published under
+const CheckRunName = "gitleaks-diff-comment"
+
+// API limits for check runs
+const (
+	// maxCheckAnnotations is the number of annotations accepted per request
+	maxCheckAnnotations = 50
+
+	// maxCheckText is the number of characters accepted in a summary or annotation message
+	maxCheckText = 65535
+)
+
+// NewCheckRun describes a check run on headSHA with an annotation for each comment
+// The conclusion is "failure" if a wildcard entry is added or a blocking
+// policy rule is broken, otherwise "neutral": exclusions need a human
+// decision, so the check never passes on its own. policy may be nil.
+func NewCheckRun(headSHA, summary string, comments []*comment.GeneratedComment, policy *diff.PolicyResult) *CheckRunRequest {
+	req := &CheckRunRequest{
+		Name:       CheckRunName,
+		HeadSHA:    headSHA,
+		Conclusion: "neutral",
+		Summary:    truncateCheckText(summary),
+	}
+
+	wildcards := 0
+	for _, comm := range comments {
+		if addsWildcard(comm.SourceChange) {
+			wildcards++
+		}
+		req.Annotations = append(req.Annotations, NewCheckAnnotation(comm))
+	}
+
+	switch {
+	case policy != nil && policy.Failed():
+		req.Conclusion = "failure"
+		req.Title = fmt.Sprintf("%d policy %s", policy.Blocking, pluralize(policy.Blocking, "violation"))
+	case wildcards > 0:
+		req.Conclusion = "failure"
+		req.Title = fmt.Sprintf("%d wildcard %s added", wildcards, pluralize(wildcards, "exclusion"))
+	case len(comments) > 0:
+		req.Title = fmt.Sprintf("%d %s to review", len(comments), pluralize(len(comments), "change"))
+	default:
+		req.Title = "No exclusion changes"
+	}
+	return req
+}
+
+// NewCheckAnnotation converts a generated comment into a check run annotation
+// Annotations can only point at the head commit, so comments on removed lines
+// are placed on the first line of the file.
+func NewCheckAnnotation(comm *comment.GeneratedComment) CheckAnnotation {
+	title, message := splitCommentBody(comm.Body)
+
+	line := comm.Line
+	level := "warning"
+	if comm.Side == "LEFT" {
+		line = 1
+		level = "notice"
+		title = fmt.Sprintf("%s (removed line %d)", title, comm.Line)
+	}
+	if addsWildcard(comm.SourceChange) || isBlocked(comm.SourceChange) {
+		level = "failure"
+	}
+
+	return CheckAnnotation{
+		Path:      comm.Path,
+		StartLine: line,
+		EndLine:   line,
+		Level:     level,
+		Title:     title,
+		Message:   truncateCheckText(message),
+	}
+}
+
+// PublishCheckRun creates the check run, adding annotations beyond the
+// per-request limit in follow-up updates
+func PublishCheckRun(ctx context.Context, client Client, req *CheckRunRequest, debug bool) (*CheckRunResponse, error) {
+	annotations := req.Annotations
+	batch := *req
+	batch.Annotations = annotations[:min(len(annotations), maxCheckAnnotations)]
+
+	var run *CheckRunResponse
+	_, err := RetryWithBackoff(func() error {
+		var err error
+		run, err = client.CreateCheckRun(ctx, &batch)
+		return err
+	}, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create check run: %w", err)
+	}
+	if debug {
+		log.Printf("Created check run %d with %d annotations: %s", run.ID, len(batch.Annotations), run.HTMLURL)
+	}
+
+	for start := maxCheckAnnotations; start < len(annotations); start += maxCheckAnnotations {
+		batch.Annotations = annotations[start:min(len(annotations), start+maxCheckAnnotations)]
+		_, err := RetryWithBackoff(func() error {
+			_, err := client.UpdateCheckRun(ctx, run.ID, &batch)
+			return err
+		}, 3)
+		if err != nil {
+			return run, fmt.Errorf("failed to add annotations %d-%d to check run: %w", start+1, start+len(batch.Annotations), err)
+		}
+		if debug {
+			log.Printf("Added %d annotations to check run %d", len(batch.Annotations), run.ID)
+		}
+	}
+	return run, nil
+}
+
+// addsWildcard returns true if change adds a wildcard entry, or turns an
+// existing entry into one
+func addsWildcard(change *diff.DiffChange) bool {
+	if change == nil || change.IsDeletion() {
+		return false
+	}
+	entry, err := change.Entry()
+	if err != nil || !entry.IsPattern {
+		return false
+	}
+	if change.IsModification() {
+		previous, err := change.PreviousEntry()
+		return err != nil || !previous.IsPattern
+	}
+	return true
+}
+
+// isBlocked returns true if change breaks a blocking policy rule
+func isBlocked(change *diff.DiffChange) bool {
+	if change == nil {
+		return false
+	}
+	for _, v := range change.Violations {
+		if v.Blocking {
+			return true
+		}
+	}
+	return false
+}
+
+// splitCommentBody turns a markdown comment into an annotation title (its
+// first line) and a plain text message, dropping the marker and bold markup
+func splitCommentBody(body string) (string, string) {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "<!-- gitleaks-diff-comment:") {
+			continue
+		}
+		lines = append(lines, strings.ReplaceAll(line, "**", ""))
+	}
+
+	text := strings.TrimSpace(strings.Join(lines, "\n"))
+	title, message, _ := strings.Cut(text, "\n")
+	return strings.TrimSpace(title), strings.TrimSpace(message)
+}
+
+// truncateCheckText shortens text to the API limit for check run text fields
+func truncateCheckText(text string) string {
+	if utf8.RuneCountInString(text) <= maxCheckText {
+		return text
+	}
+	const suffix = "\n\n…truncated"
+	runes := []rune(text)
+	return string(runes[:maxCheckText-utf8.RuneCountInString(suffix)]) + suffix
+}
+
+// pluralize returns word, with an "s" unless count is 1
+func pluralize(count int, word string) string {
+	if count == 1 {
+		return word
+	}
+	return word + "s"
+}
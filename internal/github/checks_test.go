@@ -0,0 +1,169 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+func TestNewCheckRun(t *testing.T) {
+	exact := &comment.GeneratedComment{
+		Body: "<!-- gitleaks-diff-comment: .gitleaksignore:3:RIGHT -->\n🔒 **Gitleaks Exclusion Added**\n\n`a.env` will be excluded.",
+		Path: ".gitleaksignore", Line: 3, Side: "RIGHT",
+		SourceChange: &diff.DiffChange{Operation: diff.OperationAddition, LineNumber: 3, Content: "a.env"},
+	}
+	removed := &comment.GeneratedComment{
+		Body: "<!-- gitleaks-diff-comment: .gitleaksignore:7:LEFT -->\n✅ **Gitleaks Exclusion Removed**\n\n`b.env` will now be scanned.",
+		Path: ".gitleaksignore", Line: 7, Side: "LEFT",
+		SourceChange: &diff.DiffChange{Operation: diff.OperationDeletion, OldLineNumber: 7, Content: "b.env"},
+	}
+	wildcard := &comment.GeneratedComment{
+		Body: "<!-- gitleaks-diff-comment: .gitleaksignore:4:RIGHT -->\n🔒 **Gitleaks Exclusion Added**\n\n`*.env` will be excluded.",
+		Path: ".gitleaksignore", Line: 4, Side: "RIGHT",
+		SourceChange: &diff.DiffChange{Operation: diff.OperationAddition, LineNumber: 4, Content: "*.env"},
+	}
+
+	t.Run("neutral without wildcards", func(t *testing.T) {
+		req := NewCheckRun("abc123", "table", []*comment.GeneratedComment{exact, removed}, nil)
+		if req.Conclusion != "neutral" || req.Title != "2 changes to review" {
+			t.Errorf("Conclusion = %q, Title = %q, want neutral and 2 changes", req.Conclusion, req.Title)
+		}
+		if req.Name != CheckRunName || req.HeadSHA != "abc123" || req.Summary != "table" {
+			t.Errorf("request = %+v, want name, head and summary set", req)
+		}
+
+		want := []CheckAnnotation{
+			{Path: ".gitleaksignore", StartLine: 3, EndLine: 3, Level: "warning", Title: "🔒 Gitleaks Exclusion Added", Message: "`a.env` will be excluded."},
+			{Path: ".gitleaksignore", StartLine: 1, EndLine: 1, Level: "notice", Title: "✅ Gitleaks Exclusion Removed (removed line 7)", Message: "`b.env` will now be scanned."},
+		}
+		for i := range want {
+			if req.Annotations[i] != want[i] {
+				t.Errorf("annotation %d = %+v, want %+v", i, req.Annotations[i], want[i])
+			}
+		}
+	})
+
+	t.Run("failure when a wildcard is added", func(t *testing.T) {
+		req := NewCheckRun("abc123", "", []*comment.GeneratedComment{exact, wildcard}, nil)
+		if req.Conclusion != "failure" || req.Title != "1 wildcard exclusion added" {
+			t.Errorf("Conclusion = %q, Title = %q, want failure", req.Conclusion, req.Title)
+		}
+		if req.Annotations[1].Level != "failure" {
+			t.Errorf("wildcard annotation level = %q, want failure", req.Annotations[1].Level)
+		}
+	})
+
+	t.Run("failure on a blocking policy violation", func(t *testing.T) {
+		blocked := *exact
+		change := *exact.SourceChange
+		change.Violations = []diff.PolicyViolation{{Rule: diff.PolicyRequireLineNumber, Blocking: true}}
+		blocked.SourceChange = &change
+
+		req := NewCheckRun("abc123", "", []*comment.GeneratedComment{&blocked, removed}, &diff.PolicyResult{Blocking: 1})
+		if req.Conclusion != "failure" || req.Title != "1 policy violation" {
+			t.Errorf("Conclusion = %q, Title = %q, want a policy failure", req.Conclusion, req.Title)
+		}
+		if req.Annotations[0].Level != "failure" || req.Annotations[1].Level != "notice" {
+			t.Errorf("levels = %q, %q, want failure and notice", req.Annotations[0].Level, req.Annotations[1].Level)
+		}
+	})
+
+	t.Run("warnings alone stay neutral", func(t *testing.T) {
+		req := NewCheckRun("abc123", "", []*comment.GeneratedComment{exact}, &diff.PolicyResult{Warnings: 2})
+		if req.Conclusion != "neutral" {
+			t.Errorf("Conclusion = %q, want neutral", req.Conclusion)
+		}
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		req := NewCheckRun("abc123", "", nil, nil)
+		if req.Conclusion != "neutral" || req.Title != "No exclusion changes" {
+			t.Errorf("Conclusion = %q, Title = %q", req.Conclusion, req.Title)
+		}
+	})
+}
+
+func TestAddsWildcard(t *testing.T) {
+	tests := []struct {
+		name   string
+		change *diff.DiffChange
+		want   bool
+	}{
+		{"no source change", nil, false},
+		{"exact file", &diff.DiffChange{Operation: diff.OperationAddition, Content: "a.env"}, false},
+		{"wildcard added", &diff.DiffChange{Operation: diff.OperationAddition, Content: "config/*.env"}, true},
+		{"wildcard removed", &diff.DiffChange{Operation: diff.OperationDeletion, Content: "config/*.env"}, false},
+		{"entry widened to a wildcard", &diff.DiffChange{Operation: diff.OperationModification, Content: "*.env", PreviousContent: "a.env"}, true},
+		{"wildcard edited", &diff.DiffChange{Operation: diff.OperationModification, Content: "*.yml", PreviousContent: "*.env"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addsWildcard(tt.change); got != tt.want {
+				t.Errorf("addsWildcard() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishCheckRun_Batches(t *testing.T) {
+	req := &CheckRunRequest{Name: CheckRunName, HeadSHA: "abc123", Conclusion: "neutral"}
+	for i := 0; i < 120; i++ {
+		req.Annotations = append(req.Annotations, CheckAnnotation{Path: "a", StartLine: i + 1, EndLine: i + 1})
+	}
+
+	var created int
+	var updates []int
+	mockClient := &MockClient{
+		CreateCheckRunFunc: func(ctx context.Context, r *CheckRunRequest) (*CheckRunResponse, error) {
+			created = len(r.Annotations)
+			return &CheckRunResponse{ID: 5, HTMLURL: "https://github.com/test/runs/5"}, nil
+		},
+		UpdateCheckRunFunc: func(ctx context.Context, id int64, r *CheckRunRequest) (*CheckRunResponse, error) {
+			if id != 5 || r.Conclusion != "neutral" {
+				t.Errorf("UpdateCheckRun(%d, %+v), want run 5 with the same conclusion", id, r)
+			}
+			updates = append(updates, len(r.Annotations))
+			return &CheckRunResponse{ID: id}, nil
+		},
+	}
+
+	run, err := PublishCheckRun(context.Background(), mockClient, req, false)
+	if err != nil {
+		t.Fatalf("PublishCheckRun() unexpected error: %v", err)
+	}
+	if run.HTMLURL != "https://github.com/test/runs/5" {
+		t.Errorf("HTMLURL = %q", run.HTMLURL)
+	}
+	if created != 50 || len(updates) != 2 || updates[0] != 50 || updates[1] != 20 {
+		t.Errorf("batches = %d then %v, want 50 then [50 20]", created, updates)
+	}
+}
+
+func TestPublishCheckRun_Error(t *testing.T) {
+	mockClient := &MockClient{
+		CreateCheckRunFunc: func(ctx context.Context, r *CheckRunRequest) (*CheckRunResponse, error) {
+			return nil, errors.New("403 Resource not accessible by integration")
+		},
+	}
+
+	_, err := PublishCheckRun(context.Background(), mockClient, &CheckRunRequest{}, false)
+	if err == nil || !strings.Contains(err.Error(), "failed to create check run") {
+		t.Errorf("PublishCheckRun() error = %v, want create failure", err)
+	}
+}
+
+func TestTruncateCheckText(t *testing.T) {
+	long := strings.Repeat("é", maxCheckText+10)
+	got := truncateCheckText(long)
+	if n := len([]rune(got)); n != maxCheckText || !strings.HasSuffix(got, "…truncated") {
+		t.Errorf("truncateCheckText() has %d characters, want %d ending with the marker", n, maxCheckText)
+	}
+	if truncateCheckText("short") != "short" {
+		t.Error("short text should be unchanged")
+	}
+}
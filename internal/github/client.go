@@ -4,8 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v57/github"
 	"golang.org/x/oauth2"
@@ -25,6 +30,9 @@ type Client interface {
 	// CreateIssueComment posts a PR-level comment (fallback)
 	CreateIssueComment(ctx context.Context, body string) (*PostCommentResponse, error)
 
+	// UpdateIssueComment replaces the body of an existing PR-level comment
+	UpdateIssueComment(ctx context.Context, commentID int64, body string) (*PostCommentResponse, error)
+
 	// CheckRateLimit returns remaining API calls
 	CheckRateLimit(ctx context.Context) (int, error)
 
@@ -42,14 +50,56 @@ type Client interface {
 
 	// CheckUserPermission checks if a user has required permissions (write/admin/maintain)
 	CheckUserPermission(ctx context.Context, username string) (bool, string, error)
+
+	// ListPullRequestFiles fetches the files changed in the PR, including their patches
+	ListPullRequestFiles(ctx context.Context) ([]*PullRequestFile, error)
+
+	// GetFileContents fetches a file's contents at ref via the Contents API
+	// Returns ErrFileNotFound if the path does not exist or is not a file
+	GetFileContents(ctx context.Context, path, ref string) ([]byte, error)
+
+	// ListTreeFiles lists the paths of all files in the tree at ref via the Git Trees API
+	ListTreeFiles(ctx context.Context, ref string) ([]string, error)
+
+	// GetPullRequest fetches the pull request's metadata
+	GetPullRequest(ctx context.Context) (*PullRequestInfo, error)
+
+	// CreateReview submits a pull request review with line comments in a single request
+	CreateReview(ctx context.Context, req *CreateReviewRequest) (*PostCommentResponse, error)
+
+	// CreateCheckRun creates a completed check run on req.HeadSHA
+	CreateCheckRun(ctx context.Context, req *CheckRunRequest) (*CheckRunResponse, error)
+
+	// UpdateCheckRun replaces a check run's output; annotations are appended
+	// to those already on the run
+	UpdateCheckRun(ctx context.Context, checkRunID int64, req *CheckRunRequest) (*CheckRunResponse, error)
+
+	// ListOpenIssues fetches the repository's open issues opened by creator, excluding pull requests
+	ListOpenIssues(ctx context.Context, creator string) ([]*github.Issue, error)
+
+	// CreateIssue opens an issue in the repository
+	CreateIssue(ctx context.Context, req *IssueRequest) (*IssueResponse, error)
+
+	// UpdateIssue edits an issue; empty fields of req are left unchanged
+	UpdateIssue(ctx context.Context, number int, req *IssueRequest) (*IssueResponse, error)
+
+	// BotLogin returns the login the client's comments are posted as
+	BotLogin() string
 }
 
+// ErrFileNotFound is returned by GetFileContents when the file does not exist at the ref
+var ErrFileNotFound = errors.New("file not found")
+
 // ClientImpl is the concrete implementation using go-github
 type ClientImpl struct {
 	client   *github.Client
 	owner    string
 	repo     string
 	prNumber int
+
+	// botLogin is looked up on first use if empty; see BotLogin
+	botLogin  string
+	loginOnce sync.Once
 }
 
 // NewClient creates a new GitHub API client
@@ -66,29 +116,63 @@ func NewClient(token, owner, repo string, prNumber int, ghHost string) (Client,
 	if prNumber <= 0 {
 		return nil, errors.New("PR number must be positive")
 	}
+	return newClient(token, owner, repo, prNumber, ghHost)
+}
 
-	ctx := context.Background()
+// NewRepositoryClient creates a GitHub API client that is not bound to a pull
+// request, e.g. for scheduled audits. Pull request methods must not be used.
+func NewRepositoryClient(token, owner, repo string, ghHost string) (Client, error) {
+	if token == "" {
+		return nil, errors.New("GitHub token is required")
+	}
+	if owner == "" {
+		return nil, errors.New("owner is required")
+	}
+	if repo == "" {
+		return nil, errors.New("repo is required")
+	}
+	return newClient(token, owner, repo, 0, ghHost)
+}
+
+// NewAppClient creates a GitHub API client authenticated as the app's
+// installation on owner/repo, or on installationID if it is non-zero.
+// Installation tokens are refreshed automatically. A prNumber of 0 creates a
+// repository client, like NewRepositoryClient.
+func NewAppClient(ctx context.Context, app *App, installationID int64, owner, repo string, prNumber int, ghHost string) (Client, error) {
+	if owner == "" {
+		return nil, errors.New("owner is required")
+	}
+	if repo == "" {
+		return nil, errors.New("repo is required")
+	}
+	if prNumber < 0 {
+		return nil, errors.New("PR number must not be negative")
+	}
+
+	if installationID == 0 {
+		id, err := app.InstallationID(ctx, owner, repo)
+		if err != nil {
+			return nil, err
+		}
+		installationID = id
+	}
+
+	return newClientWithSource(app.InstallationTokenSource(installationID), owner, repo, prNumber, ghHost, app.BotLogin())
+}
+
+func newClient(token, owner, repo string, prNumber int, ghHost string) (Client, error) {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(ctx, ts)
-
-	// Create GitHub client (enterprise or default)
-	var ghClient *github.Client
-	var err error
+	return newClientWithSource(ts, owner, repo, prNumber, ghHost, "")
+}
 
-	if ghHost != "" {
-		// GitHub Enterprise Server
-		baseURL := "https://" + ghHost
-		uploadURL := "https://" + ghHost
+func newClientWithSource(ts oauth2.TokenSource, owner, repo string, prNumber int, ghHost, botLogin string) (Client, error) {
+	tc := oauth2.NewClient(context.Background(), ts)
 
-		ghClient, err = github.NewClient(tc).WithEnterpriseURLs(baseURL, uploadURL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create GitHub Enterprise client for %s: %w", ghHost, err)
-		}
-	} else {
-		// GitHub.com (default)
-		ghClient = github.NewClient(tc)
+	ghClient, err := newGitHubClient(tc, ghHost)
+	if err != nil {
+		return nil, err
 	}
 
 	return &ClientImpl{
@@ -96,9 +180,49 @@ func NewClient(token, owner, repo string, prNumber int, ghHost string) (Client,
 		owner:    owner,
 		repo:     repo,
 		prNumber: prNumber,
+		botLogin: botLogin,
 	}, nil
 }
 
+// newGitHubClient creates a go-github client for GitHub.com, or for the
+// Enterprise Server at ghHost
+func newGitHubClient(httpClient *http.Client, ghHost string) (*github.Client, error) {
+	if ghHost == "" {
+		// GitHub.com (default)
+		return github.NewClient(httpClient), nil
+	}
+
+	// GitHub Enterprise Server
+	baseURL := "https://" + ghHost
+	uploadURL := "https://" + ghHost
+
+	ghClient, err := github.NewClient(httpClient).WithEnterpriseURLs(baseURL, uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub Enterprise client for %s: %w", ghHost, err)
+	}
+	return ghClient, nil
+}
+
+// BotLogin returns the login the client's comments are posted as
+// For tokens it is looked up once: a personal access token posts as its
+// user, while the workflow's GITHUB_TOKEN cannot read the authenticated user
+// and posts as github-actions[bot].
+func (c *ClientImpl) BotLogin() string {
+	c.loginOnce.Do(func() {
+		if c.botLogin != "" {
+			return
+		}
+		c.botLogin = DefaultBotLogin
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if user, _, err := c.client.Users.Get(ctx, ""); err == nil && user.GetLogin() != "" {
+			c.botLogin = user.GetLogin()
+		}
+	})
+	return c.botLogin
+}
+
 // isAuthError checks if an error is related to authentication
 func isAuthError(err error) bool {
 	if err == nil {
@@ -263,6 +387,24 @@ func (c *ClientImpl) CreateIssueComment(ctx context.Context, body string) (*Post
 	}, nil
 }
 
+// UpdateIssueComment replaces the body of an existing PR-level comment
+func (c *ClientImpl) UpdateIssueComment(ctx context.Context, commentID int64, body string) (*PostCommentResponse, error) {
+	comment := &github.IssueComment{
+		Body: github.String(body),
+	}
+
+	updated, _, err := c.client.Issues.EditComment(ctx, c.owner, c.repo, commentID, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostCommentResponse{
+		ID:        updated.GetID(),
+		HTMLURL:   updated.GetHTMLURL(),
+		CreatedAt: updated.GetCreatedAt().Time,
+	}, nil
+}
+
 // CheckRateLimit returns remaining API calls
 // Note: Automatically reads rate limit headers from any GitHub instance (including enterprise)
 func (c *ClientImpl) CheckRateLimit(ctx context.Context) (int, error) {
@@ -393,3 +535,266 @@ func (c *ClientImpl) CheckUserPermission(ctx context.Context, username string) (
 
 	return isAuthorized, permissionLevel, nil
 }
+
+// ListPullRequestFiles fetches the files changed in the pull request
+// Each file includes the unified diff patch GitHub computed for the PR's range
+func (c *ClientImpl) ListPullRequestFiles(ctx context.Context) ([]*PullRequestFile, error) {
+	opts := &github.ListOptions{
+		PerPage: 100,
+	}
+
+	var allFiles []*PullRequestFile
+
+	for {
+		files, resp, err := c.client.PullRequests.ListFiles(ctx, c.owner, c.repo, c.prNumber, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull request files: %w", err)
+		}
+
+		for _, file := range files {
+			allFiles = append(allFiles, &PullRequestFile{
+				Filename:         file.GetFilename(),
+				PreviousFilename: file.GetPreviousFilename(),
+				Status:           file.GetStatus(),
+				Additions:        file.GetAdditions(),
+				Deletions:        file.GetDeletions(),
+				Patch:            file.GetPatch(),
+				SHA:              file.GetSHA(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allFiles, nil
+}
+
+// GetFileContents fetches a file's contents at ref
+// Files over 1 MB are not inlined by the Contents API and are downloaded instead.
+func (c *ClientImpl) GetFileContents(ctx context.Context, path, ref string) ([]byte, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: ref}
+
+	file, dir, resp, err := c.client.Repositories.GetContents(ctx, c.owner, c.repo, path, opts)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get contents of %s at %s: %w", path, ref, err)
+	}
+	if file == nil || dir != nil || file.GetType() != "file" {
+		return nil, ErrFileNotFound
+	}
+
+	if file.GetEncoding() == "none" {
+		reader, _, err := c.client.Repositories.DownloadContents(ctx, c.owner, c.repo, path, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s at %s: %w", path, ref, err)
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode contents of %s at %s: %w", path, ref, err)
+	}
+	return []byte(content), nil
+}
+
+// ListTreeFiles lists all file paths in the tree at ref
+// Returns an error if GitHub truncates the recursive listing (very large repositories).
+func (c *ClientImpl) ListTreeFiles(ctx context.Context, ref string) ([]string, error) {
+	tree, _, err := c.client.Git.GetTree(ctx, c.owner, c.repo, ref, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree at %s: %w", ref, err)
+	}
+	if tree.GetTruncated() {
+		return nil, fmt.Errorf("tree at %s is too large to list through the API", ref)
+	}
+
+	var files []string
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			files = append(files, entry.GetPath())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// GetPullRequest fetches the title, author, refs and URL of the pull request
+func (c *ClientImpl) GetPullRequest(ctx context.Context) (*PullRequestInfo, error) {
+	pr, _, err := c.client.PullRequests.Get(ctx, c.owner, c.repo, c.prNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request #%d: %w", c.prNumber, err)
+	}
+
+	return &PullRequestInfo{
+		Number:  pr.GetNumber(),
+		Title:   pr.GetTitle(),
+		Author:  pr.GetUser().GetLogin(),
+		HTMLURL: pr.GetHTMLURL(),
+		BaseRef: pr.GetBase().GetRef(),
+		BaseSHA: pr.GetBase().GetSHA(),
+		HeadRef: pr.GetHead().GetRef(),
+		HeadSHA: pr.GetHead().GetSHA(),
+	}, nil
+}
+
+// CreateReview submits a review with all of its line comments at once
+// The review is submitted immediately with the given event (e.g. "COMMENT").
+func (c *ClientImpl) CreateReview(ctx context.Context, req *CreateReviewRequest) (*PostCommentResponse, error) {
+	review := &github.PullRequestReviewRequest{
+		CommitID: github.String(req.CommitID),
+		Body:     github.String(req.Body),
+		Event:    github.String(req.Event),
+	}
+	for _, draft := range req.Comments {
+		review.Comments = append(review.Comments, &github.DraftReviewComment{
+			Path: github.String(draft.Path),
+			Body: github.String(draft.Body),
+			Line: github.Int(draft.Line),
+			Side: github.String(draft.Side),
+		})
+	}
+
+	created, _, err := c.client.PullRequests.CreateReview(ctx, c.owner, c.repo, c.prNumber, review)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostCommentResponse{
+		ID:        created.GetID(),
+		HTMLURL:   created.GetHTMLURL(),
+		CreatedAt: created.GetSubmittedAt().Time,
+	}, nil
+}
+
+// CreateCheckRun creates a check run that is already completed with req.Conclusion
+func (c *ClientImpl) CreateCheckRun(ctx context.Context, req *CheckRunRequest) (*CheckRunResponse, error) {
+	opts := github.CreateCheckRunOptions{
+		Name:        req.Name,
+		HeadSHA:     req.HeadSHA,
+		Status:      github.String("completed"),
+		Conclusion:  github.String(req.Conclusion),
+		CompletedAt: &github.Timestamp{Time: time.Now()},
+		Output:      checkRunOutput(req),
+	}
+
+	run, _, err := c.client.Checks.CreateCheckRun(ctx, c.owner, c.repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckRunResponse{ID: run.GetID(), HTMLURL: run.GetHTMLURL()}, nil
+}
+
+// UpdateCheckRun updates a check run's conclusion and output
+func (c *ClientImpl) UpdateCheckRun(ctx context.Context, checkRunID int64, req *CheckRunRequest) (*CheckRunResponse, error) {
+	opts := github.UpdateCheckRunOptions{
+		Name:        req.Name,
+		Status:      github.String("completed"),
+		Conclusion:  github.String(req.Conclusion),
+		CompletedAt: &github.Timestamp{Time: time.Now()},
+		Output:      checkRunOutput(req),
+	}
+
+	run, _, err := c.client.Checks.UpdateCheckRun(ctx, c.owner, c.repo, checkRunID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckRunResponse{ID: run.GetID(), HTMLURL: run.GetHTMLURL()}, nil
+}
+
+// checkRunOutput converts a request into the API's output object
+func checkRunOutput(req *CheckRunRequest) *github.CheckRunOutput {
+	output := &github.CheckRunOutput{
+		Title:   github.String(req.Title),
+		Summary: github.String(req.Summary),
+	}
+	for _, a := range req.Annotations {
+		annotation := &github.CheckRunAnnotation{
+			Path:            github.String(a.Path),
+			StartLine:       github.Int(a.StartLine),
+			EndLine:         github.Int(a.EndLine),
+			AnnotationLevel: github.String(a.Level),
+			Message:         github.String(a.Message),
+		}
+		if a.Title != "" {
+			annotation.Title = github.String(a.Title)
+		}
+		output.Annotations = append(output.Annotations, annotation)
+	}
+	return output
+}
+
+// ListOpenIssues fetches the open issues of the repository opened by creator
+// The filter is applied by the API, so other users' issues are not downloaded.
+// The issues API also returns pull requests; those are left out.
+func (c *ClientImpl) ListOpenIssues(ctx context.Context, creator string) ([]*github.Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		State:   "open",
+		Creator: creator,
+		ListOptions: github.ListOptions{
+			PerPage: 100, // Maximum allowed per page
+		},
+	}
+
+	var allIssues []*github.Issue
+
+	for {
+		issues, resp, err := c.client.Issues.ListByRepo(ctx, c.owner, c.repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+
+		for _, issue := range issues {
+			if !issue.IsPullRequest() {
+				allIssues = append(allIssues, issue)
+			}
+		}
+
+		// Check if there are more pages
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allIssues, nil
+}
+
+// CreateIssue opens an issue with req's title and body
+func (c *ClientImpl) CreateIssue(ctx context.Context, req *IssueRequest) (*IssueResponse, error) {
+	created, _, err := c.client.Issues.Create(ctx, c.owner, c.repo, issueRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return &IssueResponse{Number: created.GetNumber(), HTMLURL: created.GetHTMLURL()}, nil
+}
+
+// UpdateIssue edits an issue's title, body or state
+func (c *ClientImpl) UpdateIssue(ctx context.Context, number int, req *IssueRequest) (*IssueResponse, error) {
+	updated, _, err := c.client.Issues.Edit(ctx, c.owner, c.repo, number, issueRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	return &IssueResponse{Number: updated.GetNumber(), HTMLURL: updated.GetHTMLURL()}, nil
+}
+
+// issueRequest converts req, leaving empty fields unset
+func issueRequest(req *IssueRequest) *github.IssueRequest {
+	issue := &github.IssueRequest{}
+	if req.Title != "" {
+		issue.Title = github.String(req.Title)
+	}
+	if req.Body != "" {
+		issue.Body = github.String(req.Body)
+	}
+	if req.State != "" {
+		issue.State = github.String(req.State)
+	}
+	return issue
+}
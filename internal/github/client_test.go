@@ -1,6 +1,11 @@
 package github
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -177,3 +182,80 @@ func TestNewClient_InvalidEnterpriseURL(t *testing.T) {
 		t.Errorf("NewClient() error should include hostname, got: %v", err)
 	}
 }
+
+// TestClientImpl_BotLogin tests looking up the login a token posts as
+func TestClientImpl_BotLogin(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"personal access token", http.StatusOK, `{"login": "octocat"}`, "octocat"},
+		{"workflow token", http.StatusForbidden, `{"message": "Resource not accessible by integration"}`, DefaultBotLogin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if r.URL.Path != "/user" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			client, err := NewClient("test-token", "owner", "repo", 1, "")
+			if err != nil {
+				t.Fatalf("NewClient() unexpected error: %v", err)
+			}
+			impl := client.(*ClientImpl)
+			impl.client.BaseURL, _ = url.Parse(server.URL + "/")
+
+			for range 2 {
+				if got := client.BotLogin(); got != tt.want {
+					t.Errorf("BotLogin() = %q, want %q", got, tt.want)
+				}
+			}
+			if calls != 1 {
+				t.Errorf("BotLogin() made %d requests, want 1", calls)
+			}
+		})
+	}
+}
+
+// TestClientImpl_ListOpenIssues tests that issues are filtered by creator on the server
+func TestClientImpl_ListOpenIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/issues" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("creator"); got != DefaultBotLogin {
+			t.Errorf("creator = %q, want %q", got, DefaultBotLogin)
+		}
+		if got := r.URL.Query().Get("state"); got != "open" {
+			t.Errorf("state = %q, want open", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"number": 1}, {"number": 2, "pull_request": {"url": "https://example.com/pulls/2"}}]`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient("test-token", "owner", "repo", 1, "")
+	if err != nil {
+		t.Fatalf("NewClient() unexpected error: %v", err)
+	}
+	client.(*ClientImpl).client.BaseURL, _ = url.Parse(server.URL + "/")
+
+	issues, err := client.ListOpenIssues(context.Background(), DefaultBotLogin)
+	if err != nil {
+		t.Fatalf("ListOpenIssues() unexpected error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].GetNumber() != 1 {
+		t.Errorf("ListOpenIssues() = %v, want only issue 1", issues)
+	}
+}
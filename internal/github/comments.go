@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -15,8 +16,11 @@ import (
 // IsBotComment checks if a comment was created by the gitleaks-diff-comment bot
 // It uses two identification methods:
 // 1. Primary: Check for invisible HTML marker in comment body
-// 2. Fallback: Check if comment author is "github-actions[bot]"
-func IsBotComment(comment *github.IssueComment) bool {
+// 2. Fallback: Check if comment author is "github-actions[bot]" or one of
+// botLogins, e.g. the login of the GitHub App the action runs as. Only bot
+// accounts are matched: a personal access token posts as a person, whose own
+// comments carry no marker.
+func IsBotComment(comment *github.IssueComment, botLogins ...string) bool {
 	if comment == nil {
 		return false
 	}
@@ -31,20 +35,16 @@ func IsBotComment(comment *github.IssueComment) bool {
 
 	// Fallback: Check comment author
 	// Handles old comments that may not have the marker
-	if comment.GetUser().GetLogin() == "github-actions[bot]" {
-		return true
-	}
-
-	return false
+	return isBotLogin(comment.GetUser().GetLogin(), botLogins)
 }
 
 // FilterBotComments separates bot comments from human comments
 // Returns only comments that were created by the gitleaks-diff-comment bot
-func FilterBotComments(comments []*github.IssueComment) []*github.IssueComment {
+func FilterBotComments(comments []*github.IssueComment, botLogins ...string) []*github.IssueComment {
 	var botComments []*github.IssueComment
 
 	for _, comment := range comments {
-		if IsBotComment(comment) {
+		if IsBotComment(comment, botLogins...) {
 			botComments = append(botComments, comment)
 		}
 	}
@@ -54,7 +54,7 @@ func FilterBotComments(comments []*github.IssueComment) []*github.IssueComment {
 
 // IsBotReviewComment checks if a review comment was created by the gitleaks-diff-comment bot
 // Review comments are the diff comments posted on specific lines of code
-func IsBotReviewComment(comment *github.PullRequestComment) bool {
+func IsBotReviewComment(comment *github.PullRequestComment, botLogins ...string) bool {
 	if comment == nil {
 		return false
 	}
@@ -69,20 +69,16 @@ func IsBotReviewComment(comment *github.PullRequestComment) bool {
 
 	// Fallback: Check comment author
 	// Handles old comments that may not have the marker
-	if comment.GetUser().GetLogin() == "github-actions[bot]" {
-		return true
-	}
-
-	return false
+	return isBotLogin(comment.GetUser().GetLogin(), botLogins)
 }
 
 // FilterBotReviewComments separates bot review comments from human review comments
 // Returns only review comments that were created by the gitleaks-diff-comment bot
-func FilterBotReviewComments(comments []*github.PullRequestComment) []*github.PullRequestComment {
+func FilterBotReviewComments(comments []*github.PullRequestComment, botLogins ...string) []*github.PullRequestComment {
 	var botComments []*github.PullRequestComment
 
 	for _, comment := range comments {
-		if IsBotReviewComment(comment) {
+		if IsBotReviewComment(comment, botLogins...) {
 			botComments = append(botComments, comment)
 		}
 	}
@@ -90,6 +86,29 @@ func FilterBotReviewComments(comments []*github.PullRequestComment) []*github.Pu
 	return botComments
 }
 
+// isBotLogin returns true if login is github-actions[bot] or one of botLogins
+// that is a bot account. github-actions[bot] is always included for comments
+// posted before the action was switched to a GitHub App.
+func isBotLogin(login string, botLogins []string) bool {
+	if !IsBotAccount(login) {
+		return false
+	}
+	return login == DefaultBotLogin || slices.Contains(botLogins, login)
+}
+
+// isOwnMarked returns true if body carries marker and author is botLogin, the
+// bot account the client posts as. A personal access token posts as a person,
+// whose own comments and issues may quote the marker, so nothing matches then.
+func isOwnMarked(body, author, marker, botLogin string) bool {
+	return strings.Contains(body, marker) && IsBotAccount(botLogin) && author == botLogin
+}
+
+// IsBotAccount returns true if login is a GitHub App or Actions account
+// (e.g. "github-actions[bot]"), as opposed to a person's
+func IsBotAccount(login string) bool {
+	return strings.HasSuffix(login, "[bot]")
+}
+
 // PostComments posts multiple comments concurrently with rate limiting and deduplication
 func PostComments(ctx context.Context, client Client, comments []*comment.GeneratedComment, commentMode string, debug bool) (*ActionOutput, error) {
 	// Fetch existing comments for deduplication
@@ -114,7 +133,17 @@ func PostComments(ctx context.Context, client Client, comments []*comment.Genera
 	// Post comments concurrently with semaphore
 	results := postCommentsConcurrently(ctx, client, comments, existingComments, commentMode, debug)
 
-	// Aggregate results
+	output := newActionOutput(results)
+
+	if debug {
+		log.Printf("Summary: Posted=%d, Skipped=%d, Errors=%d", output.Posted, output.SkippedDuplicates, output.Errors)
+	}
+
+	return output, nil
+}
+
+// newActionOutput aggregates comment results into the action output
+func newActionOutput(results []CommentResult) *ActionOutput {
 	output := &ActionOutput{
 		Results: results,
 	}
@@ -131,12 +160,7 @@ func PostComments(ctx context.Context, client Client, comments []*comment.Genera
 			output.Errors++
 		}
 	}
-
-	if debug {
-		log.Printf("Summary: Posted=%d, Skipped=%d, Errors=%d", output.Posted, output.SkippedDuplicates, output.Errors)
-	}
-
-	return output, nil
+	return output
 }
 
 // postCommentsConcurrently posts comments with controlled concurrency
@@ -169,14 +193,16 @@ func postCommentsConcurrently(ctx context.Context, client Client, comments []*co
 				return
 			}
 
-			if commentMode == "append" && existingComment != nil {
+			if commentMode == "append" {
 				// Append mode: skip if duplicate exists
-				if isDuplicateContent(comm, existingComment) {
+				if isDuplicate(comm, existingComments) {
 					if debug {
 						log.Printf("[%d/%d] Skipping duplicate comment at line %d (%s)", idx+1, len(comments), comm.Line, comm.Side)
 					}
 					resultChan <- CommentResult{
 						Status:      "skipped_duplicate",
+						Path:        comm.Path,
+						Line:        comm.Line,
 						BodyPreview: comm.GetBodyPreview(),
 					}
 					return
@@ -262,6 +288,8 @@ func postCommentWithRetry(ctx context.Context, client Client, comm *comment.Gene
 			return CommentResult{
 				Status:      "error",
 				Error:       err.Error(),
+				Path:        comm.Path,
+				Line:        comm.Line,
 				BodyPreview: comm.GetBodyPreview(),
 			}
 		}
@@ -274,6 +302,8 @@ func postCommentWithRetry(ctx context.Context, client Client, comm *comment.Gene
 			Status:      "posted",
 			CommentID:   resp.ID,
 			CommentURL:  resp.HTMLURL,
+			Path:        comm.Path,
+			Line:        comm.Line,
 			BodyPreview: comm.GetBodyPreview(),
 		}
 	}
@@ -282,6 +312,8 @@ func postCommentWithRetry(ctx context.Context, client Client, comm *comment.Gene
 	return CommentResult{
 		Status:      "error",
 		Error:       "max retries exceeded",
+		Path:        comm.Path,
+		Line:        comm.Line,
 		BodyPreview: comm.GetBodyPreview(),
 	}
 }
@@ -318,6 +350,28 @@ func extractMarker(body string) string {
 	return body[start : start+end+4] // Include " -->"
 }
 
+// isDuplicate checks if an identical comment already exists at the same location
+// Location is matched by marker when present, otherwise by path and position
+func isDuplicate(newComment *comment.GeneratedComment, existingComments []*ExistingComment) bool {
+	marker := extractMarker(newComment.Body)
+
+	for _, existing := range existingComments {
+		if marker != "" {
+			if extractMarker(existing.Body) != marker {
+				continue
+			}
+		} else if existing.Path != newComment.Path || existing.Position != newComment.Position {
+			continue
+		}
+
+		if isDuplicateContent(newComment, existing) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // isDuplicateContent checks if comment content is duplicate (for append mode)
 func isDuplicateContent(newComment *comment.GeneratedComment, existingComment *ExistingComment) bool {
 	// Normalize whitespace for comparison
@@ -361,6 +415,8 @@ func updateCommentWithRetry(ctx context.Context, client Client, comm *comment.Ge
 			return CommentResult{
 				Status:      "error",
 				Error:       err.Error(),
+				Path:        comm.Path,
+				Line:        comm.Line,
 				BodyPreview: comm.GetBodyPreview(),
 			}
 		}
@@ -373,6 +429,8 @@ func updateCommentWithRetry(ctx context.Context, client Client, comm *comment.Ge
 			Status:      "updated",
 			CommentID:   resp.ID,
 			CommentURL:  resp.HTMLURL,
+			Path:        comm.Path,
+			Line:        comm.Line,
 			BodyPreview: comm.GetBodyPreview(),
 		}
 	}
@@ -381,6 +439,8 @@ func updateCommentWithRetry(ctx context.Context, client Client, comm *comment.Ge
 	return CommentResult{
 		Status:      "error",
 		Error:       "max retries exceeded",
+		Path:        comm.Path,
+		Line:        comm.Line,
 		BodyPreview: comm.GetBodyPreview(),
 	}
 }
@@ -9,16 +9,35 @@ import (
 	"time"
 
 	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
-	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+	"github.com/google/go-github/v57/github"
 )
 
 // MockClient is a mock implementation of the GitHub Client interface
 type MockClient struct {
-	CreateReviewCommentFunc func(ctx context.Context, req *PostCommentRequest) (*PostCommentResponse, error)
-	UpdateReviewCommentFunc func(ctx context.Context, req *UpdateCommentRequest) (*PostCommentResponse, error)
-	ListReviewCommentsFunc  func(ctx context.Context) ([]*ExistingComment, error)
-	CreateIssueCommentFunc  func(ctx context.Context, body string) (*PostCommentResponse, error)
-	CheckRateLimitFunc      func(ctx context.Context) (int, error)
+	CreateReviewCommentFunc  func(ctx context.Context, req *PostCommentRequest) (*PostCommentResponse, error)
+	UpdateReviewCommentFunc  func(ctx context.Context, req *UpdateCommentRequest) (*PostCommentResponse, error)
+	ListReviewCommentsFunc   func(ctx context.Context) ([]*ExistingComment, error)
+	CreateIssueCommentFunc   func(ctx context.Context, body string) (*PostCommentResponse, error)
+	UpdateIssueCommentFunc   func(ctx context.Context, commentID int64, body string) (*PostCommentResponse, error)
+	CheckRateLimitFunc       func(ctx context.Context) (int, error)
+	ListPRCommentsFunc       func(ctx context.Context) ([]*github.IssueComment, error)
+	ListPRReviewCommentsFunc func(ctx context.Context) ([]*github.PullRequestComment, error)
+	DeleteCommentFunc        func(ctx context.Context, commentID int64) error
+	DeleteReviewCommentFunc  func(ctx context.Context, commentID int64) error
+	CheckUserPermissionFunc  func(ctx context.Context, username string) (bool, string, error)
+	ListPullRequestFilesFunc func(ctx context.Context) ([]*PullRequestFile, error)
+	GetFileContentsFunc      func(ctx context.Context, path, ref string) ([]byte, error)
+	ListTreeFilesFunc        func(ctx context.Context, ref string) ([]string, error)
+	GetPullRequestFunc       func(ctx context.Context) (*PullRequestInfo, error)
+	CreateReviewFunc         func(ctx context.Context, req *CreateReviewRequest) (*PostCommentResponse, error)
+	CreateCheckRunFunc       func(ctx context.Context, req *CheckRunRequest) (*CheckRunResponse, error)
+	UpdateCheckRunFunc       func(ctx context.Context, checkRunID int64, req *CheckRunRequest) (*CheckRunResponse, error)
+	ListOpenIssuesFunc       func(ctx context.Context, creator string) ([]*github.Issue, error)
+	CreateIssueFunc          func(ctx context.Context, req *IssueRequest) (*IssueResponse, error)
+	UpdateIssueFunc          func(ctx context.Context, number int, req *IssueRequest) (*IssueResponse, error)
+
+	// Login is returned by BotLogin; empty means github-actions[bot]
+	Login string
 }
 
 func (m *MockClient) CreateReviewComment(ctx context.Context, req *PostCommentRequest) (*PostCommentResponse, error) {
@@ -49,6 +68,13 @@ func (m *MockClient) CreateIssueComment(ctx context.Context, body string) (*Post
 	return &PostCommentResponse{ID: 123, HTMLURL: "https://github.com/test"}, nil
 }
 
+func (m *MockClient) UpdateIssueComment(ctx context.Context, commentID int64, body string) (*PostCommentResponse, error) {
+	if m.UpdateIssueCommentFunc != nil {
+		return m.UpdateIssueCommentFunc(ctx, commentID, body)
+	}
+	return &PostCommentResponse{ID: commentID, HTMLURL: "https://github.com/test"}, nil
+}
+
 func (m *MockClient) CheckRateLimit(ctx context.Context) (int, error) {
 	if m.CheckRateLimitFunc != nil {
 		return m.CheckRateLimitFunc(ctx)
@@ -56,6 +82,118 @@ func (m *MockClient) CheckRateLimit(ctx context.Context) (int, error) {
 	return 5000, nil
 }
 
+func (m *MockClient) ListPRComments(ctx context.Context) ([]*github.IssueComment, error) {
+	if m.ListPRCommentsFunc != nil {
+		return m.ListPRCommentsFunc(ctx)
+	}
+	return []*github.IssueComment{}, nil
+}
+
+func (m *MockClient) ListPRReviewComments(ctx context.Context) ([]*github.PullRequestComment, error) {
+	if m.ListPRReviewCommentsFunc != nil {
+		return m.ListPRReviewCommentsFunc(ctx)
+	}
+	return []*github.PullRequestComment{}, nil
+}
+
+func (m *MockClient) DeleteComment(ctx context.Context, commentID int64) error {
+	if m.DeleteCommentFunc != nil {
+		return m.DeleteCommentFunc(ctx, commentID)
+	}
+	return nil
+}
+
+func (m *MockClient) DeleteReviewComment(ctx context.Context, commentID int64) error {
+	if m.DeleteReviewCommentFunc != nil {
+		return m.DeleteReviewCommentFunc(ctx, commentID)
+	}
+	return nil
+}
+
+func (m *MockClient) CheckUserPermission(ctx context.Context, username string) (bool, string, error) {
+	if m.CheckUserPermissionFunc != nil {
+		return m.CheckUserPermissionFunc(ctx, username)
+	}
+	return true, "write", nil
+}
+
+func (m *MockClient) ListPullRequestFiles(ctx context.Context) ([]*PullRequestFile, error) {
+	if m.ListPullRequestFilesFunc != nil {
+		return m.ListPullRequestFilesFunc(ctx)
+	}
+	return []*PullRequestFile{}, nil
+}
+
+func (m *MockClient) GetFileContents(ctx context.Context, path, ref string) ([]byte, error) {
+	if m.GetFileContentsFunc != nil {
+		return m.GetFileContentsFunc(ctx, path, ref)
+	}
+	return nil, ErrFileNotFound
+}
+
+func (m *MockClient) ListTreeFiles(ctx context.Context, ref string) ([]string, error) {
+	if m.ListTreeFilesFunc != nil {
+		return m.ListTreeFilesFunc(ctx, ref)
+	}
+	return []string{}, nil
+}
+
+func (m *MockClient) GetPullRequest(ctx context.Context) (*PullRequestInfo, error) {
+	if m.GetPullRequestFunc != nil {
+		return m.GetPullRequestFunc(ctx)
+	}
+	return &PullRequestInfo{Number: 1}, nil
+}
+
+func (m *MockClient) CreateReview(ctx context.Context, req *CreateReviewRequest) (*PostCommentResponse, error) {
+	if m.CreateReviewFunc != nil {
+		return m.CreateReviewFunc(ctx, req)
+	}
+	return &PostCommentResponse{ID: 456, HTMLURL: "https://github.com/test#pullrequestreview-456"}, nil
+}
+
+func (m *MockClient) CreateCheckRun(ctx context.Context, req *CheckRunRequest) (*CheckRunResponse, error) {
+	if m.CreateCheckRunFunc != nil {
+		return m.CreateCheckRunFunc(ctx, req)
+	}
+	return &CheckRunResponse{ID: 789, HTMLURL: "https://github.com/test/runs/789"}, nil
+}
+
+func (m *MockClient) UpdateCheckRun(ctx context.Context, checkRunID int64, req *CheckRunRequest) (*CheckRunResponse, error) {
+	if m.UpdateCheckRunFunc != nil {
+		return m.UpdateCheckRunFunc(ctx, checkRunID, req)
+	}
+	return &CheckRunResponse{ID: checkRunID, HTMLURL: "https://github.com/test/runs/789"}, nil
+}
+
+func (m *MockClient) ListOpenIssues(ctx context.Context, creator string) ([]*github.Issue, error) {
+	if m.ListOpenIssuesFunc != nil {
+		return m.ListOpenIssuesFunc(ctx, creator)
+	}
+	return []*github.Issue{}, nil
+}
+
+func (m *MockClient) CreateIssue(ctx context.Context, req *IssueRequest) (*IssueResponse, error) {
+	if m.CreateIssueFunc != nil {
+		return m.CreateIssueFunc(ctx, req)
+	}
+	return &IssueResponse{Number: 42, HTMLURL: "https://github.com/test/issues/42"}, nil
+}
+
+func (m *MockClient) UpdateIssue(ctx context.Context, number int, req *IssueRequest) (*IssueResponse, error) {
+	if m.UpdateIssueFunc != nil {
+		return m.UpdateIssueFunc(ctx, number, req)
+	}
+	return &IssueResponse{Number: number, HTMLURL: fmt.Sprintf("https://github.com/test/issues/%d", number)}, nil
+}
+
+func (m *MockClient) BotLogin() string {
+	if m.Login != "" {
+		return m.Login
+	}
+	return DefaultBotLogin
+}
+
 func TestPostComments_Concurrency(t *testing.T) {
 	// Create 20 test comments
 	comments := make([]*comment.GeneratedComment, 20)
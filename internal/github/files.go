@@ -0,0 +1,152 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+// PullRequestFilesSource reads ignore file changes from the GitHub
+// pull request files API. It needs no local checkout or git history.
+type PullRequestFilesSource struct {
+	client   Client
+	headSHA  string
+	patterns []string
+
+	// files caches the PR file list so Changes and InlineAllows share one listing
+	files []*PullRequestFile
+}
+
+// NewPullRequestFilesSource creates a diff source backed by the PR files API
+// Only files matching one of patterns are parsed; nil selects the root .gitleaksignore.
+// Entry metadata is read from the ignore files at headSHA; if it is empty,
+// only the comments in the patches are seen.
+func NewPullRequestFilesSource(client Client, headSHA string, patterns []string) *PullRequestFilesSource {
+	if len(patterns) == 0 {
+		patterns = []string{diff.GitleaksIgnorePath}
+	}
+	return &PullRequestFilesSource{client: client, headSHA: headSHA, patterns: patterns}
+}
+
+// Changes fetches the PR's ignore file patches and parses them
+func (s *PullRequestFilesSource) Changes(ctx context.Context) ([]diff.DiffChange, error) {
+	files, err := s.listFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []diff.DiffChange{}
+	for _, file := range files {
+		if !diff.MatchAnyGlob(s.patterns, file.Filename) {
+			continue
+		}
+
+		// GitHub omits the patch for very large diffs and for binary files
+		if file.Patch == "" {
+			if file.Additions == 0 && file.Deletions == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("GitHub did not return a patch for %s (%d additions, %d deletions); the diff may be too large\n"+
+				"  → Action: Use a full checkout and set diff-source: git", file.Filename, file.Additions, file.Deletions)
+		}
+
+		fileChanges, err := diff.ParsePatch(file.Filename, []byte(file.Patch))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse patch for %s: %w", file.Filename, err)
+		}
+		s.attachMetadata(ctx, file, fileChanges)
+		changes = append(changes, fileChanges...)
+	}
+
+	return changes, nil
+}
+
+// attachMetadata reads the justifications of the entries in changes from the
+// file at the head commit, since patches omit comments more than a few lines
+// above a change. If the file cannot be read, the patch's comments are kept.
+func (s *PullRequestFilesSource) attachMetadata(ctx context.Context, file *PullRequestFile, changes []diff.DiffChange) {
+	if s.headSHA == "" || file.Status == "removed" || len(changes) == 0 {
+		return
+	}
+	content, err := s.client.GetFileContents(ctx, file.Filename, s.headSHA)
+	if err != nil {
+		log.Printf("Warning: failed to read %s at %s for entry metadata: %v", file.Filename, s.headSHA, err)
+		return
+	}
+	diff.AttachMetadata(changes, content)
+}
+
+// InlineAllows scans the patch of every file in the PR for gitleaks:allow annotations
+// Files for which GitHub omits the patch (binary or very large) cannot be scanned
+// and are skipped with a warning.
+func (s *PullRequestFilesSource) InlineAllows(ctx context.Context) ([]diff.InlineAllow, error) {
+	files, err := s.listFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	allows := []diff.InlineAllow{}
+	for _, file := range files {
+		if file.Patch == "" {
+			if file.Additions > 0 {
+				log.Printf("Warning: GitHub did not return a patch for %s; gitleaks:allow annotations in it cannot be detected", file.Filename)
+			}
+			continue
+		}
+
+		fileAllows, err := diff.ScanInlineAllows(file.Filename, []byte(file.Patch))
+		if err != nil {
+			return nil, err
+		}
+		allows = append(allows, fileAllows...)
+	}
+
+	return allows, nil
+}
+
+// listFiles returns the PR's files, fetching them on first use
+func (s *PullRequestFilesSource) listFiles(ctx context.Context) ([]*PullRequestFile, error) {
+	if s.files != nil {
+		return s.files, nil
+	}
+	files, err := s.client.ListPullRequestFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.files = files
+	return files, nil
+}
+
+// Name returns a description of the source for logging
+func (s *PullRequestFilesSource) Name() string {
+	return "pull request files API"
+}
+
+// ContentsReader reads file contents through the GitHub Contents API
+// It implements diff.BlobReader and diff.TreeReader for use without a local checkout.
+type ContentsReader struct {
+	client Client
+}
+
+// NewContentsReader creates a reader backed by the Contents and Git Trees APIs
+func NewContentsReader(client Client) *ContentsReader {
+	return &ContentsReader{client: client}
+}
+
+// ReadBlob returns the contents of path at rev
+// Returns diff.ErrObjectNotFound if the file does not exist at that revision
+func (r *ContentsReader) ReadBlob(ctx context.Context, rev, path string) ([]byte, error) {
+	content, err := r.client.GetFileContents(ctx, path, rev)
+	if errors.Is(err, ErrFileNotFound) {
+		return nil, diff.ErrObjectNotFound
+	}
+	return content, err
+}
+
+// ListFiles returns the paths of all files at rev
+func (r *ContentsReader) ListFiles(ctx context.Context, rev string) ([]string, error) {
+	return r.client.ListTreeFiles(ctx, rev)
+}
@@ -0,0 +1,212 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+func TestPullRequestFilesSource_Changes(t *testing.T) {
+	mockClient := &MockClient{
+		ListPullRequestFilesFunc: func(ctx context.Context) ([]*PullRequestFile, error) {
+			return []*PullRequestFile{
+				{
+					Filename:  "README.md",
+					Status:    "modified",
+					Additions: 1,
+					Patch:     "@@ -1 +1,2 @@\n hello\n+world.txt",
+				},
+				{
+					Filename:  ".gitleaksignore",
+					Status:    "modified",
+					Additions: 1,
+					Deletions: 1,
+					Patch:     "@@ -1,3 +1,3 @@\n # header\n-old.yml:3\n+new.yml:4\n keep.txt",
+				},
+			}, nil
+		},
+	}
+
+	source := NewPullRequestFilesSource(mockClient, "", nil)
+	changes, err := source.Changes(context.Background())
+	if err != nil {
+		t.Fatalf("Changes() unexpected error: %v", err)
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d: %+v", len(changes), changes)
+	}
+	if !changes[0].IsDeletion() || changes[0].Content != "old.yml:3" {
+		t.Errorf("changes[0] = %+v, want deletion of old.yml:3", changes[0])
+	}
+	if !changes[1].IsAddition() || changes[1].Content != "new.yml:4" || changes[1].LineNumber != 2 {
+		t.Errorf("changes[1] = %+v, want addition of new.yml:4 at line 2", changes[1])
+	}
+}
+
+func TestPullRequestFilesSource_MetadataFromHead(t *testing.T) {
+	mockClient := &MockClient{
+		ListPullRequestFilesFunc: func(ctx context.Context) ([]*PullRequestFile, error) {
+			return []*PullRequestFile{
+				{
+					Filename:  ".gitleaksignore",
+					Status:    "modified",
+					Additions: 1,
+					Deletions: 1,
+					Patch:     "@@ -3,4 +3,4 @@\n # c\n # d\n # e\n-key.pem:private-key:1\n+key.pem:private-key:2",
+				},
+			}, nil
+		},
+		GetFileContentsFunc: func(ctx context.Context, path, ref string) ([]byte, error) {
+			if path != ".gitleaksignore" || ref != "headsha" {
+				t.Errorf("GetFileContents(%q, %q), want .gitleaksignore at headsha", path, ref)
+			}
+			return []byte("# reason: test fixture\n# ticket: SEC-123\n# c\n# d\n# e\nkey.pem:private-key:2\n"), nil
+		},
+	}
+
+	changes, err := NewPullRequestFilesSource(mockClient, "headsha", nil).Changes(context.Background())
+	if err != nil {
+		t.Fatalf("Changes() unexpected error: %v", err)
+	}
+	if len(changes) != 1 || !changes[0].IsModification() {
+		t.Fatalf("expected one modification, got %+v", changes)
+	}
+	if meta := changes[0].Metadata; meta == nil || meta.Reason != "test fixture" || meta.Ticket != "SEC-123" {
+		t.Errorf("Metadata = %+v, want the reason and ticket from the head file", meta)
+	}
+}
+
+func TestPullRequestFilesSource_NotChanged(t *testing.T) {
+	mockClient := &MockClient{
+		ListPullRequestFilesFunc: func(ctx context.Context) ([]*PullRequestFile, error) {
+			return []*PullRequestFile{
+				{Filename: "src/.gitleaksignore", Status: "added", Additions: 1, Patch: "@@ -0,0 +1 @@\n+a.txt"},
+			}, nil
+		},
+	}
+
+	changes, err := NewPullRequestFilesSource(mockClient, "", nil).Changes(context.Background())
+	if err != nil {
+		t.Fatalf("Changes() unexpected error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes when root .gitleaksignore is untouched, got %+v", changes)
+	}
+}
+
+func TestPullRequestFilesSource_MissingPatch(t *testing.T) {
+	mockClient := &MockClient{
+		ListPullRequestFilesFunc: func(ctx context.Context) ([]*PullRequestFile, error) {
+			return []*PullRequestFile{
+				{Filename: ".gitleaksignore", Status: "modified", Additions: 5000},
+			}, nil
+		},
+	}
+
+	_, err := NewPullRequestFilesSource(mockClient, "", nil).Changes(context.Background())
+	if err == nil {
+		t.Fatal("Changes() expected error when GitHub omits the patch")
+	}
+	if !strings.Contains(err.Error(), "diff-source: git") {
+		t.Errorf("error should suggest using the git diff source, got: %v", err)
+	}
+}
+
+func TestPullRequestFilesSource_APIError(t *testing.T) {
+	mockClient := &MockClient{
+		ListPullRequestFilesFunc: func(ctx context.Context) ([]*PullRequestFile, error) {
+			return nil, errors.New("boom")
+		},
+	}
+
+	if _, err := NewPullRequestFilesSource(mockClient, "", nil).Changes(context.Background()); err == nil {
+		t.Error("Changes() expected error when the API call fails")
+	}
+}
+
+func TestPullRequestFilesSource_NestedFiles(t *testing.T) {
+	mockClient := &MockClient{
+		ListPullRequestFilesFunc: func(ctx context.Context) ([]*PullRequestFile, error) {
+			return []*PullRequestFile{
+				{Filename: ".gitleaksignore", Status: "modified", Additions: 1, Patch: "@@ -0,0 +1 @@\n+root.txt"},
+				{Filename: "services/api/.gitleaksignore", Status: "added", Additions: 1, Patch: "@@ -0,0 +1 @@\n+config.yml:2"},
+				{Filename: "services/api/main.go", Status: "modified", Additions: 1, Patch: "@@ -0,0 +1 @@\n+package main"},
+			}, nil
+		},
+	}
+
+	changes, err := NewPullRequestFilesSource(mockClient, "", []string{"**/.gitleaksignore"}).Changes(context.Background())
+	if err != nil {
+		t.Fatalf("Changes() unexpected error: %v", err)
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d: %+v", len(changes), changes)
+	}
+	if changes[0].FilePath != ".gitleaksignore" || changes[0].Content != "root.txt" {
+		t.Errorf("changes[0] = %+v, want root.txt in .gitleaksignore", changes[0])
+	}
+	if changes[1].FilePath != "services/api/.gitleaksignore" || changes[1].Content != "config.yml:2" {
+		t.Errorf("changes[1] = %+v, want config.yml:2 in services/api/.gitleaksignore", changes[1])
+	}
+}
+
+func TestPullRequestFilesSource_InlineAllows(t *testing.T) {
+	calls := 0
+	mockClient := &MockClient{
+		ListPullRequestFilesFunc: func(ctx context.Context) ([]*PullRequestFile, error) {
+			calls++
+			return []*PullRequestFile{
+				{Filename: "app/config.py", Status: "modified", Additions: 2, Patch: "@@ -3,1 +3,3 @@\n x = 1\n+key = \"a\"  # gitleaks:allow\n+y = 2"},
+				{Filename: "assets/logo.png", Status: "added", Additions: 1},
+				{Filename: ".gitleaksignore", Status: "modified", Additions: 1, Patch: "@@ -0,0 +1 @@\n+a.txt"},
+			}, nil
+		},
+	}
+
+	source := NewPullRequestFilesSource(mockClient, "", nil)
+	if _, err := source.Changes(context.Background()); err != nil {
+		t.Fatalf("Changes() unexpected error: %v", err)
+	}
+	allows, err := source.InlineAllows(context.Background())
+	if err != nil {
+		t.Fatalf("InlineAllows() unexpected error: %v", err)
+	}
+
+	if len(allows) != 1 || allows[0].FilePath != "app/config.py" || allows[0].LineNumber != 4 {
+		t.Errorf("expected gitleaks:allow at app/config.py:4, got %+v", allows)
+	}
+	if calls != 1 {
+		t.Errorf("expected the file list to be fetched once, got %d calls", calls)
+	}
+}
+
+func TestContentsReader_ReadBlob(t *testing.T) {
+	mockClient := &MockClient{
+		GetFileContentsFunc: func(ctx context.Context, path, ref string) ([]byte, error) {
+			switch path {
+			case "config/app.yml":
+				return []byte("a\nb\n"), nil
+			case "broken.yml":
+				return nil, errors.New("boom")
+			}
+			return nil, ErrFileNotFound
+		},
+	}
+	reader := NewContentsReader(mockClient)
+
+	content, err := reader.ReadBlob(context.Background(), "abc123", "config/app.yml")
+	if err != nil || string(content) != "a\nb\n" {
+		t.Errorf("ReadBlob() = %q, %v; want file contents", content, err)
+	}
+	if _, err := reader.ReadBlob(context.Background(), "abc123", "missing.yml"); !errors.Is(err, diff.ErrObjectNotFound) {
+		t.Errorf("ReadBlob() error = %v, want diff.ErrObjectNotFound", err)
+	}
+	if _, err := reader.ReadBlob(context.Background(), "abc123", "broken.yml"); err == nil || errors.Is(err, diff.ErrObjectNotFound) {
+		t.Errorf("ReadBlob() error = %v, want API error", err)
+	}
+}
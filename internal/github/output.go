@@ -0,0 +1,163 @@
+package github
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// resultIcons marks each comment result status in the job summary
+var resultIcons = map[string]string{
+	"posted":            "✅",
+	"updated":           "✏️",
+	"skipped_duplicate": "⊘",
+	"error":             "❌",
+}
+
+// CommentURLs returns the URLs of the comments that were posted or updated,
+// once each (comments delivered in one review share its URL)
+func (o *ActionOutput) CommentURLs() []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, result := range o.Results {
+		if result.Status != "posted" && result.Status != "updated" {
+			continue
+		}
+		if result.CommentURL == "" || seen[result.CommentURL] {
+			continue
+		}
+		seen[result.CommentURL] = true
+		urls = append(urls, result.CommentURL)
+	}
+	return urls
+}
+
+// WriteOutputs appends the step outputs to the GITHUB_OUTPUT file at path:
+// the counts, the posted comment URLs (one per line), the check run URL and
+// the full results as JSON
+func WriteOutputs(path string, output *ActionOutput) error {
+	resultsJSON, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal results: %w", err)
+	}
+
+	var b strings.Builder
+	for _, kv := range [][2]string{
+		{"posted", strconv.Itoa(output.Posted)},
+		{"skipped_duplicates", strconv.Itoa(output.SkippedDuplicates)},
+		{"errors", strconv.Itoa(output.Errors)},
+		{"comment_urls", strings.Join(output.CommentURLs(), "\n")},
+		{"check_run_url", output.CheckRunURL},
+		{"results_json", string(resultsJSON)},
+	} {
+		if err := writeOutput(&b, kv[0], kv[1]); err != nil {
+			return err
+		}
+	}
+
+	return appendFile(path, b.String())
+}
+
+// writeOutput writes one name=value output, using a heredoc-style delimiter
+// for values that span lines
+func writeOutput(b *strings.Builder, name, value string) error {
+	if !strings.ContainsAny(value, "\r\n") {
+		fmt.Fprintf(b, "%s=%s\n", name, value)
+		return nil
+	}
+
+	delimiter, err := outputDelimiter(value)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(b, "%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter)
+	return nil
+}
+
+// outputDelimiter returns a random delimiter that does not occur in value, so
+// a value cannot end its own heredoc and inject other outputs
+func outputDelimiter(value string) (string, error) {
+	buf := make([]byte, 16)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to generate output delimiter: %w", err)
+		}
+		delimiter := "ghadelimiter_" + hex.EncodeToString(buf)
+		if !strings.Contains(value, delimiter) {
+			return delimiter, nil
+		}
+	}
+}
+
+// WriteStepSummary appends the job summary to the GITHUB_STEP_SUMMARY file at path
+func WriteStepSummary(path string, output *ActionOutput) error {
+	return appendFile(path, StepSummary(output))
+}
+
+// StepSummary renders the results as a Markdown job summary: the counts,
+// followed by a table with a row per comment result, including errors
+func StepSummary(output *ActionOutput) string {
+	var b strings.Builder
+	b.WriteString("## 🔍 Gitleaks Diff Comment\n\n")
+	fmt.Fprintf(&b, "%d posted, %d skipped as duplicates, %d %s.\n",
+		output.Posted, output.SkippedDuplicates, output.Errors, pluralize(output.Errors, "error"))
+	if output.CheckRunURL != "" {
+		fmt.Fprintf(&b, "\nCheck run: %s\n", output.CheckRunURL)
+	}
+
+	if len(output.Results) == 0 {
+		b.WriteString("\nNo comments were posted.\n")
+		return b.String()
+	}
+
+	b.WriteString("\n| Status | Location | Comment | Details |\n")
+	b.WriteString("|--------|----------|---------|---------|\n")
+	for _, result := range output.Results {
+		location := "—"
+		if result.Path != "" {
+			location = fmt.Sprintf("`%s:%d`", result.Path, result.Line)
+		}
+		link := "—"
+		if result.CommentURL != "" {
+			link = fmt.Sprintf("[view](%s)", result.CommentURL)
+		}
+		details := result.BodyPreview
+		if result.Status == "error" {
+			details = result.Error
+		}
+		fmt.Fprintf(&b, "| %s %s | %s | %s | %s |\n",
+			resultIcons[result.Status], result.Status, location, link, tableCell(details))
+	}
+
+	return b.String()
+}
+
+// tableCellEscaper keeps cell text on one line, in its cell and visible:
+// previews start with the hidden comment marker
+var tableCellEscaper = strings.NewReplacer("|", "\\|", "<", "&lt;", ">", "&gt;")
+
+// tableCell makes text safe to place in a Markdown table cell
+func tableCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return "—"
+	}
+	return tableCellEscaper.Replace(text)
+}
+
+// appendFile appends content to the file at path, creating it if needed
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,124 @@
+package github
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readOutputs parses a GITHUB_OUTPUT file, including heredoc-style values
+func readOutputs(t *testing.T, path string) map[string]string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := make(map[string]string)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		if name, delimiter, ok := strings.Cut(lines[i], "<<"); ok {
+			var value []string
+			for i++; i < len(lines) && lines[i] != delimiter; i++ {
+				value = append(value, lines[i])
+			}
+			outputs[name] = strings.Join(value, "\n")
+			continue
+		}
+		name, value, _ := strings.Cut(lines[i], "=")
+		outputs[name] = value
+	}
+	return outputs
+}
+
+func TestWriteOutputs(t *testing.T) {
+	output := &ActionOutput{
+		Posted:            2,
+		SkippedDuplicates: 1,
+		Errors:            1,
+		Results: []CommentResult{
+			{Status: "posted", CommentURL: "https://github.com/o/r/pull/1#discussion_r1", Path: ".gitleaksignore", Line: 3},
+			{Status: "updated", CommentURL: "https://github.com/o/r/pull/1#issuecomment-2"},
+			{Status: "skipped_duplicate", Path: ".gitleaksignore", Line: 4},
+			{Status: "error", Error: "boom"},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "output")
+	if err := os.WriteFile(path, []byte("earlier=step\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteOutputs(path, output); err != nil {
+		t.Fatalf("WriteOutputs() unexpected error: %v", err)
+	}
+
+	outputs := readOutputs(t, path)
+	if outputs["earlier"] != "step" {
+		t.Errorf("existing outputs were not preserved: %v", outputs)
+	}
+	if outputs["posted"] != "2" || outputs["skipped_duplicates"] != "1" || outputs["errors"] != "1" {
+		t.Errorf("counts = %q, %q, %q, want 2, 1 and 1", outputs["posted"], outputs["skipped_duplicates"], outputs["errors"])
+	}
+	wantURLs := "https://github.com/o/r/pull/1#discussion_r1\nhttps://github.com/o/r/pull/1#issuecomment-2"
+	if outputs["comment_urls"] != wantURLs {
+		t.Errorf("comment_urls = %q, want %q", outputs["comment_urls"], wantURLs)
+	}
+
+	var decoded ActionOutput
+	if err := json.Unmarshal([]byte(outputs["results_json"]), &decoded); err != nil {
+		t.Fatalf("results_json is not valid JSON: %v\n%s", err, outputs["results_json"])
+	}
+	if len(decoded.Results) != 4 || decoded.Results[0].Path != ".gitleaksignore" {
+		t.Errorf("results_json = %+v, want the 4 results", decoded)
+	}
+}
+
+func TestCommentURLs(t *testing.T) {
+	review := "https://github.com/o/r/pull/1#pullrequestreview-9"
+	output := &ActionOutput{Results: []CommentResult{
+		{Status: "posted", CommentURL: review},
+		{Status: "posted", CommentURL: review},
+		{Status: "skipped_duplicate", CommentURL: "https://github.com/o/r/pull/1#issuecomment-3"},
+		{Status: "error"},
+	}}
+
+	if got := output.CommentURLs(); len(got) != 1 || got[0] != review {
+		t.Errorf("CommentURLs() = %v, want only the review URL once", got)
+	}
+}
+
+func TestStepSummary(t *testing.T) {
+	output := &ActionOutput{
+		Posted:      1,
+		Errors:      1,
+		CheckRunURL: "https://github.com/o/r/runs/5",
+		Results: []CommentResult{
+			{
+				Status:      "posted",
+				CommentURL:  "https://github.com/o/r/pull/1#discussion_r1",
+				Path:        ".gitleaksignore",
+				Line:        3,
+				BodyPreview: "<!-- gitleaks-diff-comment: .gitleaksignore:3:RIGHT --> 🔒 **Gitleaks Exclusion Added**",
+			},
+			{Status: "error", Error: "422 Validation Failed |\nline must be part of the diff"},
+		},
+	}
+
+	summary := StepSummary(output)
+	for _, want := range []string{
+		"1 posted, 0 skipped as duplicates, 1 error.",
+		"Check run: https://github.com/o/r/runs/5",
+		"| ✅ posted | `.gitleaksignore:3` | [view](https://github.com/o/r/pull/1#discussion_r1) | &lt;!-- gitleaks-diff-comment: .gitleaksignore:3:RIGHT --&gt; 🔒 **Gitleaks Exclusion Added** |",
+		"| ❌ error | — | — | 422 Validation Failed \\| line must be part of the diff |",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("StepSummary() missing %q:\n%s", want, summary)
+		}
+	}
+
+	if summary := StepSummary(&ActionOutput{}); !strings.Contains(summary, "No comments were posted.") {
+		t.Errorf("StepSummary() without results = %q", summary)
+	}
+}
@@ -0,0 +1,194 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
+)
+
+// Supported delivery modes
+const (
+	// DeliveryComments posts each comment as its own review comment
+	DeliveryComments = "comments"
+
+	// DeliveryReview submits all new comments as a single pull request review
+	DeliveryReview = "review"
+)
+
+// Limits for a single review. GitHub does not document a maximum number of
+// comments per review, but very large reviews are rejected or time out, so
+// reviews are split conservatively.
+const (
+	maxReviewComments = 50
+	maxReviewBytes    = 256 * 1024
+)
+
+// PostReview submits new comments as one pull request review, split into
+// several reviews if it would exceed API limits
+// Existing comments are still matched by marker: in override mode they are
+// updated in place, in append mode identical ones are skipped, so later
+// pushes behave as with PostComments. If a review is rejected, for example
+// because one comment targets a line outside the diff, its comments are
+// posted individually instead.
+func PostReview(ctx context.Context, client Client, comments []*comment.GeneratedComment, commentMode string, debug bool) (*ActionOutput, error) {
+	existingComments, err := client.ListReviewComments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list existing comments: %w", err)
+	}
+
+	remaining, err := client.CheckRateLimit(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to check rate limit: %v", err)
+	} else if debug {
+		log.Printf("GitHub API rate limit remaining: %d calls", remaining)
+	}
+
+	var results []CommentResult
+	var updates, drafts []*comment.GeneratedComment
+	for _, comm := range comments {
+		switch {
+		case commentMode == "override" && findExistingComment(comm, existingComments) != nil:
+			updates = append(updates, comm)
+		case commentMode == "append" && isDuplicate(comm, existingComments):
+			results = append(results, CommentResult{
+				Status:      "skipped_duplicate",
+				Path:        comm.Path,
+				Line:        comm.Line,
+				BodyPreview: comm.GetBodyPreview(),
+			})
+		default:
+			drafts = append(drafts, comm)
+		}
+	}
+
+	if debug {
+		log.Printf("Review delivery: %d new, %d to update, %d duplicates", len(drafts), len(updates), len(results))
+	}
+
+	// Comments already on the PR are edited individually; a review can only add comments
+	if len(updates) > 0 {
+		results = append(results, postCommentsConcurrently(ctx, client, updates, existingComments, commentMode, debug)...)
+	}
+
+	chunks := chunkComments(drafts, maxReviewComments, maxReviewBytes)
+	for i, chunk := range chunks {
+		req := &CreateReviewRequest{
+			CommitID: chunk[0].CommitID,
+			Body:     reviewSummary(chunk, i+1, len(chunks)),
+			Event:    "COMMENT",
+		}
+		for _, comm := range chunk {
+			req.Comments = append(req.Comments, ReviewCommentDraft{
+				Path: comm.Path,
+				Line: comm.Line,
+				Side: comm.Side,
+				Body: comm.Body,
+			})
+		}
+
+		resp, err := createReviewWithRetry(ctx, client, req, debug)
+		if err != nil {
+			log.Printf("Warning: review %d/%d was rejected, posting its %d comments individually: %v", i+1, len(chunks), len(chunk), err)
+			results = append(results, postCommentsConcurrently(ctx, client, chunk, nil, commentMode, debug)...)
+			continue
+		}
+
+		if debug {
+			log.Printf("Submitted review %d/%d with %d comments: %s", i+1, len(chunks), len(chunk), resp.HTMLURL)
+		}
+		for _, comm := range chunk {
+			results = append(results, CommentResult{
+				Status:      "posted",
+				CommentURL:  resp.HTMLURL,
+				Path:        comm.Path,
+				Line:        comm.Line,
+				BodyPreview: comm.GetBodyPreview(),
+			})
+		}
+	}
+
+	output := newActionOutput(results)
+	if debug {
+		log.Printf("Summary: Posted=%d, Skipped=%d, Errors=%d", output.Posted, output.SkippedDuplicates, output.Errors)
+	}
+	return output, nil
+}
+
+// chunkComments splits comments into groups of at most maxCount comments and
+// maxBytes of comment bodies. A single oversized comment gets a group of its own.
+func chunkComments(comments []*comment.GeneratedComment, maxCount, maxBytes int) [][]*comment.GeneratedComment {
+	var chunks [][]*comment.GeneratedComment
+	var current []*comment.GeneratedComment
+	size := 0
+
+	for _, comm := range comments {
+		if len(current) > 0 && (len(current) >= maxCount || size+len(comm.Body) > maxBytes) {
+			chunks = append(chunks, current)
+			current, size = nil, 0
+		}
+		current = append(current, comm)
+		size += len(comm.Body)
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// reviewSummary builds the review body listing how many comments each file received
+func reviewSummary(comments []*comment.GeneratedComment, part, parts int) string {
+	perFile := make(map[string]int)
+	for _, comm := range comments {
+		perFile[comm.Path]++
+	}
+	files := make([]string, 0, len(perFile))
+	for file := range perFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var b strings.Builder
+	b.WriteString("🔍 **Gitleaks exclusion review**\n\n")
+	fmt.Fprintf(&b, "%d %s on changes that affect secret scanning:\n\n", len(comments), pluralize(len(comments), "comment"))
+	for _, file := range files {
+		fmt.Fprintf(&b, "- `%s`: %d %s\n", file, perFile[file], pluralize(perFile[file], "comment"))
+	}
+	if parts > 1 {
+		fmt.Fprintf(&b, "\n_Part %d of %d_\n", part, parts)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// createReviewWithRetry submits a review, retrying on rate limits with exponential backoff
+func createReviewWithRetry(ctx context.Context, client Client, req *CreateReviewRequest, debug bool) (*PostCommentResponse, error) {
+	maxRetries := 3
+	delays := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			if debug {
+				log.Printf("Review retry attempt %d after %v", attempt, delays[attempt-1])
+			}
+			time.Sleep(delays[attempt-1])
+		}
+
+		resp, err := client.CreateReview(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+
+		// Only rate limits are worth retrying; validation errors will not go away
+		if !strings.Contains(err.Error(), "rate limit") && !strings.Contains(err.Error(), "abuse") {
+			return nil, err
+		}
+		log.Printf("Rate limit hit submitting review, retrying...")
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,191 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
+)
+
+// reviewComment builds a generated comment carrying the marker for path:line
+func reviewComment(path string, line int, body string) *comment.GeneratedComment {
+	return &comment.GeneratedComment{
+		Body:     fmt.Sprintf("<!-- gitleaks-diff-comment: %s:%d:RIGHT -->\n%s", path, line, body),
+		Path:     path,
+		Line:     line,
+		Side:     "RIGHT",
+		CommitID: "abc123",
+	}
+}
+
+func TestPostReview(t *testing.T) {
+	comments := []*comment.GeneratedComment{
+		reviewComment(".gitleaksignore", 1, "new entry"),
+		reviewComment(".gitleaksignore", 2, "changed entry"),
+		reviewComment("svc/.gitleaksignore", 4, "another entry"),
+	}
+
+	var mu sync.Mutex
+	var reviews []*CreateReviewRequest
+	var updated []int64
+	mockClient := &MockClient{
+		ListReviewCommentsFunc: func(ctx context.Context) ([]*ExistingComment, error) {
+			return []*ExistingComment{
+				{ID: 77, Body: "<!-- gitleaks-diff-comment: .gitleaksignore:2:RIGHT -->\nold text"},
+			}, nil
+		},
+		CreateReviewFunc: func(ctx context.Context, req *CreateReviewRequest) (*PostCommentResponse, error) {
+			reviews = append(reviews, req)
+			return &PostCommentResponse{ID: 456, HTMLURL: "https://github.com/test#pullrequestreview-456"}, nil
+		},
+		UpdateReviewCommentFunc: func(ctx context.Context, req *UpdateCommentRequest) (*PostCommentResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			updated = append(updated, req.CommentID)
+			return &PostCommentResponse{ID: req.CommentID}, nil
+		},
+		CreateReviewCommentFunc: func(ctx context.Context, req *PostCommentRequest) (*PostCommentResponse, error) {
+			t.Error("CreateReviewComment should not be called when the review succeeds")
+			return nil, errors.New("should not be called")
+		},
+	}
+
+	output, err := PostReview(context.Background(), mockClient, comments, "override", false)
+	if err != nil {
+		t.Fatalf("PostReview() unexpected error: %v", err)
+	}
+
+	if output.Posted != 3 || output.Errors != 0 {
+		t.Errorf("Posted = %d, Errors = %d, want 3 and 0", output.Posted, output.Errors)
+	}
+	if len(updated) != 1 || updated[0] != 77 {
+		t.Errorf("updated comments = %v, want the existing comment 77", updated)
+	}
+	if len(reviews) != 1 {
+		t.Fatalf("submitted %d reviews, want 1", len(reviews))
+	}
+
+	review := reviews[0]
+	if review.Event != "COMMENT" || review.CommitID != "abc123" {
+		t.Errorf("review = %+v, want a COMMENT review on abc123", review)
+	}
+	if len(review.Comments) != 2 {
+		t.Fatalf("review has %d comments, want the 2 new ones", len(review.Comments))
+	}
+	if review.Comments[1].Path != "svc/.gitleaksignore" || review.Comments[1].Line != 4 {
+		t.Errorf("second draft = %+v, want svc/.gitleaksignore line 4", review.Comments[1])
+	}
+	for _, want := range []string{"2 comments", "- `.gitleaksignore`: 1 comment", "- `svc/.gitleaksignore`: 1 comment"} {
+		if !strings.Contains(review.Body, want) {
+			t.Errorf("review body missing %q:\n%s", want, review.Body)
+		}
+	}
+	if strings.Contains(review.Body, "Part") {
+		t.Errorf("a single review should not be numbered:\n%s", review.Body)
+	}
+}
+
+func TestPostReview_AppendSkipsDuplicates(t *testing.T) {
+	comments := []*comment.GeneratedComment{reviewComment(".gitleaksignore", 1, "same text")}
+
+	mockClient := &MockClient{
+		ListReviewCommentsFunc: func(ctx context.Context) ([]*ExistingComment, error) {
+			return []*ExistingComment{{ID: 1, Body: comments[0].Body}}, nil
+		},
+		CreateReviewFunc: func(ctx context.Context, req *CreateReviewRequest) (*PostCommentResponse, error) {
+			t.Error("CreateReview should not be called when every comment is a duplicate")
+			return nil, errors.New("should not be called")
+		},
+	}
+
+	output, err := PostReview(context.Background(), mockClient, comments, "append", false)
+	if err != nil {
+		t.Fatalf("PostReview() unexpected error: %v", err)
+	}
+	if output.SkippedDuplicates != 1 || output.Posted != 0 {
+		t.Errorf("SkippedDuplicates = %d, Posted = %d, want 1 and 0", output.SkippedDuplicates, output.Posted)
+	}
+}
+
+func TestPostReview_FallsBackToComments(t *testing.T) {
+	comments := []*comment.GeneratedComment{
+		reviewComment(".gitleaksignore", 1, "first"),
+		reviewComment(".gitleaksignore", 9, "outside the diff"),
+	}
+
+	var mu sync.Mutex
+	individual := 0
+	mockClient := &MockClient{
+		CreateReviewFunc: func(ctx context.Context, req *CreateReviewRequest) (*PostCommentResponse, error) {
+			return nil, errors.New("422 Unprocessable Entity: pull_request_review_thread.line must be part of the diff")
+		},
+		CreateReviewCommentFunc: func(ctx context.Context, req *PostCommentRequest) (*PostCommentResponse, error) {
+			if req.Line == 9 {
+				return nil, errors.New("422 Unprocessable Entity")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			individual++
+			return &PostCommentResponse{ID: 123, HTMLURL: "https://github.com/test"}, nil
+		},
+	}
+
+	output, err := PostReview(context.Background(), mockClient, comments, "override", false)
+	if err != nil {
+		t.Fatalf("PostReview() unexpected error: %v", err)
+	}
+	if individual != 1 {
+		t.Errorf("posted %d comments individually, want 1", individual)
+	}
+	if output.Posted != 1 || output.Errors != 1 {
+		t.Errorf("Posted = %d, Errors = %d, want 1 and 1", output.Posted, output.Errors)
+	}
+}
+
+func TestChunkComments(t *testing.T) {
+	var comments []*comment.GeneratedComment
+	for i := 1; i <= 7; i++ {
+		comments = append(comments, &comment.GeneratedComment{Path: "a", Line: i, Body: strings.Repeat("x", 10)})
+	}
+
+	tests := []struct {
+		name     string
+		maxCount int
+		maxBytes int
+		want     []int
+	}{
+		{"fits in one", 10, 1000, []int{7}},
+		{"split by count", 3, 1000, []int{3, 3, 1}},
+		{"split by size", 10, 25, []int{2, 2, 2, 1}},
+		{"oversized comment alone", 10, 5, []int{1, 1, 1, 1, 1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := chunkComments(comments, tt.maxCount, tt.maxBytes)
+			var sizes []int
+			for _, chunk := range chunks {
+				sizes = append(sizes, len(chunk))
+			}
+			if fmt.Sprint(sizes) != fmt.Sprint(tt.want) {
+				t.Errorf("chunk sizes = %v, want %v", sizes, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewSummary_Parts(t *testing.T) {
+	comments := []*comment.GeneratedComment{{Path: "b"}, {Path: "a"}, {Path: "b"}}
+
+	summary := reviewSummary(comments, 2, 3)
+	if !strings.Contains(summary, "_Part 2 of 3_") {
+		t.Errorf("summary should be numbered:\n%s", summary)
+	}
+	if strings.Index(summary, "`a`") > strings.Index(summary, "`b`: 2 comments") {
+		t.Errorf("files should be sorted:\n%s", summary)
+	}
+}
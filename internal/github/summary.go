@@ -0,0 +1,88 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
+)
+
+// PostSummary keeps the pull request summary comment up to date
+// The comment is found by comment.SummaryMarker and the bot's login and edited
+// in place, so each PR has at most one and comments others copied the marker
+// into are left alone. Only bot accounts are matched: with a personal access
+// token a new comment is posted on every run. If there is no summary yet it is only created when
+// create is true; a PR that never changed an exclusion gets no comment.
+// Returns nil if nothing was done.
+func PostSummary(ctx context.Context, client Client, body string, create, debug bool) (*CommentResult, error) {
+	comments, err := client.ListPRComments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list existing comments: %w", err)
+	}
+
+	preview := (&comment.GeneratedComment{Body: body}).GetBodyPreview()
+	botLogin := client.BotLogin()
+	if !IsBotAccount(botLogin) {
+		log.Printf("Warning: %s is not a bot account, so its earlier summary comment cannot be told apart from its own comments; "+
+			"use the workflow token or a GitHub App to keep a single summary", botLogin)
+	}
+
+	for _, existing := range comments {
+		if !isOwnMarked(existing.GetBody(), existing.GetUser().GetLogin(), comment.SummaryMarker, botLogin) {
+			continue
+		}
+
+		if normalizeWhitespace(existing.GetBody()) == normalizeWhitespace(body) {
+			if debug {
+				log.Printf("Summary comment is up to date: %s", existing.GetHTMLURL())
+			}
+			return &CommentResult{
+				Status:      "skipped_duplicate",
+				CommentID:   existing.GetID(),
+				CommentURL:  existing.GetHTMLURL(),
+				BodyPreview: preview,
+			}, nil
+		}
+
+		var resp *PostCommentResponse
+		_, err := RetryWithBackoff(func() error {
+			resp, err = client.UpdateIssueComment(ctx, existing.GetID(), body)
+			return err
+		}, 3)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update summary comment: %w", err)
+		}
+		if debug {
+			log.Printf("Updated summary comment: %s", resp.HTMLURL)
+		}
+		return &CommentResult{
+			Status:      "updated",
+			CommentID:   resp.ID,
+			CommentURL:  resp.HTMLURL,
+			BodyPreview: preview,
+		}, nil
+	}
+
+	if !create {
+		return nil, nil
+	}
+
+	var resp *PostCommentResponse
+	_, err = RetryWithBackoff(func() error {
+		resp, err = client.CreateIssueComment(ctx, body)
+		return err
+	}, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post summary comment: %w", err)
+	}
+	if debug {
+		log.Printf("Posted summary comment: %s", resp.HTMLURL)
+	}
+	return &CommentResult{
+		Status:      "posted",
+		CommentID:   resp.ID,
+		CommentURL:  resp.HTMLURL,
+		BodyPreview: preview,
+	}, nil
+}
@@ -0,0 +1,138 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/comment"
+	"github.com/google/go-github/v57/github"
+)
+
+func TestPostSummary(t *testing.T) {
+	body := comment.SummaryMarker + "\n## Summary\n\n| a | b |"
+	bot := &github.User{Login: github.String(DefaultBotLogin)}
+
+	tests := []struct {
+		name       string
+		login      string
+		existing   []*github.IssueComment
+		create     bool
+		wantStatus string
+		wantUpdate int64
+		wantCreate bool
+	}{
+		{
+			name:       "creates the first summary",
+			existing:   []*github.IssueComment{{ID: github.Int64(1), Body: github.String("LGTM")}},
+			create:     true,
+			wantStatus: "posted",
+			wantCreate: true,
+		},
+		{
+			name: "updates the existing summary in place",
+			existing: []*github.IssueComment{
+				{ID: github.Int64(1), Body: github.String("LGTM")},
+				{ID: github.Int64(2), Body: github.String(comment.SummaryMarker + "\nold table"), User: bot},
+			},
+			create:     true,
+			wantStatus: "updated",
+			wantUpdate: 2,
+		},
+		{
+			name:       "leaves an identical summary alone",
+			existing:   []*github.IssueComment{{ID: github.Int64(3), Body: github.String(body + "\n"), User: bot}},
+			create:     true,
+			wantStatus: "skipped_duplicate",
+		},
+		{
+			name:       "updates even when nothing would be created",
+			existing:   []*github.IssueComment{{ID: github.Int64(4), Body: github.String(comment.SummaryMarker + "\nold table"), User: bot}},
+			create:     false,
+			wantStatus: "updated",
+			wantUpdate: 4,
+		},
+		{
+			name:   "does not create when not asked to",
+			create: false,
+		},
+		{
+			name: "ignores a comment with a copied marker",
+			existing: []*github.IssueComment{{
+				ID:   github.Int64(5),
+				Body: github.String(comment.SummaryMarker + "\nfake table"),
+				User: &github.User{Login: github.String("mallory")},
+			}},
+			create:     true,
+			wantStatus: "posted",
+			wantCreate: true,
+		},
+		{
+			name:  "does not reuse a marked comment by a personal access token's user",
+			login: "octocat",
+			existing: []*github.IssueComment{{
+				ID:   github.Int64(6),
+				Body: github.String("> " + comment.SummaryMarker + "\nquoted"),
+				User: &github.User{Login: github.String("octocat")},
+			}},
+			create:     true,
+			wantStatus: "posted",
+			wantCreate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated int64
+			created := false
+			mockClient := &MockClient{
+				Login: tt.login,
+				ListPRCommentsFunc: func(ctx context.Context) ([]*github.IssueComment, error) {
+					return tt.existing, nil
+				},
+				UpdateIssueCommentFunc: func(ctx context.Context, commentID int64, got string) (*PostCommentResponse, error) {
+					if got != body {
+						t.Errorf("UpdateIssueComment() body = %q, want %q", got, body)
+					}
+					updated = commentID
+					return &PostCommentResponse{ID: commentID}, nil
+				},
+				CreateIssueCommentFunc: func(ctx context.Context, got string) (*PostCommentResponse, error) {
+					created = true
+					return &PostCommentResponse{ID: 10}, nil
+				},
+			}
+
+			result, err := PostSummary(context.Background(), mockClient, body, tt.create, false)
+			if err != nil {
+				t.Fatalf("PostSummary() unexpected error: %v", err)
+			}
+
+			status := ""
+			if result != nil {
+				status = result.Status
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+			if updated != tt.wantUpdate {
+				t.Errorf("updated comment %d, want %d", updated, tt.wantUpdate)
+			}
+			if created != tt.wantCreate {
+				t.Errorf("created = %v, want %v", created, tt.wantCreate)
+			}
+		})
+	}
+}
+
+func TestPostSummary_Error(t *testing.T) {
+	mockClient := &MockClient{
+		CreateIssueCommentFunc: func(ctx context.Context, body string) (*PostCommentResponse, error) {
+			return nil, errors.New("403 Resource not accessible by integration")
+		},
+	}
+
+	if _, err := PostSummary(context.Background(), mockClient, comment.SummaryMarker, true, false); err == nil {
+		t.Error("PostSummary() expected error")
+	}
+}
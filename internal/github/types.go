@@ -29,6 +29,24 @@ type ExistingComment struct {
 	Side     string `json:"side"`
 }
 
+// CreateReviewRequest represents a pull request review submitted with its line comments
+type CreateReviewRequest struct {
+	CommitID string `json:"commit_id"`
+	Body     string `json:"body"`
+
+	// Event is "COMMENT", "APPROVE" or "REQUEST_CHANGES"
+	Event    string               `json:"event"`
+	Comments []ReviewCommentDraft `json:"comments"`
+}
+
+// ReviewCommentDraft is a line comment submitted as part of a review
+type ReviewCommentDraft struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"` // "LEFT" or "RIGHT"
+	Body string `json:"body"`
+}
+
 // UpdateCommentRequest represents a request to update an existing comment
 type UpdateCommentRequest struct {
 	CommentID int64  `json:"comment_id"`
@@ -46,6 +64,10 @@ type CommentResult struct {
 	// Comment URL if successfully posted
 	CommentURL string `json:"comment_url,omitempty"`
 
+	// File and line the comment is on (empty for the summary comment)
+	Path string `json:"path,omitempty"`
+	Line int    `json:"line,omitempty"`
+
 	// Error message if status is "error"
 	Error string `json:"error,omitempty"`
 
@@ -59,4 +81,86 @@ type ActionOutput struct {
 	SkippedDuplicates int             `json:"skipped_duplicates"`
 	Errors            int             `json:"errors"`
 	Results           []CommentResult `json:"results"`
+
+	// CheckRunURL is the check run published with the results, if any
+	CheckRunURL string `json:"check_run_url,omitempty"`
+}
+
+// PullRequestFile represents a file changed in a pull request
+type PullRequestFile struct {
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename,omitempty"`
+	Status           string `json:"status"` // "added", "removed", "modified", "renamed", ...
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	Patch            string `json:"patch,omitempty"` // Omitted by GitHub for very large diffs
+	SHA              string `json:"sha"`
+}
+
+// PullRequestInfo describes a pull request
+type PullRequestInfo struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Author  string `json:"author"`
+	HTMLURL string `json:"html_url"`
+	BaseRef string `json:"base_ref"`
+	BaseSHA string `json:"base_sha"`
+	HeadRef string `json:"head_ref"`
+	HeadSHA string `json:"head_sha"`
+}
+
+// CheckRunRequest describes a completed check run and its annotations
+type CheckRunRequest struct {
+	Name    string `json:"name"`
+	HeadSHA string `json:"head_sha"`
+
+	// Conclusion: "success", "neutral" or "failure"
+	Conclusion string `json:"conclusion"`
+
+	// Title and Summary (markdown) are shown on the check run page
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+
+	Annotations []CheckAnnotation `json:"annotations,omitempty"`
+}
+
+// CheckAnnotation is a message attached to a line of a file at the head commit
+type CheckAnnotation struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+
+	// Level: "notice", "warning" or "failure"
+	Level   string `json:"annotation_level"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+}
+
+// CheckRunResponse represents a created or updated check run
+type CheckRunResponse struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+}
+
+// IssueRequest represents the fields of an issue to create or edit
+type IssueRequest struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+
+	// State is "open" or "closed" (edits only)
+	State string `json:"state,omitempty"`
+}
+
+// IssueResponse represents a created or edited issue
+type IssueResponse struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// IssueResult describes what happened to the audit tracking issue
+type IssueResult struct {
+	// Status is "created", "updated", "unchanged" or "closed"
+	Status string `json:"status"`
+	Number int    `json:"number"`
+	URL    string `json:"url"`
 }
@@ -0,0 +1,211 @@
+// Package redact masks secrets in source lines before they are quoted in
+// pull request comments
+package redact
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/diff"
+)
+
+// Placeholder replaces every masked span
+const Placeholder = "[REDACTED]"
+
+const (
+	// minTokenLength is the shortest token the entropy heuristic considers
+	minTokenLength = 16
+
+	// minEntropy is the Shannon entropy (bits per character) above which a token is masked
+	minEntropy = 3.5
+)
+
+var (
+	// tokenRegex matches runs of characters that commonly make up keys and tokens
+	tokenRegex = regexp.MustCompile(`[A-Za-z0-9+/=_.\-]{16,}`)
+
+	// assignmentRegex matches values assigned to secret-looking keys,
+	// e.g. password = "hunter2" or API_KEY: abc123
+	assignmentRegex = regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credential)[\w.-]*["']?\s*[:=]\s*["']?([^\s"',;]{4,})`)
+)
+
+// builtinRules are a subset of the gitleaks default rules, used for rule IDs
+// the repository's config does not redefine
+var builtinRules = []diff.RuleDefinition{
+	{ID: "aws-access-token", Regex: `\b((?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16})\b`, SecretGroup: 1},
+	{ID: "github-pat", Regex: `ghp_[0-9a-zA-Z]{36}`},
+	{ID: "github-fine-grained-pat", Regex: `github_pat_\w{82}`},
+	{ID: "github-oauth", Regex: `gho_[0-9a-zA-Z]{36}`},
+	{ID: "github-app-token", Regex: `(?:ghu|ghs)_[0-9a-zA-Z]{36}`},
+	{ID: "slack-bot-token", Regex: `xoxb-[0-9]{10,13}-[0-9]{10,13}[a-zA-Z0-9-]*`},
+	{ID: "stripe-access-token", Regex: `\b((?:sk|rk)_(?:test|live|prod)_[a-zA-Z0-9]{10,99})\b`, SecretGroup: 1},
+	{ID: "jwt", Regex: `\b(ey[a-zA-Z0-9]{17,}\.ey[a-zA-Z0-9/\\_-]{17,}\.(?:[a-zA-Z0-9/\\_-]{10,}={0,2})?)`, SecretGroup: 1},
+}
+
+// rule is a compiled detection rule
+type rule struct {
+	id          string
+	regex       *regexp.Regexp
+	secretGroup int
+}
+
+// Redactor masks secrets using gitleaks rule regexes and an entropy heuristic
+// It implements diff.LineRedactor.
+type Redactor struct {
+	rules map[string]*rule
+
+	// builtins are applied to every line regardless of the finding's rule
+	builtins []*rule
+}
+
+// New creates a Redactor from the rules declared in the repository's gitleaks
+// config, falling back to built-in rules for IDs the config does not declare
+// Rules without a regex or with a regex Go cannot compile are ignored.
+func New(rules []diff.RuleDefinition) *Redactor {
+	r := &Redactor{rules: make(map[string]*rule)}
+
+	for _, def := range builtinRules {
+		compiled := compile(def)
+		r.rules[def.ID] = compiled
+		r.builtins = append(r.builtins, compiled)
+	}
+	for _, def := range rules {
+		if compiled := compile(def); compiled != nil {
+			r.rules[def.ID] = compiled
+		}
+	}
+	return r
+}
+
+// compile compiles a rule definition, returning nil if it has no usable regex
+func compile(def diff.RuleDefinition) *rule {
+	if def.Regex == "" {
+		return nil
+	}
+	re, err := regexp.Compile(def.Regex)
+	if err != nil {
+		return nil
+	}
+	return &rule{id: def.ID, regex: re, secretGroup: def.SecretGroup}
+}
+
+// RedactLine masks secrets in line
+// The regex of ruleID is applied first when known, then the built-in rules,
+// then the entropy heuristic, so a secret missed by one pass is still caught
+// by the next.
+func (r *Redactor) RedactLine(line, ruleID string) string {
+	line, _ = r.redactRules(line, ruleID)
+	return redactHeuristically(line)
+}
+
+// RedactFinding masks the line a finding was reported on
+// The line is known to hold a secret, and the heuristics miss short or
+// low-entropy ones, so the whole line is replaced with Placeholder unless the
+// regex of ruleID matched it.
+func (r *Redactor) RedactFinding(line, ruleID string) string {
+	line, matched := r.redactRules(line, ruleID)
+	if !matched {
+		return Placeholder
+	}
+	return redactHeuristically(line)
+}
+
+// redactRules applies the regex of ruleID, then the built-in rules, and
+// reports whether the regex of ruleID masked anything
+func (r *Redactor) redactRules(line, ruleID string) (string, bool) {
+	matched := false
+	if known, ok := r.rules[ruleID]; ok {
+		redacted := known.redact(line)
+		matched = redacted != line
+		line = redacted
+	}
+	for _, builtin := range r.builtins {
+		line = builtin.redact(line)
+	}
+	return line, matched
+}
+
+// redact masks the secret group of every match of the rule
+// The whole match is masked if the group does not exist or did not participate.
+func (r *rule) redact(line string) string {
+	return maskMatches(line, r.regex, r.secretGroup)
+}
+
+// maskMatches replaces group of every match of re in line with Placeholder
+func maskMatches(line string, re *regexp.Regexp, group int) string {
+	matches := re.FindAllStringSubmatchIndex(line, -1)
+	if matches == nil {
+		return line
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if group > 0 && 2*group+1 < len(m) && m[2*group] >= 0 {
+			start, end = m[2*group], m[2*group+1]
+		}
+		if start < last || start == end {
+			continue
+		}
+		b.WriteString(line[last:start])
+		b.WriteString(Placeholder)
+		last = end
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// redactHeuristically masks values assigned to secret-looking keys and
+// tokens that look random
+func redactHeuristically(line string) string {
+	line = maskMatches(line, assignmentRegex, 1)
+
+	return tokenRegex.ReplaceAllStringFunc(line, func(token string) string {
+		if looksRandom(token) {
+			return Placeholder
+		}
+		return token
+	})
+}
+
+// looksRandom reports whether token mixes letters and digits and has high entropy
+func looksRandom(token string) bool {
+	if len(token) < minTokenLength {
+		return false
+	}
+
+	var letters, digits bool
+	for _, c := range token {
+		switch {
+		case unicode.IsLetter(c):
+			letters = true
+		case unicode.IsDigit(c):
+			digits = true
+		}
+	}
+	return letters && digits && ShannonEntropy(token) >= minEntropy
+}
+
+// ShannonEntropy returns the entropy of s in bits per character
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+	for _, c := range s {
+		counts[c]++
+		total++
+	}
+
+	var entropy float64
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"sync"
+)
+
+// ErrQueueFull is returned when a delivery arrives while the queue is at capacity
+var ErrQueueFull = errors.New("queue is full")
+
+// ErrShuttingDown is returned when a delivery arrives after shutdown started
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// queue holds the jobs waiting for a worker. Jobs for the same pull request
+// run one at a time, in the order they arrived: only the first is handed to
+// the workers, the rest wait behind it until it finishes. A diff job that is
+// still waiting is replaced by a newer one for the same pull request, since
+// only the latest push needs comments.
+type queue struct {
+	mu sync.Mutex
+
+	// ready holds jobs any worker may start
+	ready chan *Job
+
+	// waiting holds, per pull request with a ready or running job, the jobs
+	// queued behind it
+	waiting map[string][]*Job
+
+	// pending counts jobs that have not started, ready or waiting
+	pending  int
+	capacity int
+	closed   bool
+
+	// unfinished counts accepted jobs until they finish or are superseded
+	unfinished sync.WaitGroup
+}
+
+func newQueue(capacity int) *queue {
+	return &queue{
+		ready:    make(chan *Job, capacity),
+		waiting:  make(map[string][]*Job),
+		capacity: capacity,
+	}
+}
+
+// push accepts a job, or returns ErrQueueFull or ErrShuttingDown
+func (q *queue) push(job *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrShuttingDown
+	}
+
+	key := job.Key()
+	behind, busy := q.waiting[key]
+	if busy && job.Command == "" {
+		for i, waiting := range behind {
+			if waiting.Command == "" {
+				log.Printf("Delivery %s supersedes queued delivery %s for %s", job.DeliveryID, waiting.DeliveryID, key)
+				behind[i] = job
+				return nil
+			}
+		}
+	}
+
+	if q.pending >= q.capacity {
+		return ErrQueueFull
+	}
+	q.pending++
+	q.unfinished.Add(1)
+
+	if busy {
+		q.waiting[key] = append(behind, job)
+		return nil
+	}
+	// ready never blocks: it can hold capacity jobs, and at most pending are in it
+	q.waiting[key] = nil
+	q.ready <- job
+	return nil
+}
+
+// started records that a worker took job from ready
+func (q *queue) started() {
+	q.mu.Lock()
+	q.pending--
+	q.mu.Unlock()
+}
+
+// finished releases the next job of job's pull request to the workers
+func (q *queue) finished(job *Job) {
+	q.mu.Lock()
+	key := job.Key()
+	if behind := q.waiting[key]; len(behind) > 0 {
+		q.waiting[key] = behind[1:]
+		q.ready <- behind[0]
+	} else {
+		delete(q.waiting, key)
+	}
+	q.mu.Unlock()
+
+	q.unfinished.Done()
+}
+
+// close stops accepting jobs; jobs already accepted are still handed out
+func (q *queue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+}
+
+// size returns the number of jobs that have not started
+func (q *queue) size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending
+}
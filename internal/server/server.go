@@ -0,0 +1,351 @@
+// Package server runs the bot as a long-lived service that receives GitHub
+// webhooks, for repositories that do not use GitHub Actions.
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/commands"
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/config"
+)
+
+// maxPayloadSize is the largest webhook payload GitHub delivers (25 MB)
+const maxPayloadSize = 25 << 20
+
+// pullRequestActions are the pull_request actions that change the diff
+var pullRequestActions = map[string]bool{
+	"opened":      true,
+	"synchronize": true,
+	"reopened":    true,
+}
+
+// Job is one webhook delivery to act on
+type Job struct {
+	// DeliveryID identifies the delivery (X-GitHub-Delivery)
+	DeliveryID string
+
+	// EventName is the webhook event (X-GitHub-Event), e.g. "pull_request"
+	EventName string
+
+	// Action is the event's action, e.g. "synchronize"
+	Action string
+
+	// Repository is the repository as owner/repo
+	Repository string
+
+	// InstallationID is the GitHub App installation the delivery was sent for
+	// (0 for repository webhooks)
+	InstallationID int64
+
+	// Event holds the pull request, commits and commenter from the payload
+	Event *config.Event
+
+	// Command is the command to run, e.g. "clear", or empty to comment on
+	// the pull request's changes
+	Command string
+}
+
+// Key identifies the pull request the job acts on; jobs with the same key
+// are never processed at the same time
+func (j *Job) Key() string {
+	return fmt.Sprintf("%s#%d", j.Repository, j.Event.PRNumber)
+}
+
+// Handler processes jobs
+type Handler interface {
+	Handle(ctx context.Context, job *Job) error
+}
+
+// HandlerFunc adapts a function to a Handler
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// Handle calls f(ctx, job)
+func (f HandlerFunc) Handle(ctx context.Context, job *Job) error {
+	return f(ctx, job)
+}
+
+// Options configures the worker pool of a Server
+type Options struct {
+	// Workers is the number of jobs processed at the same time
+	Workers int
+
+	// QueueSize is the number of jobs that may wait for a worker
+	QueueSize int
+
+	// JobTimeout bounds each job (0 = no limit)
+	JobTimeout time.Duration
+}
+
+// Server is an http.Handler that verifies webhook deliveries and queues
+// them for a pool of workers, which pass them to a Handler
+type Server struct {
+	secret     []byte
+	handler    Handler
+	jobTimeout time.Duration
+	queue      *queue
+
+	// ctx is the parent of every job's context; cancel aborts running jobs
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	stop    chan struct{}
+	workers sync.WaitGroup
+}
+
+// New creates a Server and starts its workers. Deliveries must be signed
+// with secret.
+func New(secret []byte, handler Handler, opts Options) *Server {
+	workers := max(opts.Workers, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		secret:     secret,
+		handler:    handler,
+		jobTimeout: opts.JobTimeout,
+		queue:      newQueue(max(opts.QueueSize, 1)),
+		ctx:        ctx,
+		cancel:     cancel,
+		stop:       make(chan struct{}),
+	}
+
+	s.workers.Add(workers)
+	for range workers {
+		go s.work()
+	}
+	return s
+}
+
+// ServeHTTP receives a webhook delivery. Deliveries are acknowledged once
+// queued (202); ignored events get 200, so GitHub does not report them as
+// failed. A full queue or a server shutting down answers 503, and the
+// delivery can be redelivered later.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "failed to read payload", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if !VerifySignature(s.secret, payload, r.Header.Get("X-Hub-Signature-256")) {
+		log.Printf("Rejected delivery %s: invalid signature", r.Header.Get("X-GitHub-Delivery"))
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	eventName := r.Header.Get("X-GitHub-Event")
+	deliveryID := r.Header.Get("X-GitHub-Delivery")
+	if eventName == "ping" {
+		fmt.Fprintln(w, "pong")
+		return
+	}
+
+	job, reason, err := ParseDelivery(eventName, deliveryID, payload)
+	if err != nil {
+		log.Printf("Rejected delivery %s: %v", deliveryID, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if job == nil {
+		fmt.Fprintf(w, "ignored: %s\n", reason)
+		return
+	}
+
+	switch err := s.queue.push(job); {
+	case errors.Is(err, ErrQueueFull):
+		log.Printf("Rejected delivery %s for %s: %v", deliveryID, job.Key(), err)
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	log.Printf("Queued delivery %s: %s.%s for %s", deliveryID, eventName, job.Action, job.Key())
+	w.WriteHeader(http.StatusAccepted)
+	fmt.Fprintln(w, "queued")
+}
+
+// Shutdown stops accepting deliveries and waits for the queued and running
+// jobs to finish. If ctx ends first, running jobs are cancelled, jobs that
+// have not started are dropped, and ctx's error is returned.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.queue.close()
+
+	drained := make(chan struct{})
+	go func() {
+		s.queue.unfinished.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		if dropped := s.queue.size(); dropped > 0 {
+			log.Printf("Shutdown timed out, dropping %d queued deliveries", dropped)
+		}
+		s.cancel()
+	}
+
+	close(s.stop)
+	s.workers.Wait()
+	s.cancel()
+	return err
+}
+
+// work processes ready jobs until the server stops
+func (s *Server) work() {
+	defer s.workers.Done()
+	for {
+		// Stopping takes priority over jobs still queued after a timeout,
+		// which cancels the jobs' context before the workers are stopped
+		if s.ctx.Err() != nil {
+			return
+		}
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case job := <-s.queue.ready:
+			s.queue.started()
+			s.run(job)
+			s.queue.finished(job)
+		}
+	}
+}
+
+// run passes job to the handler, logging the outcome; a panic fails the job
+// instead of the server
+func (s *Server) run(job *Job) {
+	ctx := s.ctx
+	if s.jobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.jobTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Delivery %s for %s panicked: %v\n%s", job.DeliveryID, job.Key(), r, debug.Stack())
+		}
+	}()
+
+	if err := s.handler.Handle(ctx, job); err != nil {
+		log.Printf("Delivery %s for %s failed after %s: %v", job.DeliveryID, job.Key(), time.Since(start).Round(time.Millisecond), err)
+		return
+	}
+	log.Printf("Delivery %s for %s done in %s", job.DeliveryID, job.Key(), time.Since(start).Round(time.Millisecond))
+}
+
+// VerifySignature returns true if header is the X-Hub-Signature-256 of
+// payload: "sha256=" followed by the hex HMAC-SHA256 of payload keyed with secret
+func VerifySignature(secret, payload []byte, header string) bool {
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok || len(secret) == 0 {
+		return false
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// deliveryPayload holds the payload fields config.ParseEvent does not read
+type deliveryPayload struct {
+	Action     string `json:"action"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Installation struct {
+		ID int64 `json:"id"`
+	} `json:"installation"`
+	Comment struct {
+		Body string `json:"body"`
+	} `json:"comment"`
+}
+
+// ParseDelivery turns a webhook delivery into a Job. pull_request deliveries
+// that change the diff (opened, synchronize, reopened) comment on the pull
+// request; new comments on a pull request run the command they contain
+// (commands.DetectCommand). Other deliveries return a nil Job and the reason
+// they are ignored.
+func ParseDelivery(eventName, deliveryID string, payload []byte) (*Job, string, error) {
+	if eventName != "pull_request" && eventName != "issue_comment" {
+		return nil, fmt.Sprintf("%s events are not handled", eventName), nil
+	}
+
+	var p deliveryPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s payload: %w", eventName, err)
+	}
+	event, err := config.ParseEvent(eventName, payload)
+	if err != nil {
+		return nil, "", err
+	}
+	if p.Repository.FullName == "" {
+		return nil, "", fmt.Errorf("%s payload has no repository", eventName)
+	}
+
+	job := &Job{
+		DeliveryID:     deliveryID,
+		EventName:      eventName,
+		Action:         p.Action,
+		Repository:     p.Repository.FullName,
+		InstallationID: p.Installation.ID,
+		Event:          event,
+	}
+
+	switch eventName {
+	case "pull_request":
+		if !pullRequestActions[p.Action] {
+			return nil, fmt.Sprintf("pull_request action %q does not change the diff", p.Action), nil
+		}
+		if event.PRNumber <= 0 || event.HeadSHA == "" {
+			return nil, "", errors.New("pull_request payload has no pull request")
+		}
+	case "issue_comment":
+		if p.Action != "created" {
+			return nil, fmt.Sprintf("issue_comment action %q is not handled", p.Action), nil
+		}
+		if event.PRNumber <= 0 {
+			return nil, "comment is not on a pull request", nil
+		}
+		command, ok := commands.DetectCommand(p.Comment.Body)
+		if !ok {
+			return nil, "comment has no command", nil
+		}
+		job.Command = command
+	}
+
+	return job, "", nil
+}
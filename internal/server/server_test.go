@@ -0,0 +1,417 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/config"
+)
+
+const testSecret = "It's a Secret to Everybody"
+
+// readPayload reads a recorded webhook payload
+func readPayload(t *testing.T, name string) []byte {
+	t.Helper()
+	payload, err := os.ReadFile(filepath.Join("..", "..", "tests", "fixtures", "webhooks", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+// sign returns the X-Hub-Signature-256 header GitHub sends for payload
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver posts a signed delivery to url and returns the response status
+func deliver(t *testing.T, url, event string, payload []byte) int {
+	t.Helper()
+	return deliverSigned(t, url, event, payload, sign(testSecret, payload))
+}
+
+func deliverSigned(t *testing.T, url, event string, payload []byte, signature string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+	if signature != "" {
+		req.Header.Set("X-Hub-Signature-256", signature)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// testJob returns a job for pull request number of octo-org/payments
+func testJob(id string, number int, command string) *Job {
+	return &Job{
+		DeliveryID: id,
+		Repository: "octo-org/payments",
+		Event:      &config.Event{PRNumber: number},
+		Command:    command,
+	}
+}
+
+// TestVerifySignature tests checking X-Hub-Signature-256
+func TestVerifySignature(t *testing.T) {
+	payload := []byte("Hello, World!")
+	// Example from GitHub's webhook documentation
+	valid := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		want   bool
+	}{
+		{name: "valid", secret: testSecret, header: valid, want: true},
+		{name: "wrong secret", secret: "other", header: valid, want: false},
+		{name: "missing", secret: testSecret, header: "", want: false},
+		{name: "sha1", secret: testSecret, header: "sha1=01dc10d0c83e72ed246219cdd91669667fe2ca59", want: false},
+		{name: "not hex", secret: testSecret, header: "sha256=zz", want: false},
+		{name: "empty secret", secret: "", header: sign("", payload), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature([]byte(tt.secret), payload, tt.header); got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseDelivery tests turning recorded deliveries into jobs
+func TestParseDelivery(t *testing.T) {
+	job, _, err := ParseDelivery("pull_request", "d1", readPayload(t, "pull_request_synchronize.json"))
+	if err != nil || job == nil {
+		t.Fatalf("ParseDelivery(pull_request) = %v, %v, want a job", job, err)
+	}
+	if job.Repository != "octo-org/payments" || job.InstallationID != 9876543 || job.Command != "" ||
+		job.Event.PRNumber != 42 || job.Event.HeadSHA != "a10867b14bb761a232cd80139fbd4c0d33264240" ||
+		job.Event.BaseSHA != "9049f1265b7d61be4a8904a9a27120d2064dab3b" {
+		t.Errorf("ParseDelivery(pull_request) = %+v, event %+v", job, job.Event)
+	}
+	if job.Key() != "octo-org/payments#42" {
+		t.Errorf("Key() = %q, want octo-org/payments#42", job.Key())
+	}
+
+	job, _, err = ParseDelivery("issue_comment", "d2", readPayload(t, "issue_comment_clear.json"))
+	if err != nil || job == nil {
+		t.Fatalf("ParseDelivery(issue_comment) = %v, %v, want a job", job, err)
+	}
+	if job.Command != "clear" || job.Event.PRNumber != 42 || job.Event.CommentID != 1234567890 || job.Event.Commenter != "hubot" {
+		t.Errorf("ParseDelivery(issue_comment) = %+v, event %+v", job, job.Event)
+	}
+
+	for _, tt := range []struct{ event, fixture, reason string }{
+		{"pull_request", "pull_request_closed.json", `pull_request action "closed" does not change the diff`},
+		{"issue_comment", "issue_comment_no_command.json", "comment has no command"},
+		{"push", "pull_request_synchronize.json", "push events are not handled"},
+	} {
+		job, reason, err := ParseDelivery(tt.event, "d3", readPayload(t, tt.fixture))
+		if job != nil || err != nil || reason != tt.reason {
+			t.Errorf("ParseDelivery(%s, %s) = %v, %q, %v, want ignored: %s", tt.event, tt.fixture, job, reason, err, tt.reason)
+		}
+	}
+
+	if _, _, err := ParseDelivery("pull_request", "d4", []byte(`{"action": "opened"`)); err == nil {
+		t.Error("ParseDelivery() with malformed payload expected error, got nil")
+	}
+}
+
+// TestServeHTTP tests the responses to signed, unsigned and ignored deliveries
+func TestServeHTTP(t *testing.T) {
+	jobs := make(chan *Job, 10)
+	srv := New([]byte(testSecret), HandlerFunc(func(ctx context.Context, job *Job) error {
+		jobs <- job
+		return nil
+	}), Options{Workers: 2, QueueSize: 10})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	defer srv.Shutdown(context.Background())
+
+	synchronize := readPayload(t, "pull_request_synchronize.json")
+	tests := []struct {
+		name      string
+		event     string
+		payload   []byte
+		signature string
+		want      int
+	}{
+		{"pull request", "pull_request", synchronize, sign(testSecret, synchronize), http.StatusAccepted},
+		{"clear command", "issue_comment", readPayload(t, "issue_comment_clear.json"), "", http.StatusAccepted},
+		{"closed pull request", "pull_request", readPayload(t, "pull_request_closed.json"), "", http.StatusOK},
+		{"comment without command", "issue_comment", readPayload(t, "issue_comment_no_command.json"), "", http.StatusOK},
+		{"ping", "ping", []byte(`{"zen": "Keep it logically awesome."}`), "", http.StatusOK},
+		{"unsigned", "pull_request", synchronize, "-", http.StatusUnauthorized},
+		{"wrong secret", "pull_request", synchronize, sign("guess", synchronize), http.StatusUnauthorized},
+		{"tampered", "pull_request", bytes.Replace(synchronize, []byte("42"), []byte("43"), 1), sign(testSecret, synchronize), http.StatusUnauthorized},
+		{"malformed", "pull_request", []byte("{"), "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := tt.signature
+			switch signature {
+			case "":
+				signature = sign(testSecret, tt.payload)
+			case "-":
+				signature = ""
+			}
+			if got := deliverSigned(t, ts.URL, tt.event, tt.payload, signature); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// Only the two accepted deliveries reach the handler
+	handled := map[string]bool{}
+	for range 2 {
+		select {
+		case job := <-jobs:
+			handled[job.EventName] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("accepted deliveries were not handled")
+		}
+	}
+	if !handled["pull_request"] || !handled["issue_comment"] {
+		t.Errorf("handled events = %v, want pull_request and issue_comment", handled)
+	}
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+// blockingHandler records the jobs it runs, and holds each one until released
+type blockingHandler struct {
+	mu      sync.Mutex
+	order   []string
+	running map[string]int
+	overlap bool
+
+	started chan *Job
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{
+		running: make(map[string]int),
+		started: make(chan *Job, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (h *blockingHandler) Handle(ctx context.Context, job *Job) error {
+	h.mu.Lock()
+	h.order = append(h.order, job.DeliveryID)
+	h.running[job.Key()]++
+	if h.running[job.Key()] > 1 {
+		h.overlap = true
+	}
+	h.mu.Unlock()
+
+	h.started <- job
+	select {
+	case <-h.release:
+	case <-ctx.Done():
+	}
+
+	h.mu.Lock()
+	h.running[job.Key()]--
+	h.mu.Unlock()
+	return ctx.Err()
+}
+
+// waitStarted returns the next job the handler starts
+func (h *blockingHandler) waitStarted(t *testing.T) *Job {
+	t.Helper()
+	select {
+	case job := <-h.started:
+		return job
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a job to start")
+		return nil
+	}
+}
+
+// TestServer_SerializesPullRequests tests that jobs for one pull request run
+// one at a time and in order, while other pull requests run alongside
+func TestServer_SerializesPullRequests(t *testing.T) {
+	h := newBlockingHandler()
+	srv := New([]byte(testSecret), h, Options{Workers: 4, QueueSize: 10})
+
+	for _, job := range []*Job{
+		testJob("a1", 1, ""),
+		testJob("a2", 1, "clear"),
+		testJob("b1", 2, ""),
+		testJob("a3", 1, ""),
+	} {
+		if err := srv.queue.push(job); err != nil {
+			t.Fatalf("push(%s) unexpected error: %v", job.DeliveryID, err)
+		}
+	}
+
+	// a1 and b1 run at the same time; a2 waits for a1 despite idle workers
+	first, second := h.waitStarted(t), h.waitStarted(t)
+	if ids := first.DeliveryID + second.DeliveryID; ids != "a1b1" && ids != "b1a1" {
+		t.Fatalf("started %s and %s, want a1 and b1", first.DeliveryID, second.DeliveryID)
+	}
+	select {
+	case job := <-h.started:
+		t.Fatalf("%s started while a1 was running", job.DeliveryID)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(h.release)
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() unexpected error: %v", err)
+	}
+
+	if h.overlap {
+		t.Error("jobs for the same pull request ran at the same time")
+	}
+	var pr1 []string
+	for _, id := range h.order {
+		if id != "b1" {
+			pr1 = append(pr1, id)
+		}
+	}
+	if len(pr1) != 3 || pr1[0] != "a1" || pr1[1] != "a2" || pr1[2] != "a3" {
+		t.Errorf("pull request 1 ran %v, want a1, a2, a3", pr1)
+	}
+}
+
+// TestServer_SupersedesQueuedDiff tests that a newer push replaces a queued one
+func TestServer_SupersedesQueuedDiff(t *testing.T) {
+	h := newBlockingHandler()
+	srv := New([]byte(testSecret), h, Options{Workers: 2, QueueSize: 10})
+
+	srv.queue.push(testJob("push1", 1, ""))
+	h.waitStarted(t)
+	srv.queue.push(testJob("push2", 1, ""))
+	srv.queue.push(testJob("clear", 1, "clear"))
+	srv.queue.push(testJob("push3", 1, ""))
+
+	close(h.release)
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() unexpected error: %v", err)
+	}
+
+	want := []string{"push1", "push3", "clear"}
+	if len(h.order) != len(want) || h.order[0] != want[0] || h.order[1] != want[1] || h.order[2] != want[2] {
+		t.Errorf("ran %v, want %v", h.order, want)
+	}
+}
+
+// TestServer_QueueFull tests that deliveries beyond the queue size are rejected
+func TestServer_QueueFull(t *testing.T) {
+	h := newBlockingHandler()
+	srv := New([]byte(testSecret), h, Options{Workers: 1, QueueSize: 1})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	// One job runs, one waits, the third does not fit
+	srv.queue.push(testJob("running", 1, ""))
+	h.waitStarted(t)
+	srv.queue.push(testJob("waiting", 2, ""))
+
+	if got := deliver(t, ts.URL, "pull_request", readPayload(t, "pull_request_synchronize.json")); got != http.StatusServiceUnavailable {
+		t.Errorf("status with a full queue = %d, want %d", got, http.StatusServiceUnavailable)
+	}
+
+	close(h.release)
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() unexpected error: %v", err)
+	}
+	if len(h.order) != 2 {
+		t.Errorf("ran %v, want the running and waiting jobs", h.order)
+	}
+}
+
+// TestServer_Shutdown tests that shutdown finishes queued jobs and refuses new ones
+func TestServer_Shutdown(t *testing.T) {
+	h := newBlockingHandler()
+	srv := New([]byte(testSecret), h, Options{Workers: 1, QueueSize: 10})
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	srv.queue.push(testJob("a1", 1, ""))
+	srv.queue.push(testJob("b1", 2, ""))
+	h.waitStarted(t)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.Shutdown(context.Background()) }()
+
+	// Deliveries are refused while the queue drains
+	deadline := time.Now().Add(5 * time.Second)
+	for deliver(t, ts.URL, "pull_request", readPayload(t, "pull_request_synchronize.json")) != http.StatusServiceUnavailable {
+		if time.Now().After(deadline) {
+			t.Fatal("deliveries were still accepted after Shutdown()")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown() returned %v before queued jobs finished", err)
+	default:
+	}
+
+	close(h.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Shutdown() unexpected error: %v", err)
+	}
+	if len(h.order) != 2 {
+		t.Errorf("ran %v, want both queued jobs", h.order)
+	}
+}
+
+// TestServer_ShutdownTimeout tests that running jobs are cancelled when shutdown times out
+func TestServer_ShutdownTimeout(t *testing.T) {
+	h := newBlockingHandler()
+	srv := New([]byte(testSecret), h, Options{Workers: 1, QueueSize: 10})
+
+	srv.queue.push(testJob("running", 1, ""))
+	srv.queue.push(testJob("queued", 2, ""))
+	h.waitStarted(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := srv.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if len(h.order) != 1 {
+		t.Errorf("ran %v, want the queued job dropped", h.order)
+	}
+}
@@ -0,0 +1,76 @@
+package commands_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/commands"
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/github"
+	gh "github.com/google/go-github/v57/github"
+)
+
+// clearClient implements the client methods the clear command uses
+// The embedded Client is nil, so any other call panics.
+type clearClient struct {
+	github.Client
+	botLogin string
+	comments []*gh.PullRequestComment
+	deleted  []int64
+}
+
+func (c *clearClient) CheckUserPermission(ctx context.Context, username string) (bool, string, error) {
+	return true, "admin", nil
+}
+
+func (c *clearClient) ListPRReviewComments(ctx context.Context) ([]*gh.PullRequestComment, error) {
+	return c.comments, nil
+}
+
+func (c *clearClient) DeleteReviewComment(ctx context.Context, commentID int64) error {
+	c.deleted = append(c.deleted, commentID)
+	return nil
+}
+
+func (c *clearClient) BotLogin() string {
+	return c.botLogin
+}
+
+// TestClearCommand_PersonalAccessToken tests that with a personal access
+// token only the bot's marked comments are deleted, not the token owner's own
+func TestClearCommand_PersonalAccessToken(t *testing.T) {
+	owner := &gh.User{Login: gh.String("octocat")}
+	client := &clearClient{
+		botLogin: "octocat",
+		comments: []*gh.PullRequestComment{
+			{ID: gh.Int64(1), Body: gh.String("<!-- gitleaks-diff-comment: .gitleaksignore:3:RIGHT -->\n🔒 **Gitleaks Exclusion Added**"), User: owner},
+			{ID: gh.Int64(2), Body: gh.String("Please add a ticket for this exclusion"), User: owner},
+		},
+	}
+
+	if err := commands.NewClearCommand(42, "octocat", 0, client).Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() unexpected error: %v", err)
+	}
+	if !slices.Equal(client.deleted, []int64{1}) {
+		t.Errorf("deleted comments %v, want only the bot's comment 1", client.deleted)
+	}
+}
+
+// TestClearCommand_AppLogin tests that unmarked comments by the app's bot
+// account are still deleted
+func TestClearCommand_AppLogin(t *testing.T) {
+	client := &clearClient{
+		botLogin: "exclusion-reviewer[bot]",
+		comments: []*gh.PullRequestComment{
+			{ID: gh.Int64(1), Body: gh.String("old comment without a marker"), User: &gh.User{Login: gh.String("exclusion-reviewer[bot]")}},
+			{ID: gh.Int64(2), Body: gh.String("LGTM"), User: &gh.User{Login: gh.String("octocat")}},
+		},
+	}
+
+	if err := commands.NewClearCommand(42, "octocat", 0, client).Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() unexpected error: %v", err)
+	}
+	if !slices.Equal(client.deleted, []int64{1}) {
+		t.Errorf("deleted comments %v, want only the app's comment 1", client.deleted)
+	}
+}
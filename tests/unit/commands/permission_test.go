@@ -0,0 +1,35 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/epy0n0ff/gitleaks-diff-comment/internal/commands"
+)
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		level    string
+		required string
+		expected bool
+	}{
+		{level: "admin", required: "admin", expected: true},
+		{level: "maintain", required: "admin", expected: false},
+		{level: "write", required: "admin", expected: false},
+		{level: "maintain", required: "write", expected: true},
+		{level: "write", required: "write", expected: true},
+		{level: "triage", required: "write", expected: false},
+		{level: "triage", required: "read", expected: true},
+		{level: "read", required: "read", expected: true},
+		{level: "none", required: "read", expected: false},
+		{level: "", required: "read", expected: false},
+		{level: "unknown", required: "read", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level+" needs "+tt.required, func(t *testing.T) {
+			if got := commands.HasPermission(tt.level, tt.required); got != tt.expected {
+				t.Errorf("HasPermission(%q, %q) = %v, want %v", tt.level, tt.required, got, tt.expected)
+			}
+		})
+	}
+}